@@ -22,7 +22,15 @@ func main() {
 
 	signal.Notify(killSignal, os.Interrupt, syscall.SIGTERM)
 
-	r := api.SetupRouter(cfg)
+	r, workers := api.SetupRouter(cfg)
+
+	// Background workers stop when the server shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Run(workerCtx)
+		close(workersDone)
+	}()
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Port),
@@ -49,7 +57,16 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	stopWorkers()
+
 	if err := server.Shutdown(ctx); err != nil {
 		cfg.Logger.Fatal("server shutdown failed", zap.Error(err))
 	}
+
+	select {
+	case <-workersDone:
+		cfg.Logger.Info("Background workers stopped")
+	case <-ctx.Done():
+		cfg.Logger.Warn("background workers did not stop in time")
+	}
 }
@@ -8,24 +8,33 @@ import (
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/domain/user"
+	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/service"
 	"github.com/CP-Payne/ecomstore/internal/utils"
 	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
 	"github.com/CP-Payne/ecomstore/internal/utils/hashing"
 	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const refreshTokenCookie = "refresh_token"
+
 type AuthHandler struct {
-	srv    *service.UserService
-	logger *zap.Logger
+	srv      *service.UserService
+	srvToken *service.TokenService
+	srvCart  *service.CartService
+	logger   *zap.Logger
 }
 
-func NewAuthHandler(srv *service.UserService) *AuthHandler {
+func NewAuthHandler(srv *service.UserService, srvToken *service.TokenService, srvCart *service.CartService) *AuthHandler {
 	logger := config.GetLogger()
 	return &AuthHandler{
-		srv:    srv,
-		logger: logger,
+		srv:      srv,
+		srvToken: srvToken,
+		srvCart:  srvCart,
+		logger:   logger,
 	}
 }
 
@@ -41,6 +50,12 @@ type LoginInput struct {
 	Password string `json:"password"`
 }
 
+type ResetPasswordInput struct {
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
 func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
@@ -79,13 +94,25 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token := config.MakeToken(user.Email, user.ID)
-	setCookie(w, token)
+	// The account is usable without verification, so a failed email only gets logged
+	if err := h.srv.SendVerificationEmail(ctx, user.ID); err != nil {
+		logger.Error("failed to send verification email", zap.Error(err), zap.String("userID", user.ID.String()))
+	}
+
+	tokens, err := h.srvToken.IssueTokens(ctx, user)
+	if err != nil {
+		logger.Error("failed to issue tokens", zap.Error(err), zap.String("userID", user.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to register user")
+		return
+	}
+	setAuthCookies(w, tokens)
+	h.mergeGuestCart(w, r, user.ID)
 
 	utils.RespondWithJson(w, http.StatusCreated, map[string]interface{}{
-		"message": "Registration successfull",
-		"email":   user.Email,
-		"userId":  user.ID.String(),
+		"message":              "Registration successfull",
+		"email":                user.Email,
+		"userId":               user.ID.String(),
+		"accessTokenExpiresAt": tokens.AccessTokenExpiresAt,
 	})
 }
 
@@ -120,22 +147,341 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token := config.MakeToken(user.Email, user.ID)
-	setCookie(w, token)
+	tokens, err := h.srvToken.IssueTokens(ctx, user)
+	if err != nil {
+		logger.Error("failed to issue tokens", zap.Error(err), zap.String("userID", user.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to authenticate user")
+		return
+	}
+	setAuthCookies(w, tokens)
+	h.mergeGuestCart(w, r, user.ID)
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message":              "Login successfull",
+		"userId":               user.ID.String(),
+		"accessTokenExpiresAt": tokens.AccessTokenExpiresAt,
+	})
+}
+
+func (h *AuthHandler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "RefreshTokens"))
+
+	refreshToken := refreshTokenFromRequest(r)
+	if refreshToken == "" {
+		logger.Info("refresh token not provided")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Refresh token not provided")
+		return
+	}
+
+	tokens, err := h.srvToken.RefreshTokens(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidToken) || errors.Is(err, apperrors.ErrTokenReuse) {
+			logger.Info("refresh rejected", zap.Error(err))
+			clearAuthCookies(w)
+			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+			return
+		}
+		logger.Error("failed to refresh tokens", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to refresh tokens")
+		return
+	}
+	setAuthCookies(w, tokens)
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message":              "Tokens refreshed",
+		"accessTokenExpiresAt": tokens.AccessTokenExpiresAt,
+	})
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "Logout"))
+
+	token, claims, _ := jwtauth.FromContext(ctx)
+	strUserID, ok := claims["id"].(string)
+	if !ok || token == nil {
+		logger.Error("user id not found in token claims")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+		return
+	}
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	err = h.srvToken.RevokeAccessToken(ctx, token.JwtID(), userID, token.Expiration())
+	if err != nil {
+		logger.Error("failed to revoke access token", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
+	if refreshToken := refreshTokenFromRequest(r); refreshToken != "" {
+		if err := h.srvToken.RevokeRefreshToken(ctx, refreshToken); err != nil {
+			logger.Error("failed to revoke refresh token", zap.Error(err), zap.String("userID", userID.String()))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to log out")
+			return
+		}
+	}
+
+	clearAuthCookies(w)
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Logout successfull",
+	})
+}
+
+func (h *AuthHandler) LogoutAllDevices(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "LogoutAllDevices"))
+
+	_, claims, _ := jwtauth.FromContext(ctx)
+	strUserID, ok := claims["id"].(string)
+	if !ok {
+		logger.Error("user id not found in token claims")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+		return
+	}
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	err = h.srvToken.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		logger.Error("failed to revoke user sessions", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to log out of all devices")
+		return
+	}
+
+	clearAuthCookies(w)
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Logged out of all devices",
+	})
+}
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ForgotPassword"))
+
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if _, err := user.ValidateEmail(input.Email); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Respond the same way whether or not the email belongs to an account. Failures such as sending the email only
+	// happen for registered emails, so they are logged rather than reported.
+	if err := h.srv.RequestPasswordReset(ctx, input.Email); err != nil {
+		logger.Error("failed to request password reset", zap.Error(err), zap.String("email", input.Email))
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ResetPassword"))
+
+	var input ResetPasswordInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if input.Password != input.ConfirmPassword {
+		utils.RespondWithError(w, http.StatusBadRequest, "Passwords do not match")
+		return
+	}
+
+	errs := input.validateResetPasswordInput()
+
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	err := h.srv.ResetPassword(ctx, input.Token, input.Password)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidToken) {
+			logger.Info("invalid password reset token", zap.Error(err))
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired reset token")
+			return
+		}
+		logger.Error("failed to reset password", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
 
 	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
-		"message": "Login successfull",
-		"userId":  user.ID.String(),
+		"message": "Password reset successfull",
 	})
 }
 
-func setCookie(w http.ResponseWriter, token string) {
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "VerifyEmail"))
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		logger.Warn("user did not provide a verification token")
+		utils.RespondWithError(w, http.StatusBadRequest, "Token not provided")
+		return
+	}
+
+	err := h.srv.VerifyEmail(ctx, token)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidToken) {
+			logger.Info("invalid email verification token", zap.Error(err))
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired verification token")
+			return
+		}
+		logger.Error("failed to verify email", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to verify email")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Email verified",
+	})
+}
+
+func (h *AuthHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ResendVerificationEmail"))
+
+	_, claims, _ := jwtauth.FromContext(ctx)
+	strUserID, ok := claims["id"].(string)
+	if !ok {
+		logger.Error("user id not found in token claims")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+		return
+	}
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	err = h.srv.SendVerificationEmail(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrConflict) {
+			utils.RespondWithError(w, http.StatusConflict, "Email already verified")
+			return
+		}
+		logger.Error("failed to send verification email", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to send verification email")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Verification email sent",
+	})
+}
+
+// mergeGuestCart moves the items of the guest cart the user filled before logging in into their own cart. The
+// user is logged in either way, so a failed merge only gets logged and leaves the guest cart in place.
+func (h *AuthHandler) mergeGuestCart(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	logger := h.logger.With(zap.String("handler", "mergeGuestCart"), zap.String("userID", userID.String()))
+
+	cookie, err := r.Cookie(guestCartCookie)
+	if err != nil {
+		return
+	}
+
+	cartID, err := h.srvCart.ParseGuestCartToken(cookie.Value)
+	if err != nil {
+		logger.Info("invalid guest cart cookie discarded", zap.Error(err))
+		clearGuestCartCookie(w)
+		return
+	}
+
+	err = h.srvCart.MergeGuestCart(r.Context(), cartID, userID)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		logger.Error("failed to merge guest cart", zap.Error(err), zap.String("cartID", cartID.String()))
+		return
+	}
+
+	clearGuestCartCookie(w)
+}
+
+// refreshTokenFromRequest reads the refresh token from its cookie, falling back to the request body for non-browser clients
+func refreshTokenFromRequest(r *http.Request) string {
+	if cookie, err := r.Cookie(refreshTokenCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return ""
+	}
+	return body.RefreshToken
+}
+
+func setAuthCookies(w http.ResponseWriter, tokens models.AuthTokens) {
 	http.SetCookie(w, &http.Cookie{
 		HttpOnly: true,
-		Expires:  time.Now().Add(7 * 24 * time.Hour),
+		Expires:  tokens.AccessTokenExpiresAt,
 		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
 		Name:     "jwt",
-		Value:    token,
+		Value:    tokens.AccessToken,
+	})
+	http.SetCookie(w, &http.Cookie{
+		HttpOnly: true,
+		Expires:  tokens.RefreshTokenExpiresAt,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/auth",
+		Name:     refreshTokenCookie,
+		Value:    tokens.RefreshToken,
+	})
+}
+
+func clearAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		HttpOnly: true,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		Name:     "jwt",
+	})
+	http.SetCookie(w, &http.Cookie{
+		HttpOnly: true,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/auth",
+		Name:     refreshTokenCookie,
 	})
 }
 
@@ -159,3 +505,18 @@ func (ri *RegistrationInput) validateRegistrationInput() errsx.Map {
 
 	return errs
 }
+
+func (ri *ResetPasswordInput) validateResetPasswordInput() errsx.Map {
+	var errs errsx.Map
+
+	if ri.Token == "" {
+		errs.Set("token", "token is required")
+	}
+
+	_, err := user.ValidatePassword(ri.Password)
+	if err != nil {
+		errs.Set("password", err)
+	}
+
+	return errs
+}
@@ -1,17 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/service"
 	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	guestCartCookie    = "guest_cart"
+	guestCartCookieTTL = 30 * 24 * time.Hour
+
+	// maxCartUpdateItems limits how many items a single batch update may change
+	maxCartUpdateItems = 100
+)
+
 type CartHandler struct {
 	srvCart    *service.CartService
 	srvProduct *service.ProductService
@@ -29,27 +44,12 @@ func NewCartHandler(srvCart *service.CartService, srvProduct *service.ProductSer
 
 func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 
-	ctx := r.Context()
 	logger := h.logger.With(zap.String("handler", "GetCart"))
 
-	_, claims, _ := jwtauth.FromContext(ctx)
-	strUserID, ok := claims["id"].(string)
-	if !ok {
-		logger.Error("user id not found in token claims")
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
-		return
-	}
-	userID, err := uuid.Parse(strUserID)
+	cart, err := h.cartFromRequest(w, r, false)
 	if err != nil {
-		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
-		return
-	}
-
-	cart, err := h.srvCart.GetCart(ctx, userID)
-	if err != nil {
-		logger.Error("failed to retrieve user cart", zap.Error(err), zap.String("userID", userID.String()))
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user cart")
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
 		return
 	}
 
@@ -62,21 +62,15 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger.With(zap.String("handler", "AddToCart"))
 
 	type CartInput struct {
-		ProductID uuid.UUID `json:"productId"`
-		Quantity  int       `json:"quantity"`
+		ProductID uuid.UUID  `json:"productId"`
+		VariantID *uuid.UUID `json:"variantId"`
+		Quantity  int        `json:"quantity"`
 	}
 
-	_, claims, _ := jwtauth.FromContext(ctx)
-	strUserID, ok := claims["id"].(string)
-	if !ok {
-		logger.Error("user id not found in token claims")
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
-		return
-	}
-	userID, err := uuid.Parse(strUserID)
+	cart, err := h.cartFromRequest(w, r, true)
 	if err != nil {
-		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
 		return
 	}
 
@@ -89,28 +83,26 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if cartInput.Quantity < 1 {
-		logger.Warn("user provided invalid product quantity", zap.String("userID", userID.String()), zap.Int("quantity", cartInput.Quantity))
+		logger.Warn("user provided invalid product quantity", zap.String("cartID", cart.ID.String()), zap.Int("quantity", cartInput.Quantity))
 		utils.RespondWithError(w, http.StatusBadRequest, "Quantity must be greater than 0")
 		return
 	}
 
-	// Check if the product exists
-	productExists, err := h.srvProduct.ProductExists(r.Context(), cartInput.ProductID)
+	// Check that the product, or the chosen variant, exists and is in stock
+	_, err = h.srvProduct.GetPurchasableItem(ctx, cartInput.ProductID, cartInput.VariantID, cartInput.Quantity)
 	if err != nil {
-		logger.Error("failed to check product existence", zap.Error(err), zap.String("productID", cartInput.ProductID.String()))
+		if respondWithPurchaseError(w, err) {
+			logger.Info("item cannot be added to cart", zap.Error(err), zap.String("productID", cartInput.ProductID.String()))
+			return
+		}
+		logger.Error("failed to check product availability", zap.Error(err), zap.String("productID", cartInput.ProductID.String()))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
 		return
 	}
 
-	if !productExists {
-		logger.Warn("product does not exist", zap.String("productID", cartInput.ProductID.String()))
-		utils.RespondWithError(w, http.StatusNotFound, "Product not found")
-		return
-	}
-
-	err = h.srvCart.AddToCart(ctx, userID, cartInput.ProductID, cartInput.Quantity)
+	err = h.srvCart.AddToCart(ctx, cart.ID, cartInput.ProductID, cartInput.VariantID, cartInput.Quantity)
 	if err != nil {
-		logger.Error("failed to add item to cart", zap.Error(err), zap.String("userID", userID.String()), zap.String("productID", cartInput.ProductID.String()))
+		logger.Error("failed to add item to cart", zap.Error(err), zap.String("cartID", cart.ID.String()), zap.String("productID", cartInput.ProductID.String()))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to add item to cart")
 		return
 	}
@@ -126,20 +118,14 @@ func (h *CartHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger.With(zap.String("handler", "RemoveFromCart"))
 
 	type CartInput struct {
-		ProductID uuid.UUID `json:"productId"`
+		ProductID uuid.UUID  `json:"productId"`
+		VariantID *uuid.UUID `json:"variantId"`
 	}
 
-	_, claims, _ := jwtauth.FromContext(ctx)
-	strUserID, ok := claims["id"].(string)
-	if !ok {
-		logger.Error("user id not found in token claims")
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
-		return
-	}
-	userID, err := uuid.Parse(strUserID)
+	cart, err := h.cartFromRequest(w, r, true)
 	if err != nil {
-		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
 		return
 	}
 
@@ -150,9 +136,9 @@ func (h *CartHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.srvCart.RemoveFromCart(ctx, userID, cartInput.ProductID)
+	err = h.srvCart.RemoveFromCart(ctx, cart.ID, cartInput.ProductID, cartInput.VariantID)
 	if err != nil {
-		logger.Error("failed to remove item from cart", zap.Error(err), zap.String("userID", userID.String()), zap.String("productID", cartInput.ProductID.String()))
+		logger.Error("failed to remove item from cart", zap.Error(err), zap.String("cartID", cart.ID.String()), zap.String("productID", cartInput.ProductID.String()))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to remove item from cart")
 		return
 	}
@@ -167,7 +153,8 @@ func (h *CartHandler) ReduceFromCart(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger.With(zap.String("handler", "ReduceFromCart"))
 
 	type CartInput struct {
-		ProductID uuid.UUID `json:"productId"`
+		ProductID uuid.UUID  `json:"productId"`
+		VariantID *uuid.UUID `json:"variantId"`
 	}
 
 	var cartInput CartInput
@@ -178,23 +165,16 @@ func (h *CartHandler) ReduceFromCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, claims, _ := jwtauth.FromContext(ctx)
-	strUserID, ok := claims["id"].(string)
-	if !ok {
-		logger.Error("user id not found in token claims")
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
-		return
-	}
-	userID, err := uuid.Parse(strUserID)
+	cart, err := h.cartFromRequest(w, r, true)
 	if err != nil {
-		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
 		return
 	}
 
-	err = h.srvCart.ReduceFromCart(r.Context(), userID, cartInput.ProductID, 1)
+	err = h.srvCart.ReduceFromCart(ctx, cart.ID, cartInput.ProductID, cartInput.VariantID, 1)
 	if err != nil {
-		logger.Error("failed to reduce cart item quantity", zap.Error(err), zap.String("userID", userID.String()), zap.String("productID", cartInput.ProductID.String()))
+		logger.Error("failed to reduce cart item quantity", zap.Error(err), zap.String("cartID", cart.ID.String()), zap.String("productID", cartInput.ProductID.String()))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to reduce cart item quantity")
 		return
 	}
@@ -203,3 +183,287 @@ func (h *CartHandler) ReduceFromCart(w http.ResponseWriter, r *http.Request) {
 		"message": "Succesfully reduced cart item quantity",
 	})
 }
+
+// SetItemQuantity sets the quantity of a product, or of the variant in the request body, in the cart and returns
+// the updated cart. A quantity of 0 removes the item.
+func (h *CartHandler) SetItemQuantity(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "SetItemQuantity"))
+
+	type QuantityInput struct {
+		VariantID *uuid.UUID `json:"variantId"`
+		Quantity  *int       `json:"quantity"`
+	}
+
+	productID, err := uuid.Parse(chi.URLParam(r, "productId"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var input QuantityInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if input.Quantity == nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Quantity is required")
+		return
+	}
+
+	h.updateItems(w, r, logger, []models.CartItemUpdate{{
+		ProductID: productID,
+		VariantID: input.VariantID,
+		Quantity:  *input.Quantity,
+	}})
+}
+
+// UpdateCart sets the quantities of several items at once and returns the updated cart. The updates are applied
+// together, so if any item is invalid or out of stock the cart is left unchanged.
+func (h *CartHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "UpdateCart"))
+
+	type UpdateInput struct {
+		Items []models.CartItemUpdate `json:"items"`
+	}
+
+	var input UpdateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(input.Items) == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "At least one item is required")
+		return
+	}
+	if len(input.Items) > maxCartUpdateItems {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("At most %d items can be updated at once", maxCartUpdateItems))
+		return
+	}
+
+	type itemKey struct {
+		productID uuid.UUID
+		variantID uuid.UUID
+	}
+	seen := make(map[itemKey]bool, len(input.Items))
+	for i, item := range input.Items {
+		key := itemKey{productID: item.ProductID}
+		if item.VariantID != nil {
+			key.variantID = *item.VariantID
+		}
+		if seen[key] {
+			utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Item %d: Item is listed more than once", i+1))
+			return
+		}
+		seen[key] = true
+	}
+
+	h.updateItems(w, r, logger, input.Items)
+}
+
+// updateItems applies the updates to the request's cart, which checks each of them against the current stock,
+// and responds with the updated cart
+func (h *CartHandler) updateItems(w http.ResponseWriter, r *http.Request, logger *zap.Logger, updates []models.CartItemUpdate) {
+	ctx := r.Context()
+
+	// Errors name the item at fault when several are updated at once
+	respondWithItemError := func(i int, status int, message string) {
+		if len(updates) > 1 {
+			message = fmt.Sprintf("Item %d: %s", i+1, message)
+		}
+		utils.RespondWithError(w, status, message)
+	}
+
+	for i, update := range updates {
+		if update.Quantity < 0 {
+			respondWithItemError(i, http.StatusBadRequest, "Quantity must not be negative")
+			return
+		}
+	}
+
+	cart, err := h.cartFromRequest(w, r, true)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	err = h.srvCart.UpdateItems(ctx, cart.ID, updates)
+	if err != nil {
+		var itemErr *service.CartItemError
+		if errors.As(err, &itemErr) {
+			if status, message, ok := purchaseError(itemErr.Err); ok {
+				logger.Info("cart item cannot be updated", zap.Error(err), zap.String("productID", updates[itemErr.Index].ProductID.String()))
+				respondWithItemError(itemErr.Index, status, message)
+				return
+			}
+		}
+		logger.Error("failed to update cart items", zap.Error(err), zap.String("cartID", cart.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update cart")
+		return
+	}
+
+	cart, err = h.reloadCart(ctx, cart)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, cart)
+}
+
+// AdjustCart accepts the changes behind the cart's warnings, removing unavailable items, lowering quantities to
+// the stock available and taking on current prices, and returns the adjusted cart
+func (h *CartHandler) AdjustCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "AdjustCart"))
+
+	cart, err := h.cartFromRequest(w, r, true)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	if err := h.srvCart.AdjustCart(ctx, cart.ID); err != nil {
+		logger.Error("failed to adjust cart", zap.Error(err), zap.String("cartID", cart.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to adjust cart")
+		return
+	}
+
+	cart, err = h.reloadCart(ctx, cart)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, cart)
+}
+
+// SetCartCurrency changes the currency the cart is priced in and returns the repriced cart
+func (h *CartHandler) SetCartCurrency(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "SetCartCurrency"))
+
+	type CurrencyInput struct {
+		Currency string `json:"currency"`
+	}
+
+	cart, err := h.cartFromRequest(w, r, true)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	var input CurrencyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if input.Currency == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Currency is required")
+		return
+	}
+
+	err = h.srvCart.SetCurrency(ctx, cart.ID, input.Currency)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUnsupportedCurrency) {
+			utils.RespondWithError(w, http.StatusBadRequest, "Currency is not supported")
+			return
+		}
+		logger.Error("failed to set cart currency", zap.Error(err), zap.String("cartID", cart.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set cart currency")
+		return
+	}
+
+	cart, err = h.reloadCart(ctx, cart)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, cart)
+}
+
+// reloadCart fetches the request's cart again after changing it. Calling cartFromRequest again would give a new
+// guest, whose cookie is only set on the response, a second new cart.
+func (h *CartHandler) reloadCart(ctx context.Context, cart models.Cart) (models.Cart, error) {
+	if cart.UserID != uuid.Nil {
+		return h.srvCart.GetCart(ctx, cart.UserID)
+	}
+	return h.srvCart.GetGuestCart(ctx, &cart.ID, false)
+}
+
+// cartFromRequest returns the logged in user's cart or, for guests, the cart referenced by the guest cart cookie.
+// Guests without a valid cookie get a new cart and a cookie pointing to it, unless create is false, in which case
+// they get an empty cart that is only saved once they change it.
+func (h *CartHandler) cartFromRequest(w http.ResponseWriter, r *http.Request, create bool) (models.Cart, error) {
+	ctx := r.Context()
+
+	_, claims, _ := jwtauth.FromContext(ctx)
+	if strUserID, ok := claims["id"].(string); ok {
+		userID, err := uuid.Parse(strUserID)
+		if err != nil {
+			return models.Cart{}, fmt.Errorf("failed to parse user id: %w", err)
+		}
+		return h.srvCart.GetCart(ctx, userID)
+	}
+
+	var cartID *uuid.UUID
+	cookie, err := r.Cookie(guestCartCookie)
+	if err == nil {
+		parsed, err := h.srvCart.ParseGuestCartToken(cookie.Value)
+		if err != nil {
+			h.logger.Info("invalid guest cart cookie discarded", zap.Error(err))
+		} else {
+			cartID = &parsed
+		}
+	}
+
+	cart, err := h.srvCart.GetGuestCart(ctx, cartID, create)
+	if err != nil {
+		return models.Cart{}, err
+	}
+
+	switch {
+	case cart.ID == uuid.Nil:
+		if cookie != nil {
+			clearGuestCartCookie(w)
+		}
+	case cartID == nil || *cartID != cart.ID:
+		setGuestCartCookie(w, h.srvCart.GuestCartToken(cart.ID))
+	}
+
+	return cart, nil
+}
+
+func setGuestCartCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		HttpOnly: true,
+		MaxAge:   int(guestCartCookieTTL.Seconds()),
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		Name:     guestCartCookie,
+		Value:    token,
+	})
+}
+
+func clearGuestCartCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		HttpOnly: true,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		Name:     guestCartCookie,
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/service"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"go.uber.org/zap"
+)
+
+type CartRecoveryHandler struct {
+	srv    *service.CartRecoveryService
+	logger *zap.Logger
+}
+
+func NewCartRecoveryHandler(srv *service.CartRecoveryService) *CartRecoveryHandler {
+	return &CartRecoveryHandler{
+		srv:    srv,
+		logger: config.GetLogger(),
+	}
+}
+
+// RecoverCart restores the abandoned cart of the link in a cart recovery email
+func (h *CartRecoveryHandler) RecoverCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "RecoverCart"))
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Token not provided")
+		return
+	}
+
+	cart, err := h.srv.RestoreCart(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidToken):
+			logger.Info("invalid cart recovery token", zap.Error(err))
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid or expired recovery link")
+		case errors.Is(err, apperrors.ErrNotFound):
+			logger.Info("recovered cart no longer exists", zap.Error(err))
+			utils.RespondWithError(w, http.StatusNotFound, "Cart is no longer available")
+		default:
+			logger.Error("failed to restore cart", zap.Error(err))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to restore cart")
+		}
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Your cart has been restored",
+		"cart":    cart,
+	})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/catalogio"
+	"go.uber.org/zap"
+)
+
+const maxCatalogImportSize = 32 << 20
+
+// ImportCatalog upserts products from a CSV or JSON lines body. The format is taken from the format query
+// parameter or the Content-Type header, dryRun=true reports the changes without applying them.
+func (h *ProductHandler) ImportCatalog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ImportCatalog"))
+
+	query := r.URL.Query()
+
+	formatName := query.Get("format")
+	if formatName == "" {
+		formatName, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	}
+	format, err := catalogio.ParseFormat(formatName)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dryRun := false
+	if v := query.Get("dryRun"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "dryRun must be true or false")
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCatalogImportSize)
+
+	reader, err := catalogio.NewReader(format, r.Body)
+	if err != nil {
+		if respondWithBodyTooLarge(w, err) {
+			return
+		}
+		logger.Warn("failed to read import", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.srv.ImportCatalog(ctx, reader, dryRun)
+	if err != nil {
+		if respondWithBodyTooLarge(w, err) {
+			return
+		}
+		logger.Error("failed to import catalog", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to import catalog")
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		utils.RespondWithJson(w, http.StatusBadRequest, report)
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, report)
+}
+
+// ExportCatalog streams all products in the import format, CSV unless format=jsonl is given
+func (h *ProductHandler) ExportCatalog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ExportCatalog"))
+
+	format := catalogio.FormatCSV
+	if v := r.URL.Query().Get("format"); v != "" {
+		var err error
+		format, err = catalogio.ParseFormat(v)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog.%s"`, format))
+
+	writer, err := catalogio.NewWriter(format, w)
+	if err != nil {
+		logger.Error("failed to start export", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to export catalog")
+		return
+	}
+
+	// Once rows have been streamed the status can no longer change, so later failures are only logged
+	if _, err := h.srv.ExportCatalog(ctx, writer); err != nil {
+		logger.Error("failed to export catalog", zap.Error(err))
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/service"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"go.uber.org/zap"
+)
+
+type CurrencyHandler struct {
+	srv    *service.CurrencyService
+	logger *zap.Logger
+}
+
+func NewCurrencyHandler(srv *service.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{
+		srv:    srv,
+		logger: config.GetLogger(),
+	}
+}
+
+// GetCurrencies lists the currencies carts and orders can be priced in. Catalogue prices are in the base currency.
+func (h *CurrencyHandler) GetCurrencies(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJson(w, http.StatusOK, struct {
+		Base       money.Currency   `json:"base"`
+		Currencies []money.Currency `json:"currencies"`
+	}{
+		Base:       h.srv.Base(),
+		Currencies: h.srv.Currencies(),
+	})
+}
@@ -58,30 +58,62 @@ func (h *PaymentHandler) CreateOrderCart(w http.ResponseWriter, r *http.Request)
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
 		return
 	}
+
+	// Items that changed since they were added must be reviewed, or accepted with adjust=true, before checkout
+	if cart.HasWarnings() {
+		if r.URL.Query().Get("adjust") != "true" {
+			logger.Info("checkout of cart with warnings refused", zap.String("cartID", cart.ID.String()))
+			utils.RespondWithJson(w, http.StatusConflict, map[string]interface{}{
+				"error": "Some items in the cart have changed, review the cart before checking out",
+				"cart":  cart,
+			})
+			return
+		}
+
+		if err := h.srvCart.AdjustCart(ctx, cart.ID); err != nil {
+			logger.Error("failed to adjust cart", zap.Error(err), zap.String("cartID", cart.ID.String()))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+			return
+		}
+		cart, err = h.srvCart.GetCart(ctx, userID)
+		if err != nil {
+			logger.Info("failed to retrieve user cart", zap.Error(err), zap.String("UserID", userID.String()))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+			return
+		}
+	}
+
 	if len(cart.Items) <= 0 {
 		logger.Info("user attempted checkout an empty cart", zap.String("userID", userID.String()))
 		utils.RespondWithError(w, http.StatusBadRequest, "cart is empty")
 		return
 	}
 
-	// Check product quantity and cart quantity
+	// Check product quantity and cart quantity, at the variant level for items with a variant
 	for _, ci := range cart.Items {
-		product, err := h.srvProduct.GetProduct(ctx, ci.ProductID)
+		_, err := h.srvProduct.GetPurchasableItem(ctx, ci.ProductID, ci.VariantID, ci.Quantity)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
-			logger.Info("failed to retrieve product during checkout", zap.Error(err), zap.String("productID", ci.ProductID.String()))
-			return
-		}
-
-		if ci.Quantity > product.Stock {
-			logger.Warn("insufficient stock to create order", zap.String("ProductID", ci.ProductID.String()), zap.String("CartID", cart.ID.String()), zap.String("UserID", userID.String()))
-			utils.RespondWithError(w, http.StatusBadRequest, "Not enough stock")
+			switch {
+			case errors.Is(err, apperrors.ErrOutOfStock):
+				logger.Warn("insufficient stock to create order", zap.String("ProductID", ci.ProductID.String()), zap.String("CartID", cart.ID.String()), zap.String("UserID", userID.String()))
+				utils.RespondWithError(w, http.StatusBadRequest, "Not enough stock")
+			case errors.Is(err, apperrors.ErrNotFound), errors.Is(err, apperrors.ErrInvalidRef), errors.Is(err, apperrors.ErrVariantNeeded):
+				logger.Info("cart contains unavailable product", zap.Error(err), zap.String("productID", ci.ProductID.String()), zap.String("userID", userID.String()))
+				utils.RespondWithError(w, http.StatusBadRequest, "Cart contains a product that is no longer available")
+			default:
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+				logger.Info("failed to retrieve product during checkout", zap.Error(err), zap.String("productID", ci.ProductID.String()))
+			}
 			return
 		}
 	}
 
 	order, err := h.srvOrder.CreateOrder(ctx, cart, false)
 	if err != nil {
+		if respondWithPurchaseError(w, err) {
+			logger.Info("cart items could not be reserved", zap.Error(err), zap.String("userID", userID.String()))
+			return
+		}
 		logger.Error("failed to create order for user", zap.Error(err), zap.String("userID", userID.String()))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create order")
 		return
@@ -119,8 +151,10 @@ func (h *PaymentHandler) CreateOrderProduct(w http.ResponseWriter, r *http.Reque
 	// cart, err := h.srvCart.GetCartByID(r.Context(), userID, cartID)
 
 	type inputParams struct {
-		ProductID string `json:"productId"`
-		Quantity  int    `json:"quantity"`
+		ProductID string     `json:"productId"`
+		VariantID *uuid.UUID `json:"variantId"`
+		Quantity  int        `json:"quantity"`
+		Currency  string     `json:"currency"`
 	}
 
 	params := &inputParams{}
@@ -144,11 +178,10 @@ func (h *PaymentHandler) CreateOrderProduct(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	product, err := h.srvProduct.GetProduct(ctx, id)
+	item, err := h.srvProduct.GetPurchasableItem(ctx, id, params.VariantID, params.Quantity)
 	if err != nil {
-		if errors.Is(err, apperrors.ErrNotFound) {
-			logger.Warn("user attempted to purchase a product that does not exist")
-			utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+		if respondWithPurchaseError(w, err) {
+			logger.Warn("user attempted to purchase an unavailable item", zap.Error(err), zap.String("productID", params.ProductID))
 			return
 		}
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
@@ -156,17 +189,24 @@ func (h *PaymentHandler) CreateOrderProduct(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if params.Quantity > product.Stock {
-		logger.Warn("insufficient stock to create order")
-		utils.RespondWithError(w, http.StatusBadRequest, "Not enough stock")
+	// Create temporary cart
+	tempCart, err := h.srvCart.CreateTemporaryProductCart(ctx, userID, item, params.Currency)
+	if err != nil {
+		if respondWithPurchaseError(w, err) {
+			logger.Info("user chose an unsupported currency", zap.Error(err), zap.String("currency", params.Currency))
+			return
+		}
+		logger.Error("failed to create temporary cart", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create order")
 		return
 	}
 
-	// Create temporary cart
-	tempCart := h.srvCart.CreateTemporaryProductCart(ctx, userID, product, params.Quantity)
-
 	order, err := h.srvOrder.CreateOrder(ctx, tempCart, true)
 	if err != nil {
+		if respondWithPurchaseError(w, err) {
+			logger.Info("item could not be reserved", zap.Error(err), zap.String("productID", params.ProductID))
+			return
+		}
 		logger.Warn("failed to create order", zap.Error(err), zap.String("userID", userID.String()), zap.String("cartID", tempCart.ID.String()))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create order")
 		return
@@ -197,6 +237,14 @@ func (h *PaymentHandler) CaptureOrder(w http.ResponseWriter, r *http.Request) {
 
 	err := h.srvPayment.CaptureOrder(ctx, orderID)
 	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			utils.RespondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		case errors.Is(err, apperrors.ErrReservationExpired):
+			utils.RespondWithError(w, http.StatusConflict, "Checkout expired, please place the order again")
+			return
+		}
 		logger.Warn("failed to capture order", zap.Error(err), zap.String("orderID", orderID))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to complete payment")
 		return
@@ -206,3 +254,35 @@ func (h *PaymentHandler) CaptureOrder(w http.ResponseWriter, r *http.Request) {
 		"message": "Purchase succesfull",
 	})
 }
+
+// CancelOrder is where the payment processor sends buyers who abandon payment, the order's stock is released
+// right away instead of when the reservation expires
+func (h *PaymentHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "CancelOrder"))
+
+	orderID := r.URL.Query().Get("token")
+	if orderID == "" {
+		logger.Warn("user did not provide a token to cancel purchase")
+		utils.RespondWithError(w, http.StatusBadRequest, "Token not provided")
+		return
+	}
+
+	err := h.srvOrder.CancelOrder(ctx, orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			utils.RespondWithError(w, http.StatusNotFound, "Order not found")
+		case errors.Is(err, apperrors.ErrConflict):
+			utils.RespondWithError(w, http.StatusConflict, "Order can no longer be cancelled")
+		default:
+			logger.Error("failed to cancel order", zap.Error(err), zap.String("orderID", orderID))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to cancel order")
+		}
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Order cancelled",
+	})
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/domain/category"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CategoryInput holds a category write. The slug is derived from the name when omitted.
+type CategoryInput struct {
+	ParentID    *uuid.UUID `json:"parentId"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	Description string     `json:"description"`
+	SortOrder   int        `json:"sortOrder"`
+}
+
+func (h *ProductHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "CreateCategory"))
+
+	var input CategoryInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	errs := input.validateCategoryInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	created, err := h.srv.CreateCategory(ctx, input.toParams())
+	if err != nil {
+		h.respondWithCategoryWriteError(w, logger, err)
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusCreated, created)
+}
+
+// UpdateCategory replaces the category with the request body, omitting parentId makes it a root category
+func (h *ProductHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "UpdateCategory"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid category id", zap.Error(err), zap.String("categoryID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	var input CategoryInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	errs := input.validateCategoryInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	updated, err := h.srv.UpdateCategory(ctx, id, input.toParams())
+	if err != nil {
+		h.respondWithCategoryWriteError(w, logger, err)
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, updated)
+}
+
+func (h *ProductHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "DeleteCategory"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid category id", zap.Error(err), zap.String("categoryID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	err = h.srv.DeleteCategory(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			utils.RespondWithError(w, http.StatusNotFound, "Category not found")
+		case errors.Is(err, apperrors.ErrConflict):
+			utils.RespondWithError(w, http.StatusConflict, "Category still has subcategories or products")
+		default:
+			logger.Error("failed to delete category", zap.Error(err), zap.String("categoryID", strID))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete category")
+		}
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Category deleted",
+	})
+}
+
+func (h *ProductHandler) respondWithCategoryWriteError(w http.ResponseWriter, logger *zap.Logger, err error) {
+	var errs errsx.Map
+
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, "Category not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		errs.Set("slug", "slug already exists")
+		utils.RespondWithJson(w, http.StatusConflict, errs)
+	case errors.Is(err, apperrors.ErrInvalidRef):
+		errs.Set("parentId", "parent category does not exist")
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+	case errors.Is(err, apperrors.ErrCategoryCycle):
+		errs.Set("parentId", apperrors.ErrCategoryCycle)
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+	default:
+		logger.Error("failed to save category", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to save category")
+	}
+}
+
+func (ci *CategoryInput) validateCategoryInput() errsx.Map {
+	var errs errsx.Map
+
+	name, err := category.ValidateName(ci.Name)
+	if err != nil {
+		errs.Set("name", err)
+	}
+	ci.Name = string(name)
+
+	if ci.Slug == "" {
+		ci.Slug = string(category.Slugify(ci.Name))
+	}
+	if _, err := category.ValidateSlug(ci.Slug); err != nil {
+		errs.Set("slug", err)
+	}
+
+	if _, err := category.ValidateSortOrder(ci.SortOrder); err != nil {
+		errs.Set("sortOrder", err)
+	}
+
+	if ci.ParentID != nil && *ci.ParentID == uuid.Nil {
+		ci.ParentID = nil
+	}
+
+	return errs
+}
+
+func (ci *CategoryInput) toParams() models.CategoryParams {
+	return models.CategoryParams{
+		ParentID:    ci.ParentID,
+		Name:        ci.Name,
+		Slug:        ci.Slug,
+		Description: ci.Description,
+		SortOrder:   ci.SortOrder,
+	}
+}
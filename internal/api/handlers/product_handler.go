@@ -1,13 +1,23 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/domain/product"
+	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/service"
 	"github.com/CP-Payne/ecomstore/internal/utils"
 	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -26,6 +36,20 @@ func NewProductHandler(srv *service.ProductService) *ProductHandler {
 	}
 }
 
+type ProductInput struct {
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	Price            float64         `json:"price"`
+	Brand            string          `json:"brand"`
+	Sku              string          `json:"sku"`
+	Stock            int             `json:"stock"`
+	CategoryID       uuid.UUID       `json:"categoryId"`
+	ImageURL         string          `json:"imageUrl"`
+	ThumbnailURL     string          `json:"thumbnailUrl"`
+	Specifications   json.RawMessage `json:"specifications"`
+	ReorderThreshold int             `json:"reorderThreshold"`
+}
+
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
@@ -59,8 +83,24 @@ func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 	logger := h.logger.With(zap.String("handler", "GetAllProducts"))
 
-	products, err := h.srv.GetAllProducts(ctx)
+	page, err := pageParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter, sort, errs := productFilterFromQuery(r.URL.Query())
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	products, err := h.srv.ListProducts(ctx, filter, sort, page)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		logger.Error("failed to retrieve product list", zap.Error(err))
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products")
 		return
@@ -68,6 +108,66 @@ func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request)
 	utils.RespondWithJson(w, http.StatusOK, products)
 }
 
+// GetProductFacets accepts the same filters as GetAllProducts
+func (h *ProductHandler) GetProductFacets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "GetProductFacets"))
+
+	filter, _, errs := productFilterFromQuery(r.URL.Query())
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	facets, err := h.srv.GetProductFacets(ctx, filter)
+	if err != nil {
+		logger.Error("failed to retrieve product facets", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve product facets")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, facets)
+}
+
+// maxSearchLength caps the raw text handed to the similarity search
+const maxSearchLength = 200
+
+func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "SearchProducts"))
+
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len(text) > maxSearchLength {
+		utils.RespondWithError(w, http.StatusBadRequest, "Search query is too long")
+		return
+	}
+
+	query, err := product.ValidateSearchQuery(text)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := pageParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := h.srv.SearchProducts(ctx, query, text, page)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.Error("failed to search products", zap.Error(err), zap.String("query", text))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to search products")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, results)
+}
+
 func (h *ProductHandler) GetProductCategories(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := h.logger.With(zap.String("handler", "GetProductCategories"))
@@ -81,31 +181,367 @@ func (h *ProductHandler) GetProductCategories(w http.ResponseWriter, r *http.Req
 	utils.RespondWithJson(w, http.StatusOK, categories)
 }
 
+// GetProductsByCategory lists a category's products along with its breadcrumbs. The category can be given by ID
+// or slug, and includeDescendants=true adds the products of all its subcategories.
 func (h *ProductHandler) GetProductsByCategory(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	logger := h.logger.With(zap.String("handler", "GetProductsByCategory"))
 
+	page, err := pageParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeDescendants := r.URL.Query().Get("includeDescendants") == "true"
+
+	ref := chi.URLParam(r, "id")
+	var category models.Category
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		category, err = h.srv.GetCategory(ctx, id)
+	} else {
+		category, err = h.srv.GetCategoryBySlug(ctx, ref)
+	}
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Category not found")
+			return
+		}
+		logger.Error("failed to retrieve category", zap.Error(err), zap.String("category", ref))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products for category")
+		return
+	}
+
+	products, err := h.srv.GetProductsByCategory(ctx, category.ID, includeDescendants, page)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.Error("failed to retrieve products for category", zap.Error(err), zap.String("categoryID", category.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products for category")
+		return
+	}
+
+	breadcrumbs, err := h.srv.GetCategoryBreadcrumbs(ctx, category.ID)
+	if err != nil {
+		logger.Error("failed to retrieve category breadcrumbs", zap.Error(err), zap.String("categoryID", category.ID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products for category")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, models.CategoryProductsPage{
+		Page:        products,
+		Category:    category,
+		Breadcrumbs: breadcrumbs,
+	})
+}
+
+func (h *ProductHandler) AdminGetProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "AdminGetProducts"))
+
+	products, err := h.srv.GetAllProductsWithMetadata(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve product list", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products")
+		return
+	}
+	utils.RespondWithJson(w, http.StatusOK, products)
+}
+
+func (h *ProductHandler) AdminGetProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "AdminGetProduct"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	product, err := h.srv.GetProductWithMetadata(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		logger.Error("failed to retrieve product", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve product")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, product)
+}
+
+func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "CreateProduct"))
+
+	var input ProductInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	errs := input.validateProductInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	product, err := h.srv.CreateProduct(ctx, input.toParams())
+	if err != nil {
+		h.respondWithProductWriteError(w, logger, err)
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusCreated, product)
+}
+
+// UpdateProduct replaces the product with the request body, omitted optional fields are cleared
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "UpdateProduct"))
+
 	strID := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strID)
 	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var input ProductInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	errs := input.validateProductInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	product, err := h.srv.UpdateProduct(ctx, id, input.toParams())
+	if err != nil {
+		h.respondWithProductWriteError(w, logger, err)
+		return
+	}
 
-		logger.Warn("invalid category id", zap.Error(err), zap.String("productID", strID))
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category ID")
+	utils.RespondWithJson(w, http.StatusOK, product)
+}
+
+func (h *ProductHandler) ArchiveProduct(w http.ResponseWriter, r *http.Request) {
+	h.setProductActive(w, r, false)
+}
+
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	h.setProductActive(w, r, true)
+}
+
+func (h *ProductHandler) setProductActive(w http.ResponseWriter, r *http.Request, active bool) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "SetProductActive"), zap.Bool("active", active))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
 		return
 	}
 
-	products, err := h.srv.GetProductsByCategory(ctx, id)
+	product, err := h.srv.SetProductActive(ctx, id, active)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrNotFound) {
-			logger.Info("no products for the provided category found", zap.Error(err))
-			utils.RespondWithError(w, http.StatusNotFound, "No products for provided category found")
+			utils.RespondWithError(w, http.StatusNotFound, "Product not found")
 			return
 		}
-		logger.Error("failed to retrieve products for category", zap.Error(err), zap.String("categoryID", strID))
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve products for category")
+		logger.Error("failed to update product status", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update product status")
 		return
 	}
 
-	utils.RespondWithJson(w, http.StatusOK, products)
+	message := "Product archived"
+	if active {
+		message = "Product restored"
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"product": product,
+	})
+}
+
+func (h *ProductHandler) respondWithProductWriteError(w http.ResponseWriter, logger *zap.Logger, err error) {
+	var errs errsx.Map
+
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		errs.Set("sku", "sku already exists")
+		utils.RespondWithJson(w, http.StatusConflict, errs)
+	case errors.Is(err, apperrors.ErrInvalidRef):
+		errs.Set("categoryId", "category does not exist")
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+	default:
+		logger.Error("failed to save product", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to save product")
+	}
+}
+
+func (pi *ProductInput) validateProductInput() errsx.Map {
+	var errs errsx.Map
+
+	name, err := product.ValidateName(pi.Name)
+	if err != nil {
+		errs.Set("name", err)
+	}
+	pi.Name = string(name)
+
+	if _, err := product.ValidateSku(pi.Sku); err != nil {
+		errs.Set("sku", err)
+	}
+
+	if _, err := product.ValidatePrice(pi.Price); err != nil {
+		errs.Set("price", err)
+	}
+
+	if _, err := product.ValidateStock(pi.Stock); err != nil {
+		errs.Set("stock", err)
+	}
+
+	if _, err := product.ValidateReorderThreshold(pi.ReorderThreshold); err != nil {
+		errs.Set("reorderThreshold", err)
+	}
+
+	if pi.CategoryID == uuid.Nil {
+		errs.Set("categoryId", "category is required")
+	}
+
+	if len(pi.Brand) > 100 {
+		errs.Set("brand", "brand must be at most 100 characters")
+	}
+
+	if len(pi.ImageURL) > 255 {
+		errs.Set("imageUrl", "image url must be at most 255 characters")
+	}
+
+	if len(pi.ThumbnailURL) > 255 {
+		errs.Set("thumbnailUrl", "thumbnail url must be at most 255 characters")
+	}
+
+	if !isJSONKind(pi.Specifications, '{') {
+		errs.Set("specifications", "specifications must be an object")
+	}
+
+	return errs
+}
+
+func (pi *ProductInput) toParams() models.ProductParams {
+	return models.ProductParams{
+		Name:             pi.Name,
+		Description:      pi.Description,
+		Price:            money.FromFloat(pi.Price, config.BaseCurrency()),
+		Brand:            pi.Brand,
+		Sku:              pi.Sku,
+		Stock:            pi.Stock,
+		CategoryID:       pi.CategoryID,
+		ImageURL:         pi.ImageURL,
+		ThumbnailURL:     pi.ThumbnailURL,
+		Specifications:   pi.Specifications,
+		ReorderThreshold: pi.ReorderThreshold,
+	}
+}
+
+// productFilterFromQuery reads the listing filters: minPrice, maxPrice, brand (repeatable), inStock, category,
+// sort and spec.<key>=<value> (repeatable, values of the same key are alternatives)
+func productFilterFromQuery(query url.Values) (models.ProductFilter, product.Sort, errsx.Map) {
+	var filter models.ProductFilter
+	var errs errsx.Map
+
+	if v := query.Get("minPrice"); v != "" {
+		price, err := money.Parse(v, config.BaseCurrency())
+		if err != nil || price.Amount < 0 {
+			errs.Set("minPrice", "minPrice must be a non-negative number with at most two decimal places")
+		} else {
+			filter.MinPrice = &price
+		}
+	}
+
+	if v := query.Get("maxPrice"); v != "" {
+		price, err := money.Parse(v, config.BaseCurrency())
+		if err != nil || price.Amount < 0 {
+			errs.Set("maxPrice", "maxPrice must be a non-negative number with at most two decimal places")
+		} else {
+			filter.MaxPrice = &price
+		}
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && filter.MinPrice.Amount > filter.MaxPrice.Amount {
+		errs.Set("maxPrice", "maxPrice must not be less than minPrice")
+	}
+
+	for _, brand := range query["brand"] {
+		if brand = strings.TrimSpace(brand); brand != "" {
+			filter.Brands = append(filter.Brands, brand)
+		}
+	}
+
+	if v := query.Get("inStock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			errs.Set("inStock", "inStock must be true or false")
+		}
+		filter.InStock = inStock
+	}
+
+	if v := query.Get("category"); v != "" {
+		categoryID, err := uuid.Parse(v)
+		if err != nil {
+			errs.Set("category", "category must be a valid ID")
+		} else {
+			filter.CategoryID = &categoryID
+		}
+	}
+
+	for param, values := range query {
+		key, ok := strings.CutPrefix(param, "spec.")
+		if !ok {
+			continue
+		}
+		if _, err := product.ValidateSpecKey(key); err != nil {
+			errs.Set(param, err)
+			continue
+		}
+		if filter.Specs == nil {
+			filter.Specs = make(map[string][]string)
+		}
+		filter.Specs[key] = append(filter.Specs[key], values...)
+	}
+
+	sort, err := product.ValidateSort(query.Get("sort"))
+	if err != nil {
+		errs.Set("sort", err)
+	}
+
+	return filter, sort, errs
+}
+
+// isJSONKind reports whether raw is absent, null or a JSON value starting with the given delimiter
+func isJSONKind(raw json.RawMessage, delim byte) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return true
+	}
+	return raw[0] == delim
 }
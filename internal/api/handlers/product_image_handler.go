@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/service"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxImageUploadSize = 10 << 20
+	maxImagesPerUpload = 10
+	// Image keys never change content, so clients may cache them for a year
+	imageCacheControl = "public, max-age=31536000, immutable"
+)
+
+type ProductImageHandler struct {
+	srv    *service.ProductImageService
+	logger *zap.Logger
+}
+
+func NewProductImageHandler(srv *service.ProductImageService) *ProductImageHandler {
+	logger := config.GetLogger()
+	return &ProductImageHandler{
+		srv:    srv,
+		logger: logger,
+	}
+}
+
+func (h *ProductImageHandler) GetProductImages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "GetProductImages"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	images, err := h.srv.GetProductImages(ctx, id)
+	if err != nil {
+		logger.Error("failed to retrieve product images", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve product images")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, images)
+}
+
+// UploadProductImages adds the multipart "image" files to the product in the order they were sent. An optional
+// "altText" field is applied to all of them.
+func (h *ProductImageHandler) UploadProductImages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "UploadProductImages"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageUploadSize*maxImagesPerUpload)
+	if err := r.ParseMultipartForm(maxImageUploadSize); err != nil {
+		logger.Warn("failed to parse multipart form", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid upload, send images as multipart form data")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["image"]
+	if len(files) == 0 || len(files) > maxImagesPerUpload {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Upload between 1 and %d images in the \"image\" field", maxImagesPerUpload))
+		return
+	}
+	altText := r.FormValue("altText")
+	if len(altText) > 255 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Alt text must be at most 255 characters")
+		return
+	}
+
+	images := make([]models.ProductImage, 0, len(files))
+	for _, header := range files {
+		if header.Size > maxImageUploadSize {
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Images must be at most 10MB")
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			logger.Error("failed to open uploaded file", zap.Error(err))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to upload image")
+			return
+		}
+
+		image, err := h.srv.UploadProductImage(ctx, id, file, altText)
+		file.Close()
+		if err != nil {
+			switch {
+			case errors.Is(err, apperrors.ErrNotFound):
+				utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+			case errors.Is(err, apperrors.ErrInvalidImage):
+				utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("%s: %s", header.Filename, apperrors.ErrInvalidImage))
+			default:
+				logger.Error("failed to upload product image", zap.Error(err), zap.String("productID", strID))
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to upload image")
+			}
+			return
+		}
+		images = append(images, image)
+	}
+
+	utils.RespondWithJson(w, http.StatusCreated, images)
+}
+
+// ReorderProductImages takes every image ID of the product in the new display order
+func (h *ProductImageHandler) ReorderProductImages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ReorderProductImages"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var input struct {
+		ImageIDs []uuid.UUID `json:"imageIds"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	images, err := h.srv.ReorderProductImages(ctx, id, input.ImageIDs)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidRef) {
+			utils.RespondWithError(w, http.StatusBadRequest, "imageIds must list every image of the product exactly once")
+			return
+		}
+		logger.Error("failed to reorder product images", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to reorder images")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, images)
+}
+
+func (h *ProductImageHandler) DeleteProductImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "DeleteProductImage"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	strImageID := chi.URLParam(r, "imageId")
+	imageID, err := uuid.Parse(strImageID)
+	if err != nil {
+		logger.Warn("invalid image id", zap.Error(err), zap.String("imageID", strImageID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid image ID")
+		return
+	}
+
+	err = h.srv.DeleteProductImage(ctx, id, imageID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Image not found")
+			return
+		}
+		logger.Error("failed to delete product image", zap.Error(err), zap.String("imageID", strImageID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete image")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "Image deleted",
+	})
+}
+
+// ServeImage streams a stored image with long lived cache headers. Conditional and range requests are answered
+// when the storage supports seeking.
+func (h *ProductImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "ServeImage"))
+
+	key := chi.URLParam(r, "*")
+
+	content, info, err := h.srv.OpenImage(ctx, key)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		logger.Error("failed to open image", zap.Error(err), zap.String("key", key))
+		http.Error(w, "Failed to retrieve image", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Cache-Control", imageCacheControl)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime.UnixNano(), info.Size))
+
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", info.ModTime, seeker)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	if _, err := io.Copy(w, content); err != nil {
+		logger.Warn("failed to write image", zap.Error(err), zap.String("key", key))
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/domain/product"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ProductVariantInput struct {
+	Sku        string          `json:"sku"`
+	Price      float64         `json:"price"`
+	Stock      int             `json:"stock"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// AdminGetProductVariants lists every variant of a product, including archived ones
+func (h *ProductHandler) AdminGetProductVariants(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "AdminGetProductVariants"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	variants, err := h.srv.GetProductVariants(ctx, id, true)
+	if err != nil {
+		logger.Error("failed to retrieve product variants", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve product variants")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, variants)
+}
+
+func (h *ProductHandler) CreateProductVariant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "CreateProductVariant"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var input ProductVariantInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	params, errs := input.validateProductVariantInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	variant, err := h.srv.CreateProductVariant(ctx, id, params)
+	if err != nil {
+		h.respondWithVariantWriteError(w, logger, err)
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusCreated, variant)
+}
+
+// UpdateProductVariant replaces the variant with the request body
+func (h *ProductHandler) UpdateProductVariant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "UpdateProductVariant"))
+
+	productID, variantID, ok := variantURLParams(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var input ProductVariantInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	params, errs := input.validateProductVariantInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	variant, err := h.srv.UpdateProductVariant(ctx, productID, variantID, params)
+	if err != nil {
+		h.respondWithVariantWriteError(w, logger, err)
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, variant)
+}
+
+func (h *ProductHandler) ArchiveProductVariant(w http.ResponseWriter, r *http.Request) {
+	h.setProductVariantActive(w, r, false)
+}
+
+func (h *ProductHandler) RestoreProductVariant(w http.ResponseWriter, r *http.Request) {
+	h.setProductVariantActive(w, r, true)
+}
+
+func (h *ProductHandler) setProductVariantActive(w http.ResponseWriter, r *http.Request, active bool) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "SetProductVariantActive"), zap.Bool("active", active))
+
+	productID, variantID, ok := variantURLParams(w, r, logger)
+	if !ok {
+		return
+	}
+
+	variant, err := h.srv.SetProductVariantActive(ctx, productID, variantID, active)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Variant not found")
+			return
+		}
+		logger.Error("failed to update variant status", zap.Error(err), zap.String("variantID", variantID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update variant status")
+		return
+	}
+
+	message := "Variant archived"
+	if active {
+		message = "Variant restored"
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"variant": variant,
+	})
+}
+
+func (h *ProductHandler) respondWithVariantWriteError(w http.ResponseWriter, logger *zap.Logger, err error) {
+	var errs errsx.Map
+
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, "Variant not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		errs.Set("sku", "sku already exists")
+		utils.RespondWithJson(w, http.StatusConflict, errs)
+	default:
+		logger.Error("failed to save product variant", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to save product variant")
+	}
+}
+
+// variantURLParams parses the product and variant IDs of a variant route, responding with an error when either is invalid
+func variantURLParams(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (uuid.UUID, uuid.UUID, bool) {
+	strID := chi.URLParam(r, "id")
+	productID, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	strVariantID := chi.URLParam(r, "variantId")
+	variantID, err := uuid.Parse(strVariantID)
+	if err != nil {
+		logger.Warn("invalid variant id", zap.Error(err), zap.String("variantID", strVariantID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return productID, variantID, true
+}
+
+func (vi *ProductVariantInput) validateProductVariantInput() (models.ProductVariantParams, errsx.Map) {
+	var errs errsx.Map
+
+	if _, err := product.ValidateSku(vi.Sku); err != nil {
+		errs.Set("sku", err)
+	}
+
+	if _, err := product.ValidatePrice(vi.Price); err != nil {
+		errs.Set("price", err)
+	}
+
+	if _, err := product.ValidateStock(vi.Stock); err != nil {
+		errs.Set("stock", err)
+	}
+
+	attributes, err := product.ValidateAttributes(vi.Attributes)
+	if err != nil {
+		errs.Set("attributes", err)
+	}
+
+	if errs != nil {
+		return models.ProductVariantParams{}, errs
+	}
+
+	// Store the attributes re-encoded so they are always an object
+	rawAttributes, _ := json.Marshal(attributes)
+
+	return models.ProductVariantParams{
+		Sku:        vi.Sku,
+		Price:      money.FromFloat(vi.Price, config.BaseCurrency()),
+		Stock:      vi.Stock,
+		Attributes: rawAttributes,
+	}, nil
+}
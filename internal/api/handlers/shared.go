@@ -1 +1,63 @@
 package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
+)
+
+// pageParams reads the limit, cursor and includeTotal query parameters of a paginated listing
+func pageParams(r *http.Request) (pagination.Params, error) {
+	query := r.URL.Query()
+
+	params, err := pagination.ParseParams(query.Get("limit"), query.Get("cursor"))
+	if err != nil {
+		return pagination.Params{}, err
+	}
+	params.IncludeTotal = query.Get("includeTotal") == "true"
+
+	return params, nil
+}
+
+// respondWithPurchaseError responds to an item that cannot be added to a cart or bought, returning false for
+// errors that are not about the item itself
+func respondWithPurchaseError(w http.ResponseWriter, err error) bool {
+	status, message, ok := purchaseError(err)
+	if !ok {
+		return false
+	}
+	utils.RespondWithError(w, status, message)
+	return true
+}
+
+// purchaseError returns the status and message for an item that cannot be added to a cart or bought
+func purchaseError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		return http.StatusNotFound, "Product not found", true
+	case errors.Is(err, apperrors.ErrInvalidRef):
+		return http.StatusBadRequest, "Variant not found for product", true
+	case errors.Is(err, apperrors.ErrVariantNeeded):
+		return http.StatusBadRequest, "A variant must be selected for this product", true
+	case errors.Is(err, apperrors.ErrOutOfStock):
+		return http.StatusBadRequest, "Not enough stock", true
+	case errors.Is(err, apperrors.ErrUnsupportedCurrency):
+		return http.StatusBadRequest, "Currency is not supported", true
+	default:
+		return 0, "", false
+	}
+}
+
+// respondWithBodyTooLarge responds to a body that exceeded its http.MaxBytesReader limit, returning false for other errors
+func respondWithBodyTooLarge(w http.ResponseWriter, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	utils.RespondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body must be at most %d bytes", maxBytesErr.Limit))
+	return true
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/domain/inventory"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type StockAdjustmentInput struct {
+	VariantID *uuid.UUID `json:"variantId"`
+	Kind      string     `json:"kind"`
+	Quantity  int        `json:"quantity"`
+	Reason    string     `json:"reason"`
+}
+
+// AdjustStock records a restock, return or correction of a product's or variant's stock
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "AdjustStock"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	_, claims, _ := jwtauth.FromContext(ctx)
+	strUserID, ok := claims["id"].(string)
+	if !ok {
+		logger.Error("user id not found in token claims")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+		return
+	}
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	var input StockAdjustmentInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adjustment, errs := input.validateStockAdjustmentInput()
+	if errs != nil {
+		utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		return
+	}
+	adjustment.CreatedBy = userID
+
+	level, err := h.srv.AdjustStock(ctx, id, adjustment)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			if input.VariantID != nil {
+				utils.RespondWithError(w, http.StatusNotFound, "Variant not found")
+				return
+			}
+			utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+		case errors.Is(err, apperrors.ErrOutOfStock):
+			errs.Set("quantity", "stock cannot go below zero")
+			utils.RespondWithJson(w, http.StatusBadRequest, errs)
+		default:
+			logger.Error("failed to adjust stock", zap.Error(err), zap.String("productID", strID))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to adjust stock")
+		}
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusCreated, level)
+}
+
+// GetStockMovements lists the stock ledger of a product, newest first. The variantId query parameter narrows it
+// to one variant.
+func (h *ProductHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "GetStockMovements"))
+
+	strID := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strID)
+	if err != nil {
+		logger.Warn("invalid product id", zap.Error(err), zap.String("productID", strID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var variantID *uuid.UUID
+	if strVariantID := r.URL.Query().Get("variantId"); strVariantID != "" {
+		parsed, err := uuid.Parse(strVariantID)
+		if err != nil {
+			logger.Warn("invalid variant id", zap.Error(err), zap.String("variantID", strVariantID))
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant ID")
+			return
+		}
+		variantID = &parsed
+	}
+
+	page, err := pageParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	movements, err := h.srv.GetStockMovements(ctx, id, variantID, page)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			utils.RespondWithError(w, http.StatusNotFound, "Product not found")
+		case errors.Is(err, pagination.ErrInvalidCursor):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.Error("failed to retrieve stock movements", zap.Error(err), zap.String("productID", strID))
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve stock movements")
+		}
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, movements)
+}
+
+func (si *StockAdjustmentInput) validateStockAdjustmentInput() (models.StockAdjustment, errsx.Map) {
+	var errs errsx.Map
+
+	kind, err := inventory.ValidateAdjustmentKind(si.Kind)
+	if err != nil {
+		errs.Set("kind", err)
+	} else if _, err := inventory.ValidateQuantity(kind, si.Quantity); err != nil {
+		errs.Set("quantity", err)
+	}
+
+	reason, err := inventory.ValidateReason(si.Reason)
+	if err != nil {
+		errs.Set("reason", err)
+	}
+
+	if errs != nil {
+		return models.StockAdjustment{}, errs
+	}
+
+	return models.StockAdjustment{
+		VariantID: si.VariantID,
+		Kind:      string(kind),
+		Quantity:  si.Quantity,
+		Reason:    string(reason),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/api/middleware"
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/service"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type StockSubscriptionHandler struct {
+	srvSubscription *service.StockSubscriptionService
+	srvProduct      *service.ProductService
+	logger          *zap.Logger
+}
+
+func NewStockSubscriptionHandler(srvSubscription *service.StockSubscriptionService, srvProduct *service.ProductService) *StockSubscriptionHandler {
+	return &StockSubscriptionHandler{
+		srvSubscription: srvSubscription,
+		srvProduct:      srvProduct,
+		logger:          config.GetLogger(),
+	}
+}
+
+type StockSubscriptionInput struct {
+	VariantID *uuid.UUID `json:"variantId"`
+}
+
+// NotifyMe subscribes the user to an email once the product, or the variant in the optional request body, is
+// restocked
+func (h *StockSubscriptionHandler) NotifyMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "NotifyMe"))
+
+	productID, ok := ctx.Value(middleware.ProductIDKey).(uuid.UUID)
+	if !ok {
+		logger.Info("failed to retrieve product id from context")
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	_, claims, _ := jwtauth.FromContext(ctx)
+	strUserID, ok := claims["id"].(string)
+	if !ok {
+		logger.Error("user id not found in token claims")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+		return
+	}
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	var input StockSubscriptionInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Only the product and variant are checked, subscribing is allowed whatever the current stock
+	if _, err := h.srvProduct.GetPurchasableItem(ctx, productID, input.VariantID, 0); err != nil {
+		if respondWithPurchaseError(w, err) {
+			return
+		}
+		logger.Error("failed to retrieve product", zap.Error(err), zap.String("productID", productID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	created, err := h.srvSubscription.Subscribe(ctx, userID, productID, input.VariantID)
+	if err != nil {
+		logger.Error("failed to subscribe to product", zap.Error(err), zap.String("productID", productID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to subscribe to product")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	utils.RespondWithJson(w, status, map[string]string{
+		"message": "You will be emailed when the product is back in stock",
+	})
+}
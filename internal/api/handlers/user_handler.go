@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/domain/user"
 	"github.com/CP-Payne/ecomstore/internal/service"
 	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -51,3 +56,48 @@ func (h *UserHandler) GetUserDetails(w http.ResponseWriter, r *http.Request) {
 
 	utils.RespondWithJson(w, http.StatusOK, user)
 }
+
+func (h *UserHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "UpdateUserRole"))
+
+	strUserID := chi.URLParam(r, "id")
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Warn("invalid user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input struct {
+		Role string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	role, err := user.ValidateRole(input.Role)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updatedUser, err := h.srvUser.SetUserRole(ctx, userID, role)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		logger.Error("failed to update user role", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update user role")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, map[string]interface{}{
+		"message": "User role updated",
+		"user":    updatedUser,
+	})
+}
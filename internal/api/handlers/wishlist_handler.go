@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/domain/wishlist"
+	"github.com/CP-Payne/ecomstore/internal/service"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WishlistHandler struct {
+	srvWishlist *service.WishlistService
+	srvCart     *service.CartService
+	srvProduct  *service.ProductService
+	logger      *zap.Logger
+}
+
+func NewWishlistHandler(srvWishlist *service.WishlistService, srvCart *service.CartService, srvProduct *service.ProductService) *WishlistHandler {
+	return &WishlistHandler{
+		srvWishlist: srvWishlist,
+		srvCart:     srvCart,
+		srvProduct:  srvProduct,
+		logger:      config.GetLogger(),
+	}
+}
+
+func (h *WishlistHandler) GetWishlists(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "GetWishlists"))
+
+	userID, ok := h.userID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	wishlists, err := h.srvWishlist.GetWishlists(r.Context(), userID)
+	if err != nil {
+		logger.Error("failed to retrieve wishlists", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve wishlists")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, wishlists)
+}
+
+func (h *WishlistHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "CreateWishlist"))
+
+	type WishlistInput struct {
+		Name string `json:"name"`
+	}
+
+	userID, ok := h.userID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var input WishlistInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	name, err := wishlist.ValidateName(input.Name)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.srvWishlist.CreateWishlist(r.Context(), userID, name)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrConflict) {
+			utils.RespondWithError(w, http.StatusConflict, "A wishlist with this name already exists")
+			return
+		}
+		logger.Error("failed to create wishlist", zap.Error(err), zap.String("userID", userID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create wishlist")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusCreated, created)
+}
+
+func (h *WishlistHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "GetWishlist"))
+
+	userID, wishlistID, ok := h.userAndWishlistID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	found, err := h.srvWishlist.GetWishlist(r.Context(), userID, wishlistID)
+	if err != nil {
+		h.respondWithWishlistError(w, logger, err, "Failed to retrieve wishlist")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, found)
+}
+
+// UpdateWishlist renames a wishlist and turns its share link on or off, leaving out a field keeps it unchanged
+func (h *WishlistHandler) UpdateWishlist(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "UpdateWishlist"))
+
+	type WishlistInput struct {
+		Name   *string `json:"name"`
+		Shared *bool   `json:"shared"`
+	}
+
+	userID, wishlistID, ok := h.userAndWishlistID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var input WishlistInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var name *wishlist.Name
+	if input.Name != nil {
+		validated, err := wishlist.ValidateName(*input.Name)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		name = &validated
+	}
+
+	updated, err := h.srvWishlist.UpdateWishlist(r.Context(), userID, wishlistID, name, input.Shared)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrConflict) {
+			utils.RespondWithError(w, http.StatusConflict, "Wishlist cannot be renamed to this name")
+			return
+		}
+		h.respondWithWishlistError(w, logger, err, "Failed to update wishlist")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, updated)
+}
+
+func (h *WishlistHandler) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "DeleteWishlist"))
+
+	userID, wishlistID, ok := h.userAndWishlistID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	if err := h.srvWishlist.DeleteWishlist(r.Context(), userID, wishlistID); err != nil {
+		h.respondWithWishlistError(w, logger, err, "Failed to delete wishlist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WishlistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "AddWishlistItem"))
+
+	type ItemInput struct {
+		ProductID uuid.UUID  `json:"productId"`
+		VariantID *uuid.UUID `json:"variantId"`
+		Quantity  int        `json:"quantity"`
+	}
+
+	userID, wishlistID, ok := h.userAndWishlistID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var input ItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	quantity, err := wishlist.ValidateQuantity(input.Quantity)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Only the product and variant are checked, items out of stock can still be wished for
+	if _, err := h.srvProduct.GetPurchasableItem(ctx, input.ProductID, input.VariantID, 0); err != nil {
+		if respondWithPurchaseError(w, err) {
+			return
+		}
+		logger.Error("failed to retrieve product", zap.Error(err), zap.String("productID", input.ProductID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return
+	}
+
+	if err := h.srvWishlist.AddItem(ctx, userID, wishlistID, input.ProductID, input.VariantID, quantity); err != nil {
+		h.respondWithWishlistError(w, logger, err, "Failed to add item to wishlist")
+		return
+	}
+
+	updated, err := h.srvWishlist.GetWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		h.respondWithWishlistError(w, logger, err, "Failed to retrieve wishlist")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, updated)
+}
+
+// RemoveItem removes the product in the path, or its variant in the variantId query parameter, from a wishlist
+func (h *WishlistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "RemoveWishlistItem"))
+
+	userID, wishlistID, ok := h.userAndWishlistID(w, r, logger)
+	if !ok {
+		return
+	}
+	productID, variantID, ok := wishlistItemParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.srvWishlist.RemoveItem(ctx, userID, wishlistID, productID, variantID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Item not found on wishlist")
+			return
+		}
+		logger.Error("failed to remove wishlist item", zap.Error(err), zap.String("wishlistID", wishlistID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to remove item from wishlist")
+		return
+	}
+
+	updated, err := h.srvWishlist.GetWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		h.respondWithWishlistError(w, logger, err, "Failed to retrieve wishlist")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, updated)
+}
+
+// MoveToCart moves a wishlist item into the user's cart and returns the cart
+func (h *WishlistHandler) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "MoveToCart"))
+
+	userID, wishlistID, ok := h.userAndWishlistID(w, r, logger)
+	if !ok {
+		return
+	}
+	productID, variantID, ok := wishlistItemParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.srvWishlist.MoveToCart(ctx, userID, wishlistID, productID, variantID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Item not found on wishlist")
+			return
+		}
+		if respondWithPurchaseError(w, err) {
+			logger.Info("wishlist item cannot be moved to cart", zap.Error(err), zap.String("productID", productID.String()))
+			return
+		}
+		logger.Error("failed to move wishlist item to cart", zap.Error(err), zap.String("wishlistID", wishlistID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to move item to cart")
+		return
+	}
+
+	cart, err := h.srvCart.GetCart(ctx, userID)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, cart)
+}
+
+// SaveForLater moves an item out of the user's cart into their saved for later list and returns the cart
+func (h *WishlistHandler) SaveForLater(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.logger.With(zap.String("handler", "SaveForLater"))
+
+	type ItemInput struct {
+		ProductID uuid.UUID  `json:"productId"`
+		VariantID *uuid.UUID `json:"variantId"`
+	}
+
+	userID, ok := h.userID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var input ItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		logger.Warn("failed to decode request body", zap.Error(err))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.srvWishlist.SaveForLater(ctx, userID, input.ProductID, input.VariantID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Item not found in cart")
+			return
+		}
+		logger.Error("failed to save item for later", zap.Error(err), zap.String("productID", input.ProductID.String()))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to save item for later")
+		return
+	}
+
+	cart, err := h.srvCart.GetCart(ctx, userID)
+	if err != nil {
+		logger.Error("failed to retrieve cart", zap.Error(err))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve cart")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, cart)
+}
+
+// GetSharedWishlist is the read-only view of a wishlist behind a share link, open to anyone with the link
+func (h *WishlistHandler) GetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.With(zap.String("handler", "GetSharedWishlist"))
+
+	shared, err := h.srvWishlist.GetSharedWishlist(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.respondWithWishlistError(w, logger, err, "Failed to retrieve wishlist")
+		return
+	}
+
+	utils.RespondWithJson(w, http.StatusOK, shared)
+}
+
+func (h *WishlistHandler) userID(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (uuid.UUID, bool) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	strUserID, ok := claims["id"].(string)
+	if !ok {
+		logger.Error("user id not found in token claims")
+		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(strUserID)
+	if err != nil {
+		logger.Error("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func (h *WishlistHandler) userAndWishlistID(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := h.userID(w, r, logger)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	wishlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid wishlist id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, wishlistID, true
+}
+
+// wishlistItemParams reads the item's product from the path and its optional variant from the query
+func wishlistItemParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, *uuid.UUID, bool) {
+	productID, err := uuid.Parse(chi.URLParam(r, "productId"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid product id")
+		return uuid.Nil, nil, false
+	}
+
+	strVariantID := r.URL.Query().Get("variantId")
+	if strVariantID == "" {
+		return productID, nil, true
+	}
+	variantID, err := uuid.Parse(strVariantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid variant id")
+		return uuid.Nil, nil, false
+	}
+
+	return productID, &variantID, true
+}
+
+func (h *WishlistHandler) respondWithWishlistError(w http.ResponseWriter, logger *zap.Logger, err error, message string) {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Wishlist not found")
+		return
+	}
+	logger.Error("wishlist request failed", zap.Error(err))
+	utils.RespondWithError(w, http.StatusInternalServerError, message)
+}
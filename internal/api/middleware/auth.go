@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CP-Payne/ecomstore/internal/domain/user"
+	"github.com/CP-Payne/ecomstore/internal/service"
+	"github.com/CP-Payne/ecomstore/internal/utils"
+	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RevocationMiddleware rejects verified tokens that were revoked on logout. It must run after jwtauth.Authenticator.
+func RevocationMiddleware(srvToken *service.TokenService, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			logger := logger.With(zap.String("middleware", "RevocationMiddleware"))
+
+			token, claims, err := jwtauth.FromContext(r.Context())
+			if err != nil || token == nil {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+				return
+			}
+
+			// Tokens without a jti predate token expiry and can never be revoked, reject them
+			jti := token.JwtID()
+			if jti == "" {
+				logger.Info("token without jti rejected")
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+				return
+			}
+
+			strUserID, _ := claims["id"].(string)
+			userID, err := uuid.Parse(strUserID)
+			if err != nil {
+				logger.Warn("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+				return
+			}
+
+			// JSON numbers decode as float64, tokens issued before generations existed have none and count as 0
+			generation, _ := claims["gen"].(float64)
+
+			revoked, err := srvToken.IsTokenRevoked(r.Context(), jti, userID, int32(generation))
+			if err != nil {
+				logger.Error("failed to check token revocation", zap.Error(err), zap.String("userID", userID.String()))
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+				return
+			}
+
+			if revoked {
+				logger.Info("revoked token rejected", zap.String("userID", userID.String()))
+				utils.RespondWithError(w, http.StatusUnauthorized, "Token has been revoked")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// OptionalAuthMiddleware lets requests without a token through as guests, while requests carrying a token must
+// pass the same checks as authenticated routes. It must run after jwtauth.Verifier.
+func OptionalAuthMiddleware(srvToken *service.TokenService, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := jwtauth.Authenticator(RevocationMiddleware(srvToken, logger)(next))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, _, err := jwtauth.FromContext(r.Context()); errors.Is(err, jwtauth.ErrNoTokenFound) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// VerifiedEmailMiddleware only lets users with a verified email address through. It must run after jwtauth.Authenticator.
+func VerifiedEmailMiddleware(srvUser *service.UserService, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			logger := logger.With(zap.String("middleware", "VerifiedEmailMiddleware"))
+
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			strUserID, _ := claims["id"].(string)
+			userID, err := uuid.Parse(strUserID)
+			if err != nil {
+				logger.Warn("failed to parse user id", zap.Error(err), zap.String("userID", strUserID))
+				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authentication")
+				return
+			}
+
+			verified, err := srvUser.IsEmailVerified(r.Context(), userID)
+			if err != nil {
+				logger.Error("failed to check email verification", zap.Error(err), zap.String("userID", userID.String()))
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to process request")
+				return
+			}
+
+			if !verified {
+				logger.Info("unverified user blocked", zap.String("userID", userID.String()))
+				utils.RespondWithError(w, http.StatusForbidden, "Email address must be verified")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole only lets users holding one of the given roles through. It must run after jwtauth.Authenticator.
+func RequireRole(logger *zap.Logger, roles ...user.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			role, _ := claims["role"].(string)
+
+			for _, allowed := range roles {
+				if user.Role(role) == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			logger.Info("insufficient role",
+				zap.String("middleware", "RequireRole"),
+				zap.String("role", role),
+				zap.Any("userID", claims["id"]),
+				zap.String("path", r.URL.Path),
+			)
+			utils.RespondWithError(w, http.StatusForbidden, "Insufficient permissions")
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/CP-Payne/ecomstore/internal/api/handlers"
 	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/domain/user"
 	"github.com/CP-Payne/ecomstore/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,7 +15,9 @@ import (
 	cmid "github.com/CP-Payne/ecomstore/internal/api/middleware"
 )
 
-func SetupRouter(cfg *config.Config) http.Handler {
+// SetupRouter builds the API's routes and the background workers its services rely on. The workers are not
+// started, the caller runs them for as long as the server is up.
+func SetupRouter(cfg *config.Config) (http.Handler, *Workers) {
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
@@ -26,29 +29,73 @@ func SetupRouter(cfg *config.Config) http.Handler {
 		cfg.Logger.Fatal("failed to setup router", zap.Error(err))
 	}
 
-	userSrv := service.NewUserService(cfg.DB)
-	productSrv := service.NewProductService(cfg.DB)
+	mailer, err := service.NewMailer(cfg.Mailer)
+	if err != nil {
+		cfg.Logger.Fatal("failed to setup router", zap.Error(err))
+	}
+
+	blobStore, err := service.NewBlobStore(cfg.Storage)
+	if err != nil {
+		cfg.Logger.Fatal("failed to setup router", zap.Error(err))
+	}
+
+	currencySrv, err := service.NewCurrencyService(cfg.Currency)
+	if err != nil {
+		cfg.Logger.Fatal("failed to setup router", zap.Error(err))
+	}
+
+	stockNotifier, err := service.NewStockNotifier(cfg.Alerts, mailer)
+	if err != nil {
+		cfg.Logger.Fatal("failed to setup router", zap.Error(err))
+	}
+
+	alertSrv := service.NewStockAlertService(cfg.DB, stockNotifier)
+
+	userSrv := service.NewUserService(cfg.DB, cfg.SqlDB, mailer, cfg.AppURL)
+	tokenSrv := service.NewTokenService(cfg.DB, cfg.SqlDB)
+	stockSubscriptionSrv := service.NewStockSubscriptionService(cfg.DB, mailer, cfg.AppURL)
+	productSrv := service.NewProductService(cfg.DB, cfg.SqlDB, alertSrv, stockSubscriptionSrv)
+	productImageSrv := service.NewProductImageService(cfg.DB, cfg.SqlDB, blobStore, cfg.Storage.PublicURL)
 	reviewSrv := service.NewReviewService(cfg.DB)
-	cartSrv := service.NewCartService(cfg.DB)
-	orderSrv := service.NewOrderService(cfg.DB, cfg.SqlDB)
-	paymentSrv := service.NewPaymentService(cfg.DB, paypalProcessor, orderSrv, productSrv, cartSrv)
+	cartSrv := service.NewCartService(cfg.DB, cfg.SqlDB, currencySrv, cfg.Cart)
+	cartRecoverySrv := service.NewCartRecoveryService(cfg.DB, cfg.SqlDB, cartSrv, mailer, cfg.AppURL, cfg.Cart)
+	orderSrv := service.NewOrderService(cfg.DB, cfg.SqlDB, cfg.Policy.ReservationTTL, alertSrv, currencySrv)
+	wishlistSrv := service.NewWishlistService(cfg.DB, cfg.SqlDB, cartSrv, productSrv, currencySrv)
+	paymentSrv := service.NewPaymentService(cfg.DB, paypalProcessor, orderSrv, productSrv, cartSrv, cartRecoverySrv)
 
-	authHandler := handlers.NewAuthHandler(userSrv)
+	authHandler := handlers.NewAuthHandler(userSrv, tokenSrv, cartSrv)
 	productHandler := handlers.NewProductHandler(productSrv)
+	productImageHandler := handlers.NewProductImageHandler(productImageSrv)
 	reviewHander := handlers.NewReviewHandler(reviewSrv, productSrv)
 	cartHandler := handlers.NewCartHandler(cartSrv, productSrv)
 	userHandler := handlers.NewUserHandler(userSrv)
 	paymentHandler := handlers.NewPaymentHandler(productSrv, paymentSrv, cartSrv, orderSrv)
 	orderHandler := handlers.NewOrderHandler(orderSrv)
+	stockSubscriptionHandler := handlers.NewStockSubscriptionHandler(stockSubscriptionSrv, productSrv)
+	currencyHandler := handlers.NewCurrencyHandler(currencySrv)
+	cartRecoveryHandler := handlers.NewCartRecoveryHandler(cartRecoverySrv)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistSrv, cartSrv, productSrv)
 
 	r.Group(func(r chi.Router) {
 		r.Post("/register", authHandler.RegisterUser)
 		r.Post("/login", authHandler.LoginUser)
+		r.Post("/auth/refresh", authHandler.RefreshTokens)
+		r.Post("/password/forgot", authHandler.ForgotPassword)
+		r.Post("/password/reset", authHandler.ResetPassword)
+		r.Get("/verify-email", authHandler.VerifyEmail)
 
 		r.Get("/products", productHandler.GetAllProducts)
+		r.Get("/products/search", productHandler.SearchProducts)
+		r.Get("/products/facets", productHandler.GetProductFacets)
 		r.Get("/products/{id}", productHandler.GetProduct)
+		r.Get("/products/{id}/images", productImageHandler.GetProductImages)
+		r.Get("/images/*", productImageHandler.ServeImage)
+		r.Get("/currencies", currencyHandler.GetCurrencies)
+		r.Get("/cart/recover", cartRecoveryHandler.RecoverCart)
+		r.Get("/wishlists/shared/{token}", wishlistHandler.GetSharedWishlist)
 
 		r.Get("/payment/capture-order", paymentHandler.CaptureOrder)
+		r.Get("/payment/cancel-order", paymentHandler.CancelOrder)
 
 		r.Get("/products/categories", productHandler.GetProductCategories)
 		r.Get("/products/categories/{id}", productHandler.GetProductsByCategory)
@@ -58,18 +105,48 @@ func SetupRouter(cfg *config.Config) http.Handler {
 	r.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(config.GetTokenAuth()))
 		r.Use(jwtauth.Authenticator)
+		r.Use(cmid.RevocationMiddleware(tokenSrv, cfg.Logger))
+
+		r.Post("/auth/logout", authHandler.Logout)
+		r.Post("/auth/logout-all", authHandler.LogoutAllDevices)
+		r.Post("/verify-email/resend", authHandler.ResendVerificationEmail)
 
 		r.Get("/user/profile", userHandler.GetUserDetails)
 		r.Get("/user/orders", orderHandler.GetUserOrders)
 
-		r.Post("/payment/create-order/product", paymentHandler.CreateOrderProduct)
-		r.Post("/payment/create-order/cart", paymentHandler.CreateOrderCart)
+		r.Get("/wishlists", wishlistHandler.GetWishlists)
+		r.Post("/wishlists", wishlistHandler.CreateWishlist)
+		r.Get("/wishlists/{id}", wishlistHandler.GetWishlist)
+		r.Patch("/wishlists/{id}", wishlistHandler.UpdateWishlist)
+		r.Delete("/wishlists/{id}", wishlistHandler.DeleteWishlist)
+		r.Post("/wishlists/{id}/items", wishlistHandler.AddItem)
+		r.Delete("/wishlists/{id}/items/{productId}", wishlistHandler.RemoveItem)
+		r.Post("/wishlists/{id}/items/{productId}/move-to-cart", wishlistHandler.MoveToCart)
+		r.Post("/cart/save-for-later", wishlistHandler.SaveForLater)
+
+		r.Group(func(r chi.Router) {
+			if cfg.Policy.RequireVerifiedEmail {
+				r.Use(cmid.VerifiedEmailMiddleware(userSrv, cfg.Logger))
+			}
+			r.Post("/payment/create-order/product", paymentHandler.CreateOrderProduct)
+			r.Post("/payment/create-order/cart", paymentHandler.CreateOrderCart)
+		})
+
+	})
+
+	// Cart routes are open to guests, whose cart is kept in a signed cookie until they log in
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(config.GetTokenAuth()))
+		r.Use(cmid.OptionalAuthMiddleware(tokenSrv, cfg.Logger))
 
 		r.Get("/cart", cartHandler.GetCart)
 		r.Post("/cart/add", cartHandler.AddToCart)
 		r.Post("/cart/remove", cartHandler.RemoveFromCart)
 		r.Post("/cart/reduce", cartHandler.ReduceFromCart)
-
+		r.Put("/cart/items/{productId}", cartHandler.SetItemQuantity)
+		r.Patch("/cart", cartHandler.UpdateCart)
+		r.Post("/cart/adjust", cartHandler.AdjustCart)
+		r.Put("/cart/currency", cartHandler.SetCartCurrency)
 	})
 
 	r.Group(func(r chi.Router) {
@@ -80,16 +157,72 @@ func SetupRouter(cfg *config.Config) http.Handler {
 	r.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(config.GetTokenAuth()))
 		r.Use(jwtauth.Authenticator)
+		r.Use(cmid.RevocationMiddleware(tokenSrv, cfg.Logger))
 		r.Use(cmid.ProductMiddleware(productSrv, cfg.Logger))
 
 		r.Get("/products/{id}/reviews/user", reviewHander.GetUserReviewForProduct)
 		r.Patch("/products/{id}/reviews", reviewHander.UpdateUserReview)
 		r.Delete("/products/{id}/reviews", reviewHander.DeleteReview)
 		r.Post("/products/{id}/reviews", reviewHander.AddReview)
+
+		r.Post("/products/{id}/notify-me", stockSubscriptionHandler.NotifyMe)
 	})
+
+	// Admin routes
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(config.GetTokenAuth()))
+		r.Use(jwtauth.Authenticator)
+		r.Use(cmid.RevocationMiddleware(tokenSrv, cfg.Logger))
+		r.Use(cmid.RequireRole(cfg.Logger, user.RoleAdmin))
+
+		r.Patch("/admin/users/{id}/role", userHandler.UpdateUserRole)
+	})
+
+	// Catalogue management routes
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(config.GetTokenAuth()))
+		r.Use(jwtauth.Authenticator)
+		r.Use(cmid.RevocationMiddleware(tokenSrv, cfg.Logger))
+		r.Use(cmid.RequireRole(cfg.Logger, user.RoleAdmin, user.RoleStaff))
+
+		r.Get("/admin/products", productHandler.AdminGetProducts)
+		r.Post("/admin/products", productHandler.CreateProduct)
+		r.Get("/admin/products/{id}", productHandler.AdminGetProduct)
+		r.Put("/admin/products/{id}", productHandler.UpdateProduct)
+		r.Delete("/admin/products/{id}", productHandler.ArchiveProduct)
+		r.Post("/admin/products/{id}/restore", productHandler.RestoreProduct)
+
+		r.Get("/admin/products/{id}/variants", productHandler.AdminGetProductVariants)
+		r.Post("/admin/products/{id}/variants", productHandler.CreateProductVariant)
+		r.Put("/admin/products/{id}/variants/{variantId}", productHandler.UpdateProductVariant)
+		r.Delete("/admin/products/{id}/variants/{variantId}", productHandler.ArchiveProductVariant)
+		r.Post("/admin/products/{id}/variants/{variantId}/restore", productHandler.RestoreProductVariant)
+
+		r.Post("/admin/products/{id}/stock", productHandler.AdjustStock)
+		r.Get("/admin/products/{id}/stock/movements", productHandler.GetStockMovements)
+
+		r.Post("/admin/products/{id}/images", productImageHandler.UploadProductImages)
+		r.Put("/admin/products/{id}/images/order", productImageHandler.ReorderProductImages)
+		r.Delete("/admin/products/{id}/images/{imageId}", productImageHandler.DeleteProductImage)
+
+		r.Post("/admin/categories", productHandler.CreateCategory)
+		r.Put("/admin/categories/{id}", productHandler.UpdateCategory)
+		r.Delete("/admin/categories/{id}", productHandler.DeleteCategory)
+
+		r.Post("/admin/catalog/import", productHandler.ImportCatalog)
+		r.Get("/admin/catalog/export", productHandler.ExportCatalog)
+	})
+
 	r.Get("/home", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("This will be the home page"))
 	}))
 
-	return r
+	workers := &Workers{
+		cfg:          cfg,
+		alerts:       alertSrv,
+		cartRecovery: cartRecoverySrv,
+		orders:       orderSrv,
+	}
+
+	return r, workers
 }
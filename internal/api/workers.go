@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/service"
+)
+
+// reservationCheckInterval is how often unpaid orders past their reservation are expired
+const reservationCheckInterval = time.Minute
+
+// Workers are the background jobs that run alongside the API server
+type Workers struct {
+	cfg          *config.Config
+	alerts       *service.StockAlertService
+	cartRecovery *service.CartRecoveryService
+	orders       *service.OrderService
+}
+
+// Run runs every worker until the context is cancelled and returns once all of them have stopped
+func (w *Workers) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	// Stock alerts are checked as stock changes and on a schedule
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.alerts.Run(ctx, w.cfg.Alerts.CheckInterval)
+	}()
+
+	// Idle carts are abandoned, their owners emailed and stale carts purged
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.cartRecovery.Run(ctx, w.cfg.Cart.CheckInterval)
+	}()
+
+	// Stock reserved by unpaid orders is released once the orders expire
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.orders.RunReservationExpiry(ctx, reservationCheckInterval)
+	}()
+
+	wg.Wait()
+}
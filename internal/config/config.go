@@ -4,8 +4,11 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
@@ -14,9 +17,16 @@ import (
 type Config struct {
 	Logger           *zap.Logger
 	Port             string
+	AppURL           string
 	DB               *database.Queries
 	SqlDB            *sql.DB
 	PaymentProcessor *ProcessorConfig
+	Mailer           *MailerConfig
+	Storage          *StorageConfig
+	Alerts           *AlertConfig
+	Currency         *CurrencyConfig
+	Cart             *CartConfig
+	Policy           *PolicyConfig
 }
 
 type ProcessorConfig struct {
@@ -25,6 +35,69 @@ type ProcessorConfig struct {
 	Port         string
 }
 
+type PolicyConfig struct {
+	// RequireVerifiedEmail blocks checkout for users who have not verified their email address
+	RequireVerifiedEmail bool
+	// ReservationTTL is how long stock stays reserved for an order that has not been paid yet
+	ReservationTTL time.Duration
+}
+
+type StorageConfig struct {
+	// Driver selects the blob storage implementation, currently only "local"
+	Driver string
+	Dir    string
+	// PublicURL is the base URL stored objects are served from
+	PublicURL string
+}
+
+type AlertConfig struct {
+	// Driver selects the stock alert notifier: "log", "email" or "webhook"
+	Driver        string
+	EmailTo       string
+	WebhookURL    string
+	WebhookSecret string
+	// CheckInterval is how often every stock level is checked, stock changes made through the API are checked
+	// as they happen
+	CheckInterval time.Duration
+}
+
+type CartConfig struct {
+	// Secret signs the cookie identifying a guest's cart and the links in cart recovery emails
+	Secret string
+	// AbandonAfter is how long a cart has to be left alone before it is abandoned and a recovery email is sent
+	AbandonAfter time.Duration
+	// PurgeAfter is how long an abandoned cart is kept before it is deleted
+	PurgeAfter    time.Duration
+	CheckInterval time.Duration
+}
+
+type CurrencyConfig struct {
+	// Base is the currency product prices are entered and stored in
+	Base money.Currency
+	// RatesFile is a JSON object of the other currencies customers can pay in, mapped to how many units of each
+	// one unit of the base currency buys, e.g. {"EUR": "0.92"}. Without it only the base currency is offered.
+	RatesFile string
+}
+
+// baseCurrency is the currency stored prices are read in, set once by New
+var baseCurrency = money.USD
+
+// BaseCurrency returns the store's base currency, USD unless STORE_CURRENCY says otherwise
+func BaseCurrency() money.Currency {
+	return baseCurrency
+}
+
+type MailerConfig struct {
+	// Driver selects the mailer implementation: "log", "file" or "smtp"
+	Driver       string
+	From         string
+	Dir          string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
 func New() *Config {
 	logger := GetLogger()
 
@@ -34,6 +107,12 @@ func New() *Config {
 
 	port := os.Getenv("PORT")
 
+	// Base URL used to build links sent to users (e.g. password reset)
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = fmt.Sprintf("http://localhost:%s", port)
+	}
+
 	// Database initialisation
 
 	dbUser := os.Getenv("POSTGRES_USER")
@@ -51,8 +130,99 @@ func New() *Config {
 	ppClientID := os.Getenv("PAYPAL_CLIENT")
 	ppClientSecret := os.Getenv("PAYPAL_SECRET")
 
+	requireVerifiedEmail := os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true"
+
+	reservationTTL := 15 * time.Minute
+	if v := os.Getenv("RESERVATION_TTL_MINUTES"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes < 1 {
+			logger.Fatal("RESERVATION_TTL_MINUTES must be a positive number of minutes", zap.String("value", v))
+		}
+		reservationTTL = time.Duration(minutes) * time.Minute
+	}
+
+	mailerDriver := os.Getenv("MAILER_DRIVER")
+	if mailerDriver == "" {
+		mailerDriver = "log"
+	}
+	mailerDir := os.Getenv("MAILER_DIR")
+	if mailerDir == "" {
+		mailerDir = "./mail"
+	}
+
+	storageDriver := os.Getenv("STORAGE_DRIVER")
+	if storageDriver == "" {
+		storageDriver = "local"
+	}
+	storageDir := os.Getenv("STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = "./uploads"
+	}
+	storagePublicURL := os.Getenv("STORAGE_PUBLIC_URL")
+	if storagePublicURL == "" {
+		storagePublicURL = appURL + "/images"
+	}
+
+	alertDriver := os.Getenv("STOCK_ALERT_DRIVER")
+	if alertDriver == "" {
+		alertDriver = "log"
+	}
+	alertInterval := time.Hour
+	if v := os.Getenv("STOCK_ALERT_INTERVAL_MINUTES"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes < 1 {
+			logger.Fatal("STOCK_ALERT_INTERVAL_MINUTES must be a positive number of minutes", zap.String("value", v))
+		}
+		alertInterval = time.Duration(minutes) * time.Minute
+	}
+
+	if v := os.Getenv("STORE_CURRENCY"); v != "" {
+		currency, err := money.ParseCurrency(v)
+		if err != nil {
+			logger.Fatal("STORE_CURRENCY must be a three letter currency code", zap.String("value", v))
+		}
+		baseCurrency = currency
+	}
+
+	// GUEST_CART_SECRET is the name CART_SECRET had before it signed recovery links too
+	cartSecret := os.Getenv("CART_SECRET")
+	if cartSecret == "" {
+		cartSecret = os.Getenv("GUEST_CART_SECRET")
+	}
+	if cartSecret == "" {
+		cartSecret = os.Getenv("JWT_SECRET")
+	}
+	cartAbandonAfter := 24 * time.Hour
+	if v := os.Getenv("CART_ABANDON_AFTER_HOURS"); v != "" {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours < 1 {
+			logger.Fatal("CART_ABANDON_AFTER_HOURS must be a positive number of hours", zap.String("value", v))
+		}
+		cartAbandonAfter = time.Duration(hours) * time.Hour
+	}
+	cartPurgeAfter := 30 * 24 * time.Hour
+	if v := os.Getenv("CART_PURGE_AFTER_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 {
+			logger.Fatal("CART_PURGE_AFTER_DAYS must be a positive number of days", zap.String("value", v))
+		}
+		cartPurgeAfter = time.Duration(days) * 24 * time.Hour
+	}
+	cartCheckInterval := time.Hour
+	if v := os.Getenv("CART_CHECK_INTERVAL_MINUTES"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes < 1 {
+			logger.Fatal("CART_CHECK_INTERVAL_MINUTES must be a positive number of minutes", zap.String("value", v))
+		}
+		cartCheckInterval = time.Duration(minutes) * time.Minute
+	}
+	if cartPurgeAfter <= cartAbandonAfter {
+		logger.Fatal("CART_PURGE_AFTER_DAYS must be longer than CART_ABANDON_AFTER_HOURS")
+	}
+
 	return &Config{
 		Port:   port,
+		AppURL: appURL,
 		Logger: logger,
 		SqlDB:  db,
 		DB:     database.New(db),
@@ -61,5 +231,40 @@ func New() *Config {
 			ClientSecret: ppClientSecret,
 			Port:         port,
 		},
+		Mailer: &MailerConfig{
+			Driver:       mailerDriver,
+			From:         os.Getenv("MAIL_FROM"),
+			Dir:          mailerDir,
+			SMTPHost:     os.Getenv("SMTP_HOST"),
+			SMTPPort:     os.Getenv("SMTP_PORT"),
+			SMTPUsername: os.Getenv("SMTP_USERNAME"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		},
+		Storage: &StorageConfig{
+			Driver:    storageDriver,
+			Dir:       storageDir,
+			PublicURL: storagePublicURL,
+		},
+		Alerts: &AlertConfig{
+			Driver:        alertDriver,
+			EmailTo:       os.Getenv("STOCK_ALERT_EMAIL"),
+			WebhookURL:    os.Getenv("STOCK_ALERT_WEBHOOK_URL"),
+			WebhookSecret: os.Getenv("STOCK_ALERT_WEBHOOK_SECRET"),
+			CheckInterval: alertInterval,
+		},
+		Cart: &CartConfig{
+			Secret:        cartSecret,
+			AbandonAfter:  cartAbandonAfter,
+			PurgeAfter:    cartPurgeAfter,
+			CheckInterval: cartCheckInterval,
+		},
+		Currency: &CurrencyConfig{
+			Base:      baseCurrency,
+			RatesFile: os.Getenv("EXCHANGE_RATES_FILE"),
+		},
+		Policy: &PolicyConfig{
+			RequireVerifiedEmail: requireVerifiedEmail,
+			ReservationTTL:       reservationTTL,
+		},
 	}
 }
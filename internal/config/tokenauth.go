@@ -3,11 +3,19 @@ package config
 import (
 	"os"
 	"sync"
+	"time"
 
 	"github.com/go-chi/jwtauth"
 	"github.com/google/uuid"
 )
 
+const (
+	// AccessTokenTTL is how long a signed JWT access token remains valid
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a refresh token can be exchanged for a new token pair
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 var (
 	so        sync.Once
 	tokenAuth *jwtauth.JWTAuth
@@ -26,7 +34,18 @@ func GetTokenAuth() *jwtauth.JWTAuth {
 	return tokenAuth
 }
 
-func MakeToken(email string, id uuid.UUID) string {
-	_, tokenString, _ := GetTokenAuth().Encode(map[string]interface{}{"email": email, "id": id})
+// MakeToken signs a short lived access token issued at issuedAt for the user's current session generation
+func MakeToken(email string, id uuid.UUID, role string, generation int32, issuedAt time.Time) string {
+	claims := map[string]interface{}{
+		"email": email,
+		"id":    id,
+		"role":  role,
+		"gen":   generation,
+		"jti":   uuid.New().String(),
+	}
+	jwtauth.SetIssuedAt(claims, issuedAt)
+	jwtauth.SetExpiry(claims, issuedAt.Add(AccessTokenTTL))
+
+	_, tokenString, _ := GetTokenAuth().Encode(claims)
 	return tokenString
 }
@@ -7,15 +7,20 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
 const addItemToCart = `-- name: AddItemToCart :exec
-INSERT INTO cart_items (id, cart_id, product_id, quantity)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (cart_id, product_id)
+INSERT INTO cart_items (id, cart_id, product_id, variant_id, quantity, price_at_add)
+SELECT $1, $2, p.id, $4, $5, coalesce(v.price, p.price)
+FROM products p
+LEFT JOIN product_variants v ON v.id = $4 AND v.product_id = p.id
+WHERE p.id = $3
+ON CONFLICT (cart_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
 DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
 `
 
@@ -23,6 +28,7 @@ type AddItemToCartParams struct {
 	ID        uuid.UUID
 	CartID    uuid.UUID
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 	Quantity  int32
 }
 
@@ -31,6 +37,32 @@ func (q *Queries) AddItemToCart(ctx context.Context, arg AddItemToCartParams) er
 		arg.ID,
 		arg.CartID,
 		arg.ProductID,
+		arg.VariantID,
+		arg.Quantity,
+	)
+	return err
+}
+
+const adjustCartItem = `-- name: AdjustCartItem :exec
+UPDATE cart_items ci
+SET quantity = $4, price_at_add = coalesce(v.price, p.price)
+FROM products p
+LEFT JOIN product_variants v ON v.id = $3 AND v.product_id = p.id
+WHERE ci.cart_id = $1 AND ci.product_id = $2 AND ci.variant_id IS NOT DISTINCT FROM $3 AND p.id = ci.product_id
+`
+
+type AdjustCartItemParams struct {
+	CartID    uuid.UUID
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Quantity  int32
+}
+
+func (q *Queries) AdjustCartItem(ctx context.Context, arg AdjustCartItemParams) error {
+	_, err := q.db.ExecContext(ctx, adjustCartItem,
+		arg.CartID,
+		arg.ProductID,
+		arg.VariantID,
 		arg.Quantity,
 	)
 	return err
@@ -43,7 +75,7 @@ VALUES ($1, $2, 'active')
 
 type CreateCartParams struct {
 	ID     uuid.UUID
-	UserID uuid.UUID
+	UserID uuid.NullUUID
 }
 
 func (q *Queries) CreateCart(ctx context.Context, arg CreateCartParams) error {
@@ -62,17 +94,18 @@ func (q *Queries) DeleteCart(ctx context.Context, id uuid.UUID) error {
 }
 
 const getActiveCart = `-- name: GetActiveCart :one
-SELECT id, user_id, status, created_at
+SELECT id, user_id, status, currency, created_at
 FROM carts
-WHERE user_id=$1 AND status='active'
+WHERE user_id=$1 AND status IN ('active', 'abandoned')
 ORDER BY created_at DESC
 LIMIT 1
 `
 
 type GetActiveCartRow struct {
 	ID        uuid.UUID
-	UserID    uuid.UUID
+	UserID    uuid.NullUUID
 	Status    string
+	Currency  sql.NullString
 	CreatedAt time.Time
 }
 
@@ -83,19 +116,67 @@ func (q *Queries) GetActiveCart(ctx context.Context, userID uuid.UUID) (GetActiv
 		&i.ID,
 		&i.UserID,
 		&i.Status,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCartByID = `-- name: GetCartByID :one
+SELECT id, user_id, status, currency, created_at
+FROM carts
+WHERE id=$1
+`
+
+type GetCartByIDRow struct {
+	ID        uuid.UUID
+	UserID    uuid.NullUUID
+	Status    string
+	Currency  sql.NullString
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetCartByID(ctx context.Context, id uuid.UUID) (GetCartByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getCartByID, id)
+	var i GetCartByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.Currency,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const getCartItem = `-- name: GetCartItem :one
+SELECT quantity
+FROM cart_items
+WHERE cart_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3
+`
+
+type GetCartItemParams struct {
+	CartID    uuid.UUID
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+}
+
+func (q *Queries) GetCartItem(ctx context.Context, arg GetCartItemParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getCartItem, arg.CartID, arg.ProductID, arg.VariantID)
+	var quantity int32
+	err := row.Scan(&quantity)
+	return quantity, err
+}
+
 const getCartItems = `-- name: GetCartItems :many
-SELECT product_id, quantity
+SELECT product_id, variant_id, quantity
 FROM cart_items
 WHERE cart_id=$1
 `
 
 type GetCartItemsRow struct {
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 	Quantity  int32
 }
 
@@ -108,7 +189,58 @@ func (q *Queries) GetCartItems(ctx context.Context, cartID uuid.UUID) ([]GetCart
 	var items []GetCartItemsRow
 	for rows.Next() {
 		var i GetCartItemsRow
-		if err := rows.Scan(&i.ProductID, &i.Quantity); err != nil {
+		if err := rows.Scan(&i.ProductID, &i.VariantID, &i.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCartItemsWithStock = `-- name: GetCartItemsWithStock :many
+SELECT ci.product_id, ci.variant_id, ci.quantity, coalesce(v.stock_quantity, p.stock_quantity)::integer AS stock_quantity,
+    (p.is_active AND CASE
+        WHEN ci.variant_id IS NULL THEN NOT EXISTS (
+            SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_active = true
+        )
+        ELSE v.is_active
+    END)::boolean AS available
+FROM cart_items ci
+JOIN products p ON p.id = ci.product_id
+LEFT JOIN product_variants v ON v.id = ci.variant_id
+WHERE ci.cart_id = $1
+`
+
+type GetCartItemsWithStockRow struct {
+	ProductID     uuid.UUID
+	VariantID     uuid.NullUUID
+	Quantity      int32
+	StockQuantity int32
+	Available     bool
+}
+
+func (q *Queries) GetCartItemsWithStock(ctx context.Context, cartID uuid.UUID) ([]GetCartItemsWithStockRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCartItemsWithStock, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCartItemsWithStockRow
+	for rows.Next() {
+		var i GetCartItemsWithStockRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.VariantID,
+			&i.Quantity,
+			&i.StockQuantity,
+			&i.Available,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -123,20 +255,36 @@ func (q *Queries) GetCartItems(ctx context.Context, cartID uuid.UUID) ([]GetCart
 }
 
 const getCartWithItems = `-- name: GetCartWithItems :many
-SELECT c.id AS cart_id, c.user_id, ci.product_id, ci.quantity, p.name, p.price
+SELECT c.id AS cart_id, c.user_id, ci.product_id, ci.variant_id, ci.quantity, p.name,
+    coalesce(v.price, p.price)::text AS price, ci.price_at_add::text AS price_at_add,
+    v.sku AS variant_sku, v.attributes AS variant_attributes,
+    coalesce(v.stock_quantity, p.stock_quantity)::integer AS stock_quantity,
+    (p.is_active AND CASE
+        WHEN ci.variant_id IS NULL THEN NOT EXISTS (
+            SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_active = true
+        )
+        ELSE v.is_active
+    END)::boolean AS available
 FROM carts c
 JOIN cart_items ci ON c.id = ci.cart_id
 JOIN products p ON ci.product_id = p.id
+LEFT JOIN product_variants v ON ci.variant_id = v.id
 WHERE c.id = $1
 `
 
 type GetCartWithItemsRow struct {
-	CartID    uuid.UUID
-	UserID    uuid.UUID
-	ProductID uuid.UUID
-	Quantity  int32
-	Name      string
-	Price     string
+	CartID            uuid.UUID
+	UserID            uuid.NullUUID
+	ProductID         uuid.UUID
+	VariantID         uuid.NullUUID
+	Quantity          int32
+	Name              string
+	Price             string
+	PriceAtAdd        string
+	VariantSku        sql.NullString
+	VariantAttributes pqtype.NullRawMessage
+	StockQuantity     int32
+	Available         bool
 }
 
 func (q *Queries) GetCartWithItems(ctx context.Context, id uuid.UUID) ([]GetCartWithItemsRow, error) {
@@ -152,9 +300,15 @@ func (q *Queries) GetCartWithItems(ctx context.Context, id uuid.UUID) ([]GetCart
 			&i.CartID,
 			&i.UserID,
 			&i.ProductID,
+			&i.VariantID,
 			&i.Quantity,
 			&i.Name,
 			&i.Price,
+			&i.PriceAtAdd,
+			&i.VariantSku,
+			&i.VariantAttributes,
+			&i.StockQuantity,
+			&i.Available,
 		); err != nil {
 			return nil, err
 		}
@@ -169,15 +323,104 @@ func (q *Queries) GetCartWithItems(ctx context.Context, id uuid.UUID) ([]GetCart
 	return items, nil
 }
 
+const getGuestCart = `-- name: GetGuestCart :one
+SELECT id, status, currency, created_at
+FROM carts
+WHERE id=$1 AND user_id IS NULL AND status IN ('active', 'abandoned')
+`
+
+type GetGuestCartRow struct {
+	ID        uuid.UUID
+	Status    string
+	Currency  sql.NullString
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetGuestCart(ctx context.Context, id uuid.UUID) (GetGuestCartRow, error) {
+	row := q.db.QueryRowContext(ctx, getGuestCart, id)
+	var i GetGuestCartRow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const markAbandonedCarts = `-- name: MarkAbandonedCarts :many
+UPDATE carts
+SET status = 'abandoned'
+WHERE status = 'active' AND updated_at < $1
+RETURNING id, user_id
+`
+
+type MarkAbandonedCartsRow struct {
+	ID     uuid.UUID
+	UserID uuid.NullUUID
+}
+
+func (q *Queries) MarkAbandonedCarts(ctx context.Context, updatedAt time.Time) ([]MarkAbandonedCartsRow, error) {
+	rows, err := q.db.QueryContext(ctx, markAbandonedCarts, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarkAbandonedCartsRow
+	for rows.Next() {
+		var i MarkAbandonedCartsRow
+		if err := rows.Scan(&i.ID, &i.UserID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeAbandonedCarts = `-- name: PurgeAbandonedCarts :execrows
+DELETE FROM carts
+WHERE status = 'abandoned' AND updated_at < $1
+`
+
+func (q *Queries) PurgeAbandonedCarts(ctx context.Context, updatedAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeAbandonedCarts, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reactivateCart = `-- name: ReactivateCart :exec
+UPDATE carts
+SET status = 'active', updated_at = $2
+WHERE id = $1 AND status = 'abandoned'
+`
+
+type ReactivateCartParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) ReactivateCart(ctx context.Context, arg ReactivateCartParams) error {
+	_, err := q.db.ExecContext(ctx, reactivateCart, arg.ID, arg.UpdatedAt)
+	return err
+}
+
 const reduceItemFromCart = `-- name: ReduceItemFromCart :exec
 WITH updated AS (
     UPDATE cart_items
-    SET quantity = cart_items.quantity - $3
-    WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2
+    SET quantity = cart_items.quantity - $4
+    WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND cart_items.variant_id IS NOT DISTINCT FROM $3
     RETURNING cart_items.quantity
 )
 DELETE FROM cart_items
-WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND EXISTS (
+WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND cart_items.variant_id IS NOT DISTINCT FROM $3 AND EXISTS (
     SELECT 1 FROM updated WHERE updated.quantity <= 0
 )
 `
@@ -185,25 +428,78 @@ WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND EXISTS (
 type ReduceItemFromCartParams struct {
 	CartID    uuid.UUID
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 	Quantity  int32
 }
 
 func (q *Queries) ReduceItemFromCart(ctx context.Context, arg ReduceItemFromCartParams) error {
-	_, err := q.db.ExecContext(ctx, reduceItemFromCart, arg.CartID, arg.ProductID, arg.Quantity)
+	_, err := q.db.ExecContext(ctx, reduceItemFromCart,
+		arg.CartID,
+		arg.ProductID,
+		arg.VariantID,
+		arg.Quantity,
+	)
 	return err
 }
 
 const removeItemFromCart = `-- name: RemoveItemFromCart :exec
 DELETE FROM cart_items
-WHERE cart_id=$1 AND product_id=$2
+WHERE cart_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3
 `
 
 type RemoveItemFromCartParams struct {
 	CartID    uuid.UUID
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 }
 
 func (q *Queries) RemoveItemFromCart(ctx context.Context, arg RemoveItemFromCartParams) error {
-	_, err := q.db.ExecContext(ctx, removeItemFromCart, arg.CartID, arg.ProductID)
+	_, err := q.db.ExecContext(ctx, removeItemFromCart, arg.CartID, arg.ProductID, arg.VariantID)
+	return err
+}
+
+const setCartCurrency = `-- name: SetCartCurrency :exec
+UPDATE carts
+SET currency = $2, updated_at = $3
+WHERE id = $1
+`
+
+type SetCartCurrencyParams struct {
+	ID        uuid.UUID
+	Currency  sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetCartCurrency(ctx context.Context, arg SetCartCurrencyParams) error {
+	_, err := q.db.ExecContext(ctx, setCartCurrency, arg.ID, arg.Currency, arg.UpdatedAt)
+	return err
+}
+
+const setCartItemQuantity = `-- name: SetCartItemQuantity :exec
+INSERT INTO cart_items (id, cart_id, product_id, variant_id, quantity, price_at_add)
+SELECT $1, $2, p.id, $4, $5, coalesce(v.price, p.price)
+FROM products p
+LEFT JOIN product_variants v ON v.id = $4 AND v.product_id = p.id
+WHERE p.id = $3
+ON CONFLICT (cart_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
+DO UPDATE SET quantity = EXCLUDED.quantity
+`
+
+type SetCartItemQuantityParams struct {
+	ID        uuid.UUID
+	CartID    uuid.UUID
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Quantity  int32
+}
+
+func (q *Queries) SetCartItemQuantity(ctx context.Context, arg SetCartItemQuantityParams) error {
+	_, err := q.db.ExecContext(ctx, setCartItemQuantity,
+		arg.ID,
+		arg.CartID,
+		arg.ProductID,
+		arg.VariantID,
+		arg.Quantity,
+	)
 	return err
 }
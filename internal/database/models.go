@@ -6,6 +6,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,23 +15,52 @@ import (
 
 type Cart struct {
 	ID        uuid.UUID
-	UserID    uuid.UUID
+	UserID    uuid.NullUUID
 	Status    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	Currency  sql.NullString
 }
 
 type CartItem struct {
-	ID        uuid.UUID
-	CartID    uuid.UUID
-	ProductID uuid.UUID
-	Quantity  int32
+	ID         uuid.UUID
+	CartID     uuid.UUID
+	ProductID  uuid.UUID
+	Quantity   int32
+	VariantID  uuid.NullUUID
+	PriceAtAdd string
+}
+
+type CartRecovery struct {
+	ID          uuid.UUID
+	CartID      uuid.UUID
+	UserID      uuid.UUID
+	AbandonedAt time.Time
+	EmailSentAt sql.NullTime
+	RestoredAt  sql.NullTime
+	OrderID     uuid.NullUUID
+	ConvertedAt sql.NullTime
 }
 
 type Category struct {
 	ID          uuid.UUID
 	Name        string
 	Description sql.NullString
+	ParentID    uuid.NullUUID
+	Slug        string
+	SortOrder   int32
+}
+
+type InventoryReservation struct {
+	ID        uuid.UUID
+	OrderID   uuid.UUID
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Quantity  int32
+	Status    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type Order struct {
@@ -47,6 +77,8 @@ type Order struct {
 	CartID           uuid.NullUUID
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	Currency         string
+	ExchangeRate     string
 }
 
 type OrderItem struct {
@@ -55,24 +87,59 @@ type OrderItem struct {
 	ProductID uuid.UUID
 	Quantity  int32
 	Price     string
+	VariantID uuid.NullUUID
 }
 
 type Product struct {
-	ID             uuid.UUID
-	Name           string
-	Description    sql.NullString
-	Price          string
-	Brand          sql.NullString
-	Sku            string
-	StockQuantity  int32
-	CategoryID     uuid.UUID
-	ImageUrl       sql.NullString
-	ThumbnailUrl   sql.NullString
-	Specifications pqtype.NullRawMessage
-	Variants       pqtype.NullRawMessage
-	IsActive       bool
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID               uuid.UUID
+	Name             string
+	Description      sql.NullString
+	Price            string
+	Brand            sql.NullString
+	Sku              string
+	StockQuantity    int32
+	CategoryID       uuid.UUID
+	ImageUrl         sql.NullString
+	ThumbnailUrl     sql.NullString
+	Specifications   pqtype.NullRawMessage
+	IsActive         bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ReorderThreshold int32
+}
+
+type ProductImage struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	Position  int32
+	AltText   sql.NullString
+	Format    string
+	Width     int32
+	Height    int32
+	CreatedAt time.Time
+}
+
+type ProductVariant struct {
+	ID            uuid.UUID
+	ProductID     uuid.UUID
+	Sku           string
+	Price         string
+	StockQuantity int32
+	Attributes    json.RawMessage
+	IsActive      bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type RefreshToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	FamilyID   uuid.UUID
+	TokenHash  string
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	ReplacedBy uuid.NullUUID
+	CreatedAt  time.Time
 }
 
 type Review struct {
@@ -88,11 +155,88 @@ type Review struct {
 	Anonymous  bool
 }
 
+type RevokedToken struct {
+	Jti       string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+type StockAlert struct {
+	ID               uuid.UUID
+	ProductID        uuid.UUID
+	VariantID        uuid.NullUUID
+	Level            string
+	StockQuantity    int32
+	ReorderThreshold int32
+	CreatedAt        time.Time
+	NotifiedAt       sql.NullTime
+	ResolvedAt       sql.NullTime
+}
+
+type StockMovement struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Kind      string
+	Quantity  int32
+	OrderID   uuid.NullUUID
+	Reason    sql.NullString
+	CreatedBy uuid.NullUUID
+	CreatedAt time.Time
+}
+
+type StockSubscription struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	ProductID  uuid.UUID
+	VariantID  uuid.NullUUID
+	CreatedAt  time.Time
+	NotifiedAt sql.NullTime
+}
+
 type User struct {
-	ID             uuid.UUID
-	Name           sql.NullString
-	Email          string
-	HashedPassword string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID              uuid.UUID
+	Name            sql.NullString
+	Email           string
+	HashedPassword  string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	EmailVerifiedAt sql.NullTime
+	Role            string
+}
+
+type UserSessionRevocation struct {
+	UserID        uuid.UUID
+	RevokedBefore time.Time
+	Generation    int32
+}
+
+type UserToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type Wishlist struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Name          string
+	SavedForLater bool
+	ShareToken    sql.NullString
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type WishlistItem struct {
+	ID         uuid.UUID
+	WishlistID uuid.UUID
+	ProductID  uuid.UUID
+	VariantID  uuid.NullUUID
+	Quantity   int32
+	CreatedAt  time.Time
 }
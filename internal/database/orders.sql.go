@@ -13,10 +13,30 @@ import (
 	"github.com/google/uuid"
 )
 
+const cancelOrder = `-- name: CancelOrder :execrows
+UPDATE orders
+    SET status = 'CANCELLED', updated_at = $2
+    WHERE id = $1 AND status IN ('created', 'PAYER_ACTION_REQUIRED')
+`
+
+type CancelOrderParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) CancelOrder(ctx context.Context, arg CancelOrderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelOrder, arg.ID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createOrder = `-- name: CreateOrder :one
 INSERT INTO orders(
-    id, user_id, product_total,order_total, status, payment_method, shipping_price, cart_id, created_at, updated_at
-) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    id, user_id, product_total,order_total, status, payment_method, shipping_price, cart_id, created_at, updated_at,
+    currency, exchange_rate
+) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING id
 `
 
@@ -31,6 +51,8 @@ type CreateOrderParams struct {
 	CartID        uuid.NullUUID
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	Currency      string
+	ExchangeRate  string
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (uuid.UUID, error) {
@@ -45,6 +67,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (uuid.
 		arg.CartID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.Currency,
+		arg.ExchangeRate,
 	)
 	var id uuid.UUID
 	err := row.Scan(&id)
@@ -53,14 +77,15 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (uuid.
 
 const createOrderItem = `-- name: CreateOrderItem :exec
 INSERT INTO order_items(
-    id, order_id, product_id, quantity, price
-) VALUES ( $1, $2, $3, $4, $5)
+    id, order_id, product_id, variant_id, quantity, price
+) VALUES ( $1, $2, $3, $4, $5, $6)
 `
 
 type CreateOrderItemParams struct {
 	ID        uuid.UUID
 	OrderID   uuid.UUID
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 	Quantity  int32
 	Price     string
 }
@@ -70,6 +95,7 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		arg.ID,
 		arg.OrderID,
 		arg.ProductID,
+		arg.VariantID,
 		arg.Quantity,
 		arg.Price,
 	)
@@ -77,7 +103,7 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 }
 
 const getOrderByID = `-- name: GetOrderByID :one
-SELECT id, user_id, processor_order_id, product_total, status, order_total, payment_method, payment_email, payer_id, shipping_price, cart_id, created_at, updated_at FROM orders
+SELECT id, user_id, processor_order_id, product_total, status, order_total, payment_method, payment_email, payer_id, shipping_price, cart_id, created_at, updated_at, currency, exchange_rate FROM orders
 WHERE id = $1
 `
 
@@ -98,12 +124,14 @@ func (q *Queries) GetOrderByID(ctx context.Context, id uuid.UUID) (Order, error)
 		&i.CartID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
 	)
 	return i, err
 }
 
 const getOrderByProcessorOrderID = `-- name: GetOrderByProcessorOrderID :one
-SELECT id, user_id, processor_order_id, product_total, status, order_total, payment_method, payment_email, payer_id, shipping_price, cart_id, created_at, updated_at FROM orders
+SELECT id, user_id, processor_order_id, product_total, status, order_total, payment_method, payment_email, payer_id, shipping_price, cart_id, created_at, updated_at, currency, exchange_rate FROM orders
 WHERE processor_order_id = $1
 `
 
@@ -124,22 +152,27 @@ func (q *Queries) GetOrderByProcessorOrderID(ctx context.Context, processorOrder
 		&i.CartID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
 	)
 	return i, err
 }
 
 const getOrderItemsByOrderID = `-- name: GetOrderItemsByOrderID :many
-SELECT oi.quantity, p.price, p.name, oi.product_id
+SELECT oi.quantity, oi.price, p.name, oi.product_id, oi.variant_id, v.sku AS variant_sku
 FROM order_items oi
 JOIN products p ON oi.product_id = p.id
+LEFT JOIN product_variants v ON oi.variant_id = v.id
 WHERE oi.order_id = $1
 `
 
 type GetOrderItemsByOrderIDRow struct {
-	Quantity  int32
-	Price     string
-	Name      string
-	ProductID uuid.UUID
+	Quantity   int32
+	Price      string
+	Name       string
+	ProductID  uuid.UUID
+	VariantID  uuid.NullUUID
+	VariantSku sql.NullString
 }
 
 func (q *Queries) GetOrderItemsByOrderID(ctx context.Context, orderID uuid.UUID) ([]GetOrderItemsByOrderIDRow, error) {
@@ -156,6 +189,8 @@ func (q *Queries) GetOrderItemsByOrderID(ctx context.Context, orderID uuid.UUID)
 			&i.Price,
 			&i.Name,
 			&i.ProductID,
+			&i.VariantID,
+			&i.VariantSku,
 		); err != nil {
 			return nil, err
 		}
@@ -7,13 +7,156 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
+const createProduct = `-- name: CreateProduct :one
+INSERT INTO products (id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold
+`
+
+type CreateProductParams struct {
+	ID               uuid.UUID
+	Name             string
+	Description      sql.NullString
+	Price            string
+	Brand            sql.NullString
+	Sku              string
+	StockQuantity    int32
+	CategoryID       uuid.UUID
+	ImageUrl         sql.NullString
+	ThumbnailUrl     sql.NullString
+	Specifications   pqtype.NullRawMessage
+	IsActive         bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ReorderThreshold int32
+}
+
+func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, createProduct,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Price,
+		arg.Brand,
+		arg.Sku,
+		arg.StockQuantity,
+		arg.CategoryID,
+		arg.ImageUrl,
+		arg.ThumbnailUrl,
+		arg.Specifications,
+		arg.IsActive,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.ReorderThreshold,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Brand,
+		&i.Sku,
+		&i.StockQuantity,
+		&i.CategoryID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.Specifications,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
+const exportProducts = `-- name: ExportProducts :many
+SELECT p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold,
+    c.slug AS category_slug, c.name AS category_name
+FROM products p
+JOIN categories c ON c.id = p.category_id
+WHERE p.created_at > $1 OR (p.created_at = $1 AND p.id > $2)
+ORDER BY p.created_at, p.id
+LIMIT $3
+`
+
+type ExportProductsParams struct {
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+	RowLimit       int32
+}
+
+type ExportProductsRow struct {
+	ID               uuid.UUID
+	Name             string
+	Description      sql.NullString
+	Price            string
+	Brand            sql.NullString
+	Sku              string
+	StockQuantity    int32
+	CategoryID       uuid.UUID
+	ImageUrl         sql.NullString
+	ThumbnailUrl     sql.NullString
+	Specifications   pqtype.NullRawMessage
+	IsActive         bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ReorderThreshold int32
+	CategorySlug     string
+	CategoryName     string
+}
+
+func (q *Queries) ExportProducts(ctx context.Context, arg ExportProductsParams) ([]ExportProductsRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportProducts, arg.AfterCreatedAt, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportProductsRow
+	for rows.Next() {
+		var i ExportProductsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Brand,
+			&i.Sku,
+			&i.StockQuantity,
+			&i.CategoryID,
+			&i.ImageUrl,
+			&i.ThumbnailUrl,
+			&i.Specifications,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReorderThreshold,
+			&i.CategorySlug,
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAllProducts = `-- name: GetAllProducts :many
-SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, variants, is_active, created_at, updated_at FROM products
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold FROM products
+WHERE is_active = true
 `
 
 func (q *Queries) GetAllProducts(ctx context.Context) ([]Product, error) {
@@ -37,10 +180,10 @@ func (q *Queries) GetAllProducts(ctx context.Context) ([]Product, error) {
 			&i.ImageUrl,
 			&i.ThumbnailUrl,
 			&i.Specifications,
-			&i.Variants,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ReorderThreshold,
 		); err != nil {
 			return nil, err
 		}
@@ -56,8 +199,8 @@ func (q *Queries) GetAllProducts(ctx context.Context) ([]Product, error) {
 }
 
 const getProduct = `-- name: GetProduct :one
-SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, variants, is_active, created_at, updated_at FROM products
-WHERE id = $1
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold FROM products
+WHERE id = $1 AND is_active = true
 `
 
 func (q *Queries) GetProduct(ctx context.Context, id uuid.UUID) (Product, error) {
@@ -75,16 +218,45 @@ func (q *Queries) GetProduct(ctx context.Context, id uuid.UUID) (Product, error)
 		&i.ImageUrl,
 		&i.ThumbnailUrl,
 		&i.Specifications,
-		&i.Variants,
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
+const getProductAnyStatus = `-- name: GetProductAnyStatus :one
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold FROM products
+WHERE id = $1
+`
+
+func (q *Queries) GetProductAnyStatus(ctx context.Context, id uuid.UUID) (Product, error) {
+	row := q.db.QueryRowContext(ctx, getProductAnyStatus, id)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Brand,
+		&i.Sku,
+		&i.StockQuantity,
+		&i.CategoryID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.Specifications,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReorderThreshold,
 	)
 	return i, err
 }
 
 const getProductCategories = `-- name: GetProductCategories :many
-SELECT id, name, description FROM categories
+SELECT id, name, description, parent_id, slug, sort_order FROM categories
+ORDER BY sort_order, name
 `
 
 func (q *Queries) GetProductCategories(ctx context.Context) ([]Category, error) {
@@ -96,7 +268,14 @@ func (q *Queries) GetProductCategories(ctx context.Context) ([]Category, error)
 	var items []Category
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.Description); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.ParentID,
+			&i.Slug,
+			&i.SortOrder,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -111,12 +290,36 @@ func (q *Queries) GetProductCategories(ctx context.Context) ([]Category, error)
 }
 
 const getProductsByCategory = `-- name: GetProductsByCategory :many
-SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, variants, is_active, created_at, updated_at FROM products
-WHERE category_id = $1
+WITH RECURSIVE category_tree AS (
+    SELECT categories.id FROM categories WHERE categories.id = $1
+    UNION ALL
+    SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+    WHERE $2::boolean
+)
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at
+FROM products
+WHERE category_id IN (SELECT id FROM category_tree) AND is_active = true
+    AND (created_at > $3 OR (created_at = $3 AND id > $4))
+ORDER BY created_at, id
+LIMIT $5
 `
 
-func (q *Queries) GetProductsByCategory(ctx context.Context, categoryID uuid.UUID) ([]Product, error) {
-	rows, err := q.db.QueryContext(ctx, getProductsByCategory, categoryID)
+type GetProductsByCategoryParams struct {
+	CategoryID         uuid.UUID
+	IncludeDescendants bool
+	AfterCreatedAt     time.Time
+	AfterID            uuid.UUID
+	RowLimit           int32
+}
+
+func (q *Queries) GetProductsByCategory(ctx context.Context, arg GetProductsByCategoryParams) ([]Product, error) {
+	rows, err := q.db.QueryContext(ctx, getProductsByCategory,
+		arg.CategoryID,
+		arg.IncludeDescendants,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -136,10 +339,10 @@ func (q *Queries) GetProductsByCategory(ctx context.Context, categoryID uuid.UUI
 			&i.ImageUrl,
 			&i.ThumbnailUrl,
 			&i.Specifications,
-			&i.Variants,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ReorderThreshold,
 		); err != nil {
 			return nil, err
 		}
@@ -156,6 +359,7 @@ func (q *Queries) GetProductsByCategory(ctx context.Context, categoryID uuid.UUI
 
 const getTotalProducts = `-- name: GetTotalProducts :one
 SELECT COUNT(*) FROM products
+WHERE is_active = true
 `
 
 func (q *Queries) GetTotalProducts(ctx context.Context) (int64, error) {
@@ -165,9 +369,76 @@ func (q *Queries) GetTotalProducts(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const getTotalProductsByCategory = `-- name: GetTotalProductsByCategory :one
+WITH RECURSIVE category_tree AS (
+    SELECT categories.id FROM categories WHERE categories.id = $1
+    UNION ALL
+    SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+    WHERE $2::boolean
+)
+SELECT COUNT(*) FROM products
+WHERE category_id IN (SELECT id FROM category_tree) AND is_active = true
+`
+
+type GetTotalProductsByCategoryParams struct {
+	CategoryID         uuid.UUID
+	IncludeDescendants bool
+}
+
+func (q *Queries) GetTotalProductsByCategory(ctx context.Context, arg GetTotalProductsByCategoryParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getTotalProductsByCategory, arg.CategoryID, arg.IncludeDescendants)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const listAllProducts = `-- name: ListAllProducts :many
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold FROM products
+ORDER BY created_at DESC, id
+`
+
+func (q *Queries) ListAllProducts(ctx context.Context) ([]Product, error) {
+	rows, err := q.db.QueryContext(ctx, listAllProducts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Brand,
+			&i.Sku,
+			&i.StockQuantity,
+			&i.CategoryID,
+			&i.ImageUrl,
+			&i.ThumbnailUrl,
+			&i.Specifications,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReorderThreshold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProducts = `-- name: ListProducts :many
-SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, variants, is_active, created_at, updated_at FROM products
-WHERE (created_at > $1 OR (created_at = $1 AND id > $2))
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold FROM products
+WHERE is_active = true AND (created_at > $1 OR (created_at = $1 AND id > $2))
 ORDER BY created_at, id
 LIMIT $3
 `
@@ -199,10 +470,10 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 			&i.ImageUrl,
 			&i.ThumbnailUrl,
 			&i.Specifications,
-			&i.Variants,
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ReorderThreshold,
 		); err != nil {
 			return nil, err
 		}
@@ -219,7 +490,7 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 
 const productExists = `-- name: ProductExists :one
 SELECT EXISTS (
-    SELECT 1 FROM products WHERE id = $1
+    SELECT 1 FROM products WHERE id = $1 AND is_active = true
 )
 `
 
@@ -230,7 +501,297 @@ func (q *Queries) ProductExists(ctx context.Context, id uuid.UUID) (bool, error)
 	return exists, err
 }
 
-const updateStock = `-- name: UpdateStock :exec
+const searchProducts = `-- name: SearchProducts :many
+SELECT p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold,
+    ranked.rank,
+    ts_headline('english', p.name, to_tsquery('english', $1), 'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text AS name_highlight,
+    ts_headline('english', coalesce(p.description, ''), to_tsquery('english', $1), 'MaxFragments=2, MinWords=5, MaxWords=20, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text AS snippet
+FROM (
+    SELECT products.id, ts_rank_cd(product_search_document(products.name, products.brand, products.description, products.specifications), to_tsquery('english', $1), 32)::real AS rank
+    FROM products
+    WHERE products.is_active = true
+        AND product_search_document(products.name, products.brand, products.description, products.specifications) @@ to_tsquery('english', $1)
+) ranked
+JOIN products p ON p.id = ranked.id
+WHERE ranked.rank < $2::real OR (ranked.rank = $2::real AND p.id > $3)
+ORDER BY ranked.rank DESC, p.id
+LIMIT $4
+`
+
+type SearchProductsParams struct {
+	Query     string
+	AfterRank float32
+	AfterID   uuid.UUID
+	RowLimit  int32
+}
+
+type SearchProductsRow struct {
+	ID               uuid.UUID
+	Name             string
+	Description      sql.NullString
+	Price            string
+	Brand            sql.NullString
+	Sku              string
+	StockQuantity    int32
+	CategoryID       uuid.UUID
+	ImageUrl         sql.NullString
+	ThumbnailUrl     sql.NullString
+	Specifications   pqtype.NullRawMessage
+	IsActive         bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ReorderThreshold int32
+	Rank             float32
+	NameHighlight    string
+	Snippet          string
+}
+
+func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchProducts,
+		arg.Query,
+		arg.AfterRank,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchProductsRow
+	for rows.Next() {
+		var i SearchProductsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Brand,
+			&i.Sku,
+			&i.StockQuantity,
+			&i.CategoryID,
+			&i.ImageUrl,
+			&i.ThumbnailUrl,
+			&i.Specifications,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReorderThreshold,
+			&i.Rank,
+			&i.NameHighlight,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProductsFuzzy = `-- name: SearchProductsFuzzy :many
+SELECT p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold,
+    ranked.rank,
+    p.name::text AS name_highlight,
+    left(coalesce(p.description, ''), 200)::text AS snippet
+FROM (
+    SELECT products.id, greatest(word_similarity($1, products.name), word_similarity($1, coalesce(products.brand, '')))::real AS rank
+    FROM products
+    WHERE ($1 <% products.name OR $1 <% products.brand)
+        AND products.is_active = true
+) ranked
+JOIN products p ON p.id = ranked.id
+WHERE ranked.rank < $2::real OR (ranked.rank = $2::real AND p.id > $3)
+ORDER BY ranked.rank DESC, p.id
+LIMIT $4
+`
+
+type SearchProductsFuzzyParams struct {
+	Query     string
+	AfterRank float32
+	AfterID   uuid.UUID
+	RowLimit  int32
+}
+
+type SearchProductsFuzzyRow struct {
+	ID               uuid.UUID
+	Name             string
+	Description      sql.NullString
+	Price            string
+	Brand            sql.NullString
+	Sku              string
+	StockQuantity    int32
+	CategoryID       uuid.UUID
+	ImageUrl         sql.NullString
+	ThumbnailUrl     sql.NullString
+	Specifications   pqtype.NullRawMessage
+	IsActive         bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ReorderThreshold int32
+	Rank             float32
+	NameHighlight    string
+	Snippet          string
+}
+
+func (q *Queries) SearchProductsFuzzy(ctx context.Context, arg SearchProductsFuzzyParams) ([]SearchProductsFuzzyRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchProductsFuzzy,
+		arg.Query,
+		arg.AfterRank,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchProductsFuzzyRow
+	for rows.Next() {
+		var i SearchProductsFuzzyRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Brand,
+			&i.Sku,
+			&i.StockQuantity,
+			&i.CategoryID,
+			&i.ImageUrl,
+			&i.ThumbnailUrl,
+			&i.Specifications,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReorderThreshold,
+			&i.Rank,
+			&i.NameHighlight,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setProductActive = `-- name: SetProductActive :one
+UPDATE products
+SET is_active = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold
+`
+
+type SetProductActiveParams struct {
+	ID        uuid.UUID
+	IsActive  bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetProductActive(ctx context.Context, arg SetProductActiveParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, setProductActive, arg.ID, arg.IsActive, arg.UpdatedAt)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Brand,
+		&i.Sku,
+		&i.StockQuantity,
+		&i.CategoryID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.Specifications,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
+const setWordSimilarityThreshold = `-- name: SetWordSimilarityThreshold :exec
+SELECT set_config('pg_trgm.word_similarity_threshold', $1::real::text, true)
+`
+
+func (q *Queries) SetWordSimilarityThreshold(ctx context.Context, threshold float32) error {
+	_, err := q.db.ExecContext(ctx, setWordSimilarityThreshold, threshold)
+	return err
+}
+
+const updateProduct = `-- name: UpdateProduct :one
+UPDATE products
+SET name = $2, description = $3, price = $4, brand = $5, sku = $6, stock_quantity = $7, category_id = $8,
+    image_url = $9, thumbnail_url = $10, specifications = $11, updated_at = $12, reorder_threshold = $13
+WHERE id = $1
+RETURNING id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold
+`
+
+type UpdateProductParams struct {
+	ID               uuid.UUID
+	Name             string
+	Description      sql.NullString
+	Price            string
+	Brand            sql.NullString
+	Sku              string
+	StockQuantity    int32
+	CategoryID       uuid.UUID
+	ImageUrl         sql.NullString
+	ThumbnailUrl     sql.NullString
+	Specifications   pqtype.NullRawMessage
+	UpdatedAt        time.Time
+	ReorderThreshold int32
+}
+
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, updateProduct,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Price,
+		arg.Brand,
+		arg.Sku,
+		arg.StockQuantity,
+		arg.CategoryID,
+		arg.ImageUrl,
+		arg.ThumbnailUrl,
+		arg.Specifications,
+		arg.UpdatedAt,
+		arg.ReorderThreshold,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Brand,
+		&i.Sku,
+		&i.StockQuantity,
+		&i.CategoryID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.Specifications,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
+const updateStock = `-- name: UpdateStock :execrows
 UPDATE products
 SET stock_quantity = stock_quantity - $2
 WHERE id = $1 AND stock_quantity >= $2
@@ -241,7 +802,76 @@ type UpdateStockParams struct {
 	StockQuantity int32
 }
 
-func (q *Queries) UpdateStock(ctx context.Context, arg UpdateStockParams) error {
-	_, err := q.db.ExecContext(ctx, updateStock, arg.ID, arg.StockQuantity)
-	return err
+func (q *Queries) UpdateStock(ctx context.Context, arg UpdateStockParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateStock, arg.ID, arg.StockQuantity)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertProductBySku = `-- name: UpsertProductBySku :one
+INSERT INTO products (id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (sku) DO UPDATE
+SET name = EXCLUDED.name, description = EXCLUDED.description, price = EXCLUDED.price, brand = EXCLUDED.brand,
+    stock_quantity = EXCLUDED.stock_quantity, category_id = EXCLUDED.category_id, image_url = EXCLUDED.image_url,
+    thumbnail_url = EXCLUDED.thumbnail_url, specifications = EXCLUDED.specifications, is_active = EXCLUDED.is_active,
+    updated_at = EXCLUDED.updated_at
+RETURNING id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold
+`
+
+type UpsertProductBySkuParams struct {
+	ID             uuid.UUID
+	Name           string
+	Description    sql.NullString
+	Price          string
+	Brand          sql.NullString
+	Sku            string
+	StockQuantity  int32
+	CategoryID     uuid.UUID
+	ImageUrl       sql.NullString
+	ThumbnailUrl   sql.NullString
+	Specifications pqtype.NullRawMessage
+	IsActive       bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) UpsertProductBySku(ctx context.Context, arg UpsertProductBySkuParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, upsertProductBySku,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Price,
+		arg.Brand,
+		arg.Sku,
+		arg.StockQuantity,
+		arg.CategoryID,
+		arg.ImageUrl,
+		arg.ThumbnailUrl,
+		arg.Specifications,
+		arg.IsActive,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Brand,
+		&i.Sku,
+		&i.StockQuantity,
+		&i.CategoryID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.Specifications,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReorderThreshold,
+	)
+	return i, err
 }
@@ -16,7 +16,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, email,name, hashed_password, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, name, email, hashed_password, created_at, updated_at
+RETURNING id, name, email, hashed_password, created_at, updated_at, email_verified_at, role
 `
 
 type CreateUserParams struct {
@@ -45,12 +45,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, hashed_password, created_at, updated_at FROM users WHERE email = $1
+SELECT id, name, email, hashed_password, created_at, updated_at, email_verified_at, role FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -63,25 +65,118 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, name, email, hashed_password, created_at, updated_at, email_verified_at, role FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUserDetails = `-- name: GetUserDetails :one
-SELECT id, email, name 
+SELECT id, email, name, email_verified_at, role
 FROM users
 WHERE id = $1
 `
 
 type GetUserDetailsRow struct {
-	ID    uuid.UUID
-	Email string
-	Name  sql.NullString
+	ID              uuid.UUID
+	Email           string
+	Name            sql.NullString
+	EmailVerifiedAt sql.NullTime
+	Role            string
 }
 
 func (q *Queries) GetUserDetails(ctx context.Context, id uuid.UUID) (GetUserDetailsRow, error) {
 	row := q.db.QueryRowContext(ctx, getUserDetails, id)
 	var i GetUserDetailsRow
-	err := row.Scan(&i.ID, &i.Email, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.EmailVerifiedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const setUserEmailVerified = `-- name: SetUserEmailVerified :exec
+UPDATE users
+    SET email_verified_at = $2, updated_at = $3
+    WHERE id = $1
+`
+
+type SetUserEmailVerifiedParams struct {
+	ID              uuid.UUID
+	EmailVerifiedAt sql.NullTime
+	UpdatedAt       time.Time
+}
+
+func (q *Queries) SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) error {
+	_, err := q.db.ExecContext(ctx, setUserEmailVerified, arg.ID, arg.EmailVerifiedAt, arg.UpdatedAt)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+    SET role = $2, updated_at = $3
+    WHERE id = $1
+    RETURNING id, name, email, hashed_password, created_at, updated_at, email_verified_at, role
+`
+
+type SetUserRoleParams struct {
+	ID        uuid.UUID
+	Role      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.ID, arg.Role, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+	)
 	return i, err
 }
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+    SET hashed_password = $2, updated_at = $3
+    WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID             uuid.UUID
+	HashedPassword string
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword, arg.UpdatedAt)
+	return err
+}
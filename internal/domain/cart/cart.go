@@ -0,0 +1,72 @@
+package cart
+
+import (
+	"fmt"
+
+	"github.com/CP-Payne/ecomstore/pkg/money"
+)
+
+// WarningCode identifies why a cart item no longer matches what the shopper added
+type WarningCode string
+
+const (
+	WarningPriceChanged      WarningCode = "price_changed"
+	WarningInsufficientStock WarningCode = "insufficient_stock"
+	WarningUnavailable       WarningCode = "unavailable"
+)
+
+// Warning tells the shopper that an item changed since it was added to their cart
+type Warning struct {
+	Code              WarningCode  `json:"code"`
+	Message           string       `json:"message"`
+	PreviousPrice     *money.Money `json:"previousPrice,omitempty"`
+	AvailableQuantity *int         `json:"availableQuantity,omitempty"`
+}
+
+// Line is the state of a cart item compared to its product as it is now
+type Line struct {
+	Quantity int
+	// AddedPrice is the price when the item was added, Price the current one
+	AddedPrice money.Money
+	Price      money.Money
+	Stock      int
+	// Available is false once the product or variant is archived, or a variant must now be chosen
+	Available bool
+}
+
+// Warnings lists what changed about the line since it was added. An unavailable item only gets that warning, as
+// its price and stock no longer matter.
+func (l Line) Warnings() []Warning {
+	if !l.Available {
+		return []Warning{{Code: WarningUnavailable, Message: "This item is no longer available"}}
+	}
+
+	var warnings []Warning
+	if l.AddedPrice != l.Price {
+		previous := l.AddedPrice
+		warnings = append(warnings, Warning{
+			Code:          WarningPriceChanged,
+			Message:       fmt.Sprintf("The price changed from %s to %s", l.AddedPrice, l.Price),
+			PreviousPrice: &previous,
+		})
+	}
+	if l.Quantity > l.Stock {
+		stock := max(l.Stock, 0)
+		warnings = append(warnings, Warning{
+			Code:              WarningInsufficientStock,
+			Message:           fmt.Sprintf("Only %d left in stock", stock),
+			AvailableQuantity: &stock,
+		})
+	}
+
+	return warnings
+}
+
+// Adjusted returns the quantity the line can be bought in now, 0 when it has to be removed from the cart
+func (l Line) Adjusted() int {
+	if !l.Available {
+		return 0
+	}
+
+	return max(min(l.Quantity, l.Stock), 0)
+}
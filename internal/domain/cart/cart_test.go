@@ -0,0 +1,45 @@
+package cart
+
+import (
+	"testing"
+
+	"github.com/CP-Payne/ecomstore/pkg/money"
+)
+
+func TestWarnings(t *testing.T) {
+	price := money.New(1999, money.USD)
+	raised := money.New(2499, money.USD)
+
+	tests := []struct {
+		name     string
+		line     Line
+		expected []WarningCode
+		adjusted int
+	}{
+		{"unchanged", Line{Quantity: 2, AddedPrice: price, Price: price, Stock: 5, Available: true}, nil, 2},
+		{"price changed", Line{Quantity: 2, AddedPrice: price, Price: raised, Stock: 5, Available: true}, []WarningCode{WarningPriceChanged}, 2},
+		{"insufficient stock", Line{Quantity: 4, AddedPrice: price, Price: price, Stock: 3, Available: true}, []WarningCode{WarningInsufficientStock}, 3},
+		{"out of stock", Line{Quantity: 1, AddedPrice: price, Price: price, Stock: 0, Available: true}, []WarningCode{WarningInsufficientStock}, 0},
+		{"price and stock", Line{Quantity: 4, AddedPrice: price, Price: raised, Stock: 1, Available: true}, []WarningCode{WarningPriceChanged, WarningInsufficientStock}, 1},
+		{"unavailable", Line{Quantity: 1, AddedPrice: price, Price: raised, Stock: 0, Available: false}, []WarningCode{WarningUnavailable}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := tt.line.Warnings()
+
+			if len(warnings) != len(tt.expected) {
+				t.Fatalf("expected warnings %v but got %v", tt.expected, warnings)
+			}
+			for i, warning := range warnings {
+				if warning.Code != tt.expected[i] {
+					t.Fatalf("expected warnings %v but got %v", tt.expected, warnings)
+				}
+			}
+
+			if result := tt.line.Adjusted(); result != tt.adjusted {
+				t.Fatalf("expected adjusted quantity %d but got %d", tt.adjusted, result)
+			}
+		})
+	}
+}
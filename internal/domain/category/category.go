@@ -0,0 +1,58 @@
+package category
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Name string
+
+func ValidateName(n string) (Name, error) {
+	n = strings.TrimSpace(n)
+	if n == "" || len(n) > 255 {
+		return "", errors.New("name is required and must be at most 255 characters")
+	}
+
+	return Name(n), nil
+}
+
+type Slug string
+
+const maxSlugLength = 100
+
+var slugPattern = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+func ValidateSlug(s string) (Slug, error) {
+	if len(s) > maxSlugLength || !slugPattern.MatchString(s) {
+		return "", errors.New("slug must be at most 100 lowercase letters or numbers separated by single dashes")
+	}
+
+	return Slug(s), nil
+}
+
+// Slugify derives a slug from a category name, e.g. "Tech Accessories" becomes "tech-accessories".
+// Letters outside of ASCII are dropped, so the result can be empty.
+func Slugify(name string) Slug {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsNumber(r))
+	})
+
+	slug := strings.Join(words, "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+
+	return Slug(slug)
+}
+
+type SortOrder int
+
+func ValidateSortOrder(o int) (SortOrder, error) {
+	if o < 0 || o > 10000 {
+		return 0, errors.New("sort order must be between 0 and 10000")
+	}
+
+	return SortOrder(o), nil
+}
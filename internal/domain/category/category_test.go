@@ -0,0 +1,69 @@
+package category
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSlugValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Slug
+		err      error
+	}{
+		{"laptops", "laptops", nil},
+		{"tech-accessories", "tech-accessories", nil},
+		{"usb-c-2", "usb-c-2", nil},
+		{"", "", errors.New("slug must be at most 100 lowercase letters or numbers separated by single dashes")},
+		{"Laptops", "", errors.New("slug must be at most 100 lowercase letters or numbers separated by single dashes")},
+		{"tech--accessories", "", errors.New("slug must be at most 100 lowercase letters or numbers separated by single dashes")},
+		{"-laptops", "", errors.New("slug must be at most 100 lowercase letters or numbers separated by single dashes")},
+		{"tech accessories", "", errors.New("slug must be at most 100 lowercase letters or numbers separated by single dashes")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateSlug(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Slug
+	}{
+		{"Laptops", "laptops"},
+		{"Tech Accessories", "tech-accessories"},
+		{"  Home & Office  ", "home-office"},
+		{"USB-C Hubs", "usb-c-hubs"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := Slugify(tt.input)
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package inventory
+
+import (
+	"errors"
+	"strings"
+)
+
+// MovementKind is the cause of a change in stock recorded in the stock movement ledger
+type MovementKind string
+
+const (
+	MovementSale        MovementKind = "sale"
+	MovementRestock     MovementKind = "restock"
+	MovementReturn      MovementKind = "return"
+	MovementAdjustment  MovementKind = "adjustment"
+	MovementReservation MovementKind = "reservation"
+)
+
+// ValidateAdjustmentKind accepts the kinds of movement staff can record by hand. Sales and reservations are only
+// ever recorded by checkout.
+func ValidateAdjustmentKind(k string) (MovementKind, error) {
+	switch kind := MovementKind(k); kind {
+	case MovementRestock, MovementReturn, MovementAdjustment:
+		return kind, nil
+	default:
+		return "", errors.New("kind must be one of restock, return or adjustment")
+	}
+}
+
+type Quantity int
+
+// ValidateQuantity checks the signed quantity of a manual movement. Restocks and returns only add stock while an
+// adjustment corrects it either way.
+func ValidateQuantity(kind MovementKind, q int) (Quantity, error) {
+	if q < -100000 || q > 100000 {
+		return 0, errors.New("quantity must be between -100000 and 100000")
+	}
+
+	switch {
+	case kind == MovementAdjustment && q == 0:
+		return 0, errors.New("quantity must not be zero")
+	case kind != MovementAdjustment && q <= 0:
+		return 0, errors.New("quantity must be positive")
+	}
+
+	return Quantity(q), nil
+}
+
+type Reason string
+
+func ValidateReason(r string) (Reason, error) {
+	r = strings.TrimSpace(r)
+	if r == "" || len(r) > 255 {
+		return "", errors.New("reason is required and must be at most 255 characters")
+	}
+
+	return Reason(r), nil
+}
@@ -0,0 +1,48 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuantityValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     MovementKind
+		input    int
+		expected Quantity
+		err      error
+	}{
+		{"restock", MovementRestock, 12, 12, nil},
+		{"return", MovementReturn, 1, 1, nil},
+		{"adjustment up", MovementAdjustment, 3, 3, nil},
+		{"adjustment down", MovementAdjustment, -3, -3, nil},
+		{"zero adjustment", MovementAdjustment, 0, 0, errors.New("quantity must not be zero")},
+		{"negative restock", MovementRestock, -5, 0, errors.New("quantity must be positive")},
+		{"zero return", MovementReturn, 0, 0, errors.New("quantity must be positive")},
+		{"too large", MovementRestock, 100001, 0, errors.New("quantity must be between -100000 and 100000")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ValidateQuantity(tt.kind, tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+)
+
+type Name string
+
+func ValidateName(n string) (Name, error) {
+	n = strings.TrimSpace(n)
+	if n == "" || len(n) > 255 {
+		return "", errors.New("name is required and must be at most 255 characters")
+	}
+
+	return Name(n), nil
+}
+
+type Sku string
+
+func ValidateSku(s string) (Sku, error) {
+	match, _ := regexp.MatchString("^[A-Za-z0-9_-]{1,100}$", s)
+	if !match {
+		return "", errors.New("sku must be 1 to 100 letters, numbers, dashes or underscores")
+	}
+
+	return Sku(s), nil
+}
+
+type Price float64
+
+// Prices are stored as DECIMAL(10, 2)
+const (
+	maxPrice       = 99999999.99
+	maxPriceAmount = 9999999999
+)
+
+func ValidatePrice(p float64) (Price, error) {
+	if p <= 0 || p > maxPrice {
+		return 0, errors.New("price must be greater than 0 and at most 99999999.99")
+	}
+
+	// Reject fractions of a cent instead of silently rounding them away
+	cents := p * 100
+	if math.Abs(cents-math.Round(cents)) > 1e-6 {
+		return 0, errors.New("price must have at most two decimal places")
+	}
+
+	return Price(p), nil
+}
+
+// ValidatePriceAmount applies the range of ValidatePrice to an exact amount in cents, such as a price read from an
+// import, which cannot hold fractions of a cent
+func ValidatePriceAmount(cents int64) (int64, error) {
+	if cents <= 0 || cents > maxPriceAmount {
+		return 0, errors.New("price must be greater than 0 and at most 99999999.99")
+	}
+
+	return cents, nil
+}
+
+type Stock int
+
+func ValidateStock(s int) (Stock, error) {
+	if s < 0 || s > math.MaxInt32 {
+		return 0, errors.New("stock must be a non-negative number")
+	}
+
+	return Stock(s), nil
+}
+
+// ReorderThreshold is the stock at or below which a product or its variants are reported as running low
+type ReorderThreshold int
+
+func ValidateReorderThreshold(t int) (ReorderThreshold, error) {
+	if t < 0 || t > 1000000 {
+		return 0, errors.New("reorder threshold must be between 0 and 1000000")
+	}
+
+	return ReorderThreshold(t), nil
+}
+
+type Sort string
+
+const (
+	SortNewest    Sort = "newest"
+	SortOldest    Sort = "oldest"
+	SortPriceAsc  Sort = "price_asc"
+	SortPriceDesc Sort = "price_desc"
+	SortRating    Sort = "rating"
+)
+
+// ValidateSort defaults to the oldest first order product listings have always used
+func ValidateSort(s string) (Sort, error) {
+	switch Sort(s) {
+	case "":
+		return SortOldest, nil
+	case SortNewest, SortOldest, SortPriceAsc, SortPriceDesc, SortRating:
+		return Sort(s), nil
+	}
+
+	return "", errors.New("sort must be one of newest, oldest, price_asc, price_desc or rating")
+}
+
+type SpecKey string
+
+func ValidateSpecKey(k string) (SpecKey, error) {
+	match, _ := regexp.MatchString("^[A-Za-z0-9_]{1,50}$", k)
+	if !match {
+		return "", errors.New("specification keys must be 1 to 50 letters, numbers or underscores")
+	}
+
+	return SpecKey(k), nil
+}
+
+type Attributes map[string]string
+
+const maxAttributes = 20
+
+// ValidateAttributes accepts a JSON object of text values describing a variant, such as {"color": "Black"}.
+// Keys follow the rules of specification keys so variants can be filtered the same way.
+func ValidateAttributes(raw json.RawMessage) (Attributes, error) {
+	attributes := Attributes{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return attributes, nil
+	}
+
+	if err := json.Unmarshal(raw, &attributes); err != nil {
+		return nil, errors.New("attributes must be an object of text values")
+	}
+	if len(attributes) > maxAttributes {
+		return nil, errors.New("a variant can have at most 20 attributes")
+	}
+
+	for key, value := range attributes {
+		if _, err := ValidateSpecKey(key); err != nil {
+			return nil, errors.New("attribute names must be 1 to 50 letters, numbers or underscores")
+		}
+		if value == "" || len(value) > 100 {
+			return nil, errors.New("attribute values must be 1 to 100 characters")
+		}
+	}
+
+	return attributes, nil
+}
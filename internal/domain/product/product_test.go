@@ -0,0 +1,311 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNameValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Name
+		err      error
+	}{
+		{"Wireless Mouse", "Wireless Mouse", nil},
+		{"  USB-C Hub  ", "USB-C Hub", nil},
+		{"", "", errors.New("name is required and must be at most 255 characters")},
+		{"   ", "", errors.New("name is required and must be at most 255 characters")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateName(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSkuValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Sku
+		err      error
+	}{
+		{"MOUSE-WL-LOGI", "MOUSE-WL-LOGI", nil},
+		{"hub_7in1", "hub_7in1", nil},
+		{"MOUSE WL", "", errors.New("sku must be 1 to 100 letters, numbers, dashes or underscores")},
+		{"MOUSE/WL", "", errors.New("sku must be 1 to 100 letters, numbers, dashes or underscores")},
+		{"", "", errors.New("sku must be 1 to 100 letters, numbers, dashes or underscores")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateSku(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPriceValidation(t *testing.T) {
+	tests := []struct {
+		input    float64
+		expected Price
+		err      error
+	}{
+		{29.99, 29.99, nil},
+		{0.01, 0.01, nil},
+		{99999999.99, 99999999.99, nil},
+		{0, 0, errors.New("price must be greater than 0 and at most 99999999.99")},
+		{-5, 0, errors.New("price must be greater than 0 and at most 99999999.99")},
+		{100000000, 0, errors.New("price must be greater than 0 and at most 99999999.99")},
+		{9.999, 0, errors.New("price must have at most two decimal places")},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.input), func(t *testing.T) {
+			result, err := ValidatePrice(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPriceAmountValidation(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected int64
+		err      error
+	}{
+		{2999, 2999, nil},
+		{1, 1, nil},
+		{9999999999, 9999999999, nil},
+		{0, 0, errors.New("price must be greater than 0 and at most 99999999.99")},
+		{-500, 0, errors.New("price must be greater than 0 and at most 99999999.99")},
+		{10000000000, 0, errors.New("price must be greater than 0 and at most 99999999.99")},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.input), func(t *testing.T) {
+			result, err := ValidatePriceAmount(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestStockValidation(t *testing.T) {
+	tests := []struct {
+		input    int
+		expected Stock
+		err      error
+	}{
+		{0, 0, nil},
+		{150, 150, nil},
+		{-1, 0, errors.New("stock must be a non-negative number")},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.input), func(t *testing.T) {
+			result, err := ValidateStock(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSearchQueryValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SearchQuery
+		err      error
+	}{
+		{"mouse", "mouse:*", nil},
+		{"Wirel Mou", "wirel:* & mou:*", nil},
+		{"usb-c hub!", "usb:* & c:* & hub:*", nil},
+		{"a & b | !c:*", "a:* & b:* & c:*", nil},
+		{"1 2 3 4 5 6 7 8 9 10 11", "1:* & 2:* & 3:* & 4:* & 5:* & 6:* & 7:* & 8:* & 9:* & 10:*", nil},
+		{"", "", errors.New("search query must contain at least one letter or number")},
+		{"&|!():*", "", errors.New("search query must contain at least one letter or number")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateSearchQuery(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSortValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Sort
+		err      error
+	}{
+		{"", SortOldest, nil},
+		{"newest", SortNewest, nil},
+		{"price_asc", SortPriceAsc, nil},
+		{"price_desc", SortPriceDesc, nil},
+		{"rating", SortRating, nil},
+		{"price", "", errors.New("sort must be one of newest, oldest, price_asc, price_desc or rating")},
+		{"NEWEST", "", errors.New("sort must be one of newest, oldest, price_asc, price_desc or rating")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateSort(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAttributesValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Attributes
+		err      error
+	}{
+		{"empty", "", Attributes{}, nil},
+		{"null", "null", Attributes{}, nil},
+		{"valid", `{"color": "Black", "band": "Silicone"}`, Attributes{"color": "Black", "band": "Silicone"}, nil},
+		{"array", `["Black"]`, nil, errors.New("attributes must be an object of text values")},
+		{"number value", `{"size": 42}`, nil, errors.New("attributes must be an object of text values")},
+		{"invalid name", `{"colour name": "Black"}`, nil, errors.New("attribute names must be 1 to 50 letters, numbers or underscores")},
+		{"empty value", `{"color": ""}`, nil, errors.New("attribute values must be 1 to 100 characters")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ValidateAttributes(json.RawMessage(tt.input))
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package product
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+const maxSearchTerms = 10
+
+type SearchQuery string
+
+// ValidateSearchQuery turns free text into a prefix matching tsquery, e.g. "wirel mou" becomes "wirel:* & mou:*".
+// Everything but letters and numbers is dropped so the result is always valid tsquery syntax.
+func ValidateSearchQuery(q string) (SearchQuery, error) {
+	terms := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(terms) == 0 {
+		return "", errors.New("search query must contain at least one letter or number")
+	}
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	return SearchQuery(strings.Join(terms, " & ")), nil
+}
@@ -48,3 +48,20 @@ func ValidatePassword(p string) (Password, error) {
 
 	return Password(p), nil
 }
+
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleStaff    Role = "staff"
+	RoleAdmin    Role = "admin"
+)
+
+func ValidateRole(r string) (Role, error) {
+	switch Role(r) {
+	case RoleCustomer, RoleStaff, RoleAdmin:
+		return Role(r), nil
+	}
+
+	return "", errors.New("role must be one of customer, staff or admin")
+}
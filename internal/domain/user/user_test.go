@@ -120,3 +120,41 @@ func TestPasswordValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Role
+		err      error
+	}{
+		{"customer", RoleCustomer, nil},
+		{"staff", RoleStaff, nil},
+		{"admin", RoleAdmin, nil},
+		{"Admin", "", errors.New("role must be one of customer, staff or admin")},
+		{"superuser", "", errors.New("role must be one of customer, staff or admin")},
+		{"", "", errors.New("role must be one of customer, staff or admin")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateRole(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
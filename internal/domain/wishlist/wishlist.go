@@ -0,0 +1,38 @@
+package wishlist
+
+import (
+	"errors"
+	"strings"
+)
+
+// SavedForLaterName is the name of the list items saved for later from the cart are moved to. Users cannot give
+// their own lists this name.
+const SavedForLaterName = "Saved for later"
+
+type Name string
+
+func ValidateName(n string) (Name, error) {
+	n = strings.TrimSpace(n)
+	if n == "" || len(n) > 100 {
+		return "", errors.New("name is required and must be at most 100 characters")
+	}
+	if strings.EqualFold(n, SavedForLaterName) {
+		return "", errors.New("name is reserved for items saved for later")
+	}
+
+	return Name(n), nil
+}
+
+type Quantity int
+
+// ValidateQuantity checks how many of an item the user wants, 0 meaning the default of one
+func ValidateQuantity(q int) (Quantity, error) {
+	if q == 0 {
+		return 1, nil
+	}
+	if q < 0 || q > 1000 {
+		return 0, errors.New("quantity must be between 1 and 1000")
+	}
+
+	return Quantity(q), nil
+}
@@ -0,0 +1,81 @@
+package wishlist
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNameValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Name
+		err      error
+	}{
+		{"Birthday", "Birthday", nil},
+		{"  Gift ideas ", "Gift ideas", nil},
+		{"", "", errors.New("name is required and must be at most 100 characters")},
+		{"   ", "", errors.New("name is required and must be at most 100 characters")},
+		{strings.Repeat("a", 101), "", errors.New("name is required and must be at most 100 characters")},
+		{"saved for LATER", "", errors.New("name is reserved for items saved for later")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ValidateName(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			// If error is expected, check if it matches
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestQuantityValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    int
+		expected Quantity
+		err      error
+	}{
+		{"default", 0, 1, nil},
+		{"several", 3, 3, nil},
+		{"negative", -1, 0, errors.New("quantity must be between 1 and 1000")},
+		{"too many", 1001, 0, errors.New("quantity must be between 1 and 1000")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ValidateQuantity(tt.input)
+
+			if err != nil && tt.err == nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if err == nil && tt.err != nil {
+				t.Fatalf("expected error %v but got no error", tt.err)
+			}
+
+			if err != nil && err.Error() != tt.err.Error() {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected result %v but got %v", tt.expected, result)
+			}
+		})
+	}
+}
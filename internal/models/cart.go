@@ -1,23 +1,48 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/CP-Payne/ecomstore/internal/domain/cart"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/google/uuid"
 )
 
 type CartItem struct {
-	ProductID uuid.UUID `json:"productId"`
-	Quantity  int       `json:"quantity"`
-	Name      string    `json:"productName"`
-	Price     float32   `json:"price"`
+	ProductID  uuid.UUID       `json:"productId"`
+	VariantID  *uuid.UUID      `json:"variantId,omitempty"`
+	Quantity   int             `json:"quantity"`
+	Name       string          `json:"productName"`
+	Price      money.Money     `json:"price"`
+	Sku        string          `json:"sku,omitempty"`
+	Attributes json.RawMessage `json:"attributes,omitempty"`
+	Warnings   []cart.Warning  `json:"warnings,omitempty"`
 }
 
 type Cart struct {
-	ID        uuid.UUID  `json:"id"`
-	UserID    uuid.UUID  `json:"userId"`
-	Items     []CartItem `json:"items"`
-	Status    string     `json:"status"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt"`
+	ID        uuid.UUID      `json:"id"`
+	UserID    uuid.UUID      `json:"userId"`
+	Items     []CartItem     `json:"items"`
+	Status    string         `json:"status"`
+	Currency  money.Currency `json:"currency"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// HasWarnings reports whether any item changed since it was added and the shopper should review the cart
+func (c Cart) HasWarnings() bool {
+	for _, item := range c.Items {
+		if len(item.Warnings) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CartItemUpdate sets the quantity of an item in a cart, a quantity of 0 removes the item
+type CartItemUpdate struct {
+	ProductID uuid.UUID  `json:"productId"`
+	VariantID *uuid.UUID `json:"variantId"`
+	Quantity  int        `json:"quantity"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import "github.com/CP-Payne/ecomstore/pkg/errsx"
+
+// CatalogImportReport summarises a bulk import. Nothing is written unless Applied is true, which requires every
+// row to be valid and the import not to be a dry run.
+type CatalogImportReport struct {
+	DryRun            bool              `json:"dryRun"`
+	Applied           bool              `json:"applied"`
+	Rows              int               `json:"rows"`
+	Created           int               `json:"created"`
+	Updated           int               `json:"updated"`
+	CategoriesCreated int               `json:"categoriesCreated"`
+	Errors            []CatalogRowError `json:"errors,omitempty"`
+}
+
+// CatalogRowError holds the field errors of one input row, Line is the row's line number in the input
+type CatalogRowError struct {
+	Line   int       `json:"line"`
+	Sku    string    `json:"sku,omitempty"`
+	Errors errsx.Map `json:"errors"`
+}
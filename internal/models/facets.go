@@ -0,0 +1,32 @@
+package models
+
+import "github.com/CP-Payne/ecomstore/pkg/money"
+
+// FacetCount is the number of products sharing a value. Label is set when the value is an ID.
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucket counts products priced from Min up to, but not including, Max. The last bucket has no Max.
+type PriceBucket struct {
+	Min   money.Money  `json:"min"`
+	Max   *money.Money `json:"max"`
+	Count int64        `json:"count"`
+}
+
+type SpecificationFacet struct {
+	Key    string       `json:"key"`
+	Values []FacetCount `json:"values"`
+}
+
+// ProductFacets holds the facet counts of a filtered listing. Each facet ignores the filter on its own field,
+// so the counts show how many products selecting another value would match.
+type ProductFacets struct {
+	Total          int64                `json:"total"`
+	Brands         []FacetCount         `json:"brands"`
+	Categories     []FacetCount         `json:"categories"`
+	Prices         []PriceBucket        `json:"prices"`
+	Specifications []SpecificationFacet `json:"specifications"`
+}
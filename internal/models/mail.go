@@ -0,0 +1,13 @@
+package models
+
+import "context"
+
+type Mailer interface {
+	Send(ctx context.Context, message *EmailMessage) error
+}
+
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -19,27 +20,31 @@ import (
 // }
 
 type Order struct {
-	ID               uuid.UUID   `json:"id"`
-	ProductTotal     float32     `json:"productTotal"`
-	OrderTotal       float32     `json:"orderTotal"`
-	ProcessorOrderID string      `json:"processorOrderId,omitempty"`
-	Status           string      `json:"status,omitempty"`
-	UserID           uuid.UUID   `json:"userId"`
-	OrderItems       []OrderItem `json:"items"`
-	PaymentEmail     string      `json:"paymentEmail,omitempty"`
-	PaymentMethod    string      `json:"paymentMethod"`
-	PayerID          string      `json:"payerId,omitempty"`
-	ShippingPrice    float32     `json:"shippingPrice"`
-	CartID           *uuid.UUID  `json:"cartId,omitempty"`
-	CreatedAt        time.Time   `json:"createdAt"`
-	UpdatedAt        time.Time   `json:"updatedAt"`
+	ID               uuid.UUID      `json:"id"`
+	ProductTotal     money.Money    `json:"productTotal"`
+	OrderTotal       money.Money    `json:"orderTotal"`
+	ProcessorOrderID string         `json:"processorOrderId,omitempty"`
+	Status           string         `json:"status,omitempty"`
+	UserID           uuid.UUID      `json:"userId"`
+	OrderItems       []OrderItem    `json:"items"`
+	PaymentEmail     string         `json:"paymentEmail,omitempty"`
+	PaymentMethod    string         `json:"paymentMethod"`
+	PayerID          string         `json:"payerId,omitempty"`
+	ShippingPrice    money.Money    `json:"shippingPrice"`
+	Currency         money.Currency `json:"currency"`
+	ExchangeRate     string         `json:"exchangeRate,omitempty"`
+	CartID           *uuid.UUID     `json:"cartId,omitempty"`
+	CreatedAt        time.Time      `json:"createdAt"`
+	UpdatedAt        time.Time      `json:"updatedAt"`
 }
 
 // TODO: Need to set PayerID, PaymentEmail, ProcessorOrderID
 
 type OrderItem struct {
-	ProductID uuid.UUID `json:"productId"`
-	Name      string    `json:"name"`
-	Quantity  int       `json:"quantity"`
-	Price     float32   `json:"price"`
+	ProductID uuid.UUID   `json:"productId"`
+	VariantID *uuid.UUID  `json:"variantId,omitempty"`
+	Sku       string      `json:"sku,omitempty"`
+	Name      string      `json:"name"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
 }
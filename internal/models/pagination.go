@@ -0,0 +1,9 @@
+package models
+
+// Page is a single page of a cursor paginated listing. NextCursor is empty on the last page
+// and Total is only set when the client asked for it.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
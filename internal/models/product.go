@@ -2,42 +2,70 @@ package models
 
 import (
 	"encoding/json"
-	"strconv"
+	"html"
+	"strings"
 	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type Product struct {
-	ID             uuid.UUID       `json:"id"`
-	Name           string          `json:"name"`
-	Description    string          `json:"description"`
-	Price          float32         `json:"price"`
-	Brand          string          `json:"brand"`
-	Sku            string          `json:"sku"`
-	Stock          int             `json:"stock"`
-	CategoryID     uuid.UUID       `json:"categoryId"`
-	ImageURL       string          `json:"imageUrl"`
-	ThumbnailURL   string          `json:"thumbnailUrl"`
-	Specifications json.RawMessage `json:"specifications"`
-	Variants       json.RawMessage `json:"variants"`
+	ID             uuid.UUID        `json:"id"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description"`
+	Price          money.Money      `json:"price"`
+	Brand          string           `json:"brand"`
+	Sku            string           `json:"sku"`
+	Stock          int              `json:"stock"`
+	CategoryID     uuid.UUID        `json:"categoryId"`
+	ImageURL       string           `json:"imageUrl"`
+	ThumbnailURL   string           `json:"thumbnailUrl"`
+	Specifications json.RawMessage  `json:"specifications"`
+	Variants       []ProductVariant `json:"variants,omitempty"`
 }
 
 type ProductWithMetadata struct {
 	Product
-	IsActive  bool      `json:"isActive"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ReorderThreshold int       `json:"reorderThreshold"`
+	IsActive         bool      `json:"isActive"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// ProductParams holds the writable fields of a product
+type ProductParams struct {
+	Name             string
+	Description      string
+	Price            money.Money
+	Brand            string
+	Sku              string
+	Stock            int
+	CategoryID       uuid.UUID
+	ImageURL         string
+	ThumbnailURL     string
+	Specifications   json.RawMessage
+	ReorderThreshold int
+}
+
+// ProductFilter narrows a product listing, zero values do not filter. Specs maps specification keys to accepted values.
+type ProductFilter struct {
+	MinPrice   *money.Money
+	MaxPrice   *money.Money
+	Brands     []string
+	InStock    bool
+	CategoryID *uuid.UUID
+	Specs      map[string][]string
 }
 
 // Database Product to product mappings
 func DatabaseProductToProduct(product database.Product, includeMetadata bool) interface{} {
-	price, err := strconv.ParseFloat(product.Price, 32)
+	price, err := money.Parse(product.Price, config.BaseCurrency())
 	if err != nil {
-		config.GetLogger().Error("failed to parse string price to float", zap.Error(err))
+		config.GetLogger().Error("failed to parse product price", zap.Error(err), zap.String("price", product.Price))
 		return Product{}
 	}
 
@@ -45,15 +73,14 @@ func DatabaseProductToProduct(product database.Product, includeMetadata bool) in
 		ID:             product.ID,
 		Name:           product.Name,
 		Description:    NullStringToString(product.Description),
-		Price:          float32(price),
+		Price:          price,
 		Brand:          NullStringToString(product.Brand),
 		Sku:            product.Sku,
 		Stock:          int(product.StockQuantity),
-		CategoryID:     product.ID,
+		CategoryID:     product.CategoryID,
 		ImageURL:       NullStringToString(product.ImageUrl),
 		ThumbnailURL:   NullStringToString(product.ThumbnailUrl),
 		Specifications: NullRawMessageToRawMessage(product.Specifications),
-		Variants:       NullRawMessageToRawMessage(product.Variants),
 		// IsActive:       product.IsActive,
 		// CreatedAt:      product.CreatedAt,
 		// UpdatedAt:      product.UpdatedAt,
@@ -61,10 +88,11 @@ func DatabaseProductToProduct(product database.Product, includeMetadata bool) in
 
 	if includeMetadata {
 		return ProductWithMetadata{
-			Product:   baseProduct,
-			IsActive:  product.IsActive,
-			CreatedAt: product.CreatedAt,
-			UpdatedAt: product.UpdatedAt,
+			Product:          baseProduct,
+			ReorderThreshold: int(product.ReorderThreshold),
+			IsActive:         product.IsActive,
+			CreatedAt:        product.CreatedAt,
+			UpdatedAt:        product.UpdatedAt,
 		}
 	}
 
@@ -96,3 +124,58 @@ func DatabaseProductsToProducts(dbProducts []database.Product, includeMetadata b
 
 	}
 }
+
+// ProductSearchResult is a product matched by a search. NameHighlight and Snippet are HTML, with the product text
+// escaped and matched words wrapped in <mark> tags.
+type ProductSearchResult struct {
+	Product
+	Rank          float32 `json:"rank"`
+	NameHighlight string  `json:"nameHighlight"`
+	Snippet       string  `json:"snippet"`
+}
+
+// ProductSearchPage is a page of search results. Fuzzy is set when nothing matched the query exactly
+// and the results were found by similarity instead.
+type ProductSearchPage struct {
+	Page[ProductSearchResult]
+	Fuzzy bool `json:"fuzzy"`
+}
+
+func DatabaseSearchRowToSearchResult(row database.SearchProductsRow) ProductSearchResult {
+	product := DatabaseProductToProduct(database.Product{
+		ID:             row.ID,
+		Name:           row.Name,
+		Description:    row.Description,
+		Price:          row.Price,
+		Brand:          row.Brand,
+		Sku:            row.Sku,
+		StockQuantity:  row.StockQuantity,
+		CategoryID:     row.CategoryID,
+		ImageUrl:       row.ImageUrl,
+		ThumbnailUrl:   row.ThumbnailUrl,
+		Specifications: row.Specifications,
+		IsActive:       row.IsActive,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+	}, false).(Product)
+
+	return ProductSearchResult{
+		Product:       product,
+		Rank:          row.Rank,
+		NameHighlight: highlightHTML(row.NameHighlight),
+		Snippet:       highlightHTML(row.Snippet),
+	}
+}
+
+// Search queries mark matched words with the STX and ETX control characters rather than tags, so the product
+// text around them can be escaped before the tags are put in
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+var highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+func highlightHTML(s string) string {
+	return highlightTags.Replace(html.EscapeString(s))
+}
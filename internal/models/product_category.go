@@ -6,17 +6,45 @@ import (
 )
 
 type Category struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
+	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parentId"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	Description string     `json:"description"`
+	SortOrder   int        `json:"sortOrder"`
+	Children    []Category `json:"children,omitempty"`
+}
+
+// CategoryParams holds the writable fields of a category
+type CategoryParams struct {
+	ParentID    *uuid.UUID
+	Name        string
+	Slug        string
+	Description string
+	SortOrder   int
+}
+
+// CategoryProductsPage is a page of a category's products. Breadcrumbs lead from the root category down to Category.
+type CategoryProductsPage struct {
+	Page[Product]
+	Category    Category   `json:"category"`
+	Breadcrumbs []Category `json:"breadcrumbs"`
 }
 
 // Database Category to Category mappings
 func DatabaseCategoryToCategory(category database.Category) Category {
+	var parentID *uuid.UUID
+	if category.ParentID.Valid {
+		parentID = &category.ParentID.UUID
+	}
+
 	return Category{
 		ID:          category.ID,
+		ParentID:    parentID,
 		Name:        category.Name,
+		Slug:        category.Slug,
 		Description: NullStringToString(category.Description),
+		SortOrder:   int(category.SortOrder),
 	}
 }
 
@@ -27,3 +55,29 @@ func DatabaseCategoriesToCategories(dbCategories []database.Category) []Category
 	}
 	return categories
 }
+
+// CategoryTree nests categories under their parents, keeping the order of the given list within each level
+func CategoryTree(categories []Category) []Category {
+	children := make(map[uuid.UUID][]Category)
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var attach func(level []Category) []Category
+	attach = func(level []Category) []Category {
+		for i := range level {
+			level[i].Children = attach(children[level[i].ID])
+		}
+		return level
+	}
+
+	if roots == nil {
+		return []Category{}
+	}
+	return attach(roots)
+}
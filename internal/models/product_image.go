@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/google/uuid"
+)
+
+// ProductImage is an uploaded product image. URLs maps each generated size, and the original upload, to its URL.
+type ProductImage struct {
+	ID        uuid.UUID         `json:"id"`
+	ProductID uuid.UUID         `json:"productId"`
+	Position  int               `json:"position"`
+	AltText   string            `json:"altText"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	URLs      map[string]string `json:"urls"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+func DatabaseImageToImage(image database.ProductImage, urls map[string]string) ProductImage {
+	return ProductImage{
+		ID:        image.ID,
+		ProductID: image.ProductID,
+		Position:  int(image.Position),
+		AltText:   NullStringToString(image.AltText),
+		Width:     int(image.Width),
+		Height:    int(image.Height),
+		URLs:      urls,
+		CreatedAt: image.CreatedAt,
+	}
+}
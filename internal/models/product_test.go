@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Wireless \x02Headphones\x03", "Wireless <mark>Headphones</mark>"},
+		{"<script>alert(1)</script> \x02mouse\x03", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>mouse</mark>"},
+		{"Tom & Jerry's \"mug\"", "Tom &amp; Jerry&#39;s &#34;mug&#34;"},
+		{"no match", "no match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := highlightHTML(tt.input); result != tt.expected {
+				t.Fatalf("expected %q but got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"github.com/google/uuid"
+)
+
+// ProductVariant is a purchasable version of a product, such as a colour or size, with its own SKU, price and stock
+type ProductVariant struct {
+	ID         uuid.UUID       `json:"id"`
+	ProductID  uuid.UUID       `json:"productId"`
+	Sku        string          `json:"sku"`
+	Price      money.Money     `json:"price"`
+	Stock      int             `json:"stock"`
+	Attributes json.RawMessage `json:"attributes"`
+	IsActive   bool            `json:"isActive"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// ProductVariantParams holds the writable fields of a variant
+type ProductVariantParams struct {
+	Sku        string
+	Price      money.Money
+	Stock      int
+	Attributes json.RawMessage
+}
+
+func DatabaseVariantToVariant(variant database.ProductVariant) (ProductVariant, error) {
+	price, err := money.Parse(variant.Price, config.BaseCurrency())
+	if err != nil {
+		return ProductVariant{}, err
+	}
+
+	return ProductVariant{
+		ID:         variant.ID,
+		ProductID:  variant.ProductID,
+		Sku:        variant.Sku,
+		Price:      price,
+		Stock:      int(variant.StockQuantity),
+		Attributes: variant.Attributes,
+		IsActive:   variant.IsActive,
+		CreatedAt:  variant.CreatedAt,
+		UpdatedAt:  variant.UpdatedAt,
+	}, nil
+}
+
+func DatabaseVariantsToVariants(dbVariants []database.ProductVariant) ([]ProductVariant, error) {
+	variants := make([]ProductVariant, 0, len(dbVariants))
+	for _, dbVariant := range dbVariants {
+		variant, err := DatabaseVariantToVariant(dbVariant)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
@@ -3,7 +3,9 @@ package models
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 )
 
@@ -20,3 +22,28 @@ func NullRawMessageToRawMessage(rawMessage pqtype.NullRawMessage) json.RawMessag
 	}
 	return rawMessage.RawMessage
 }
+
+func NullTimeToTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func NullUUIDToUUID(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func StringToNullString(str string) sql.NullString {
+	return sql.NullString{String: str, Valid: str != ""}
+}
+
+func RawMessageToNullRawMessage(rawMessage json.RawMessage) pqtype.NullRawMessage {
+	if len(rawMessage) == 0 || string(rawMessage) == "null" {
+		return pqtype.NullRawMessage{}
+	}
+	return pqtype.NullRawMessage{RawMessage: rawMessage, Valid: true}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StockLevelLow = "low"
+	StockLevelOut = "out"
+)
+
+// StockNotifier delivers stock alerts to whoever restocks the store
+type StockNotifier interface {
+	Notify(ctx context.Context, alert StockAlert) error
+}
+
+// StockAlert reports a product or variant that ran low or out of stock. Level is StockLevelLow or StockLevelOut.
+type StockAlert struct {
+	ID               uuid.UUID  `json:"id"`
+	ProductID        uuid.UUID  `json:"productId"`
+	VariantID        *uuid.UUID `json:"variantId,omitempty"`
+	Sku              string     `json:"sku"`
+	Name             string     `json:"name"`
+	Level            string     `json:"level"`
+	Stock            int        `json:"stock"`
+	ReorderThreshold int        `json:"reorderThreshold"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
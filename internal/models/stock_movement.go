@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/google/uuid"
+)
+
+// StockMovement is an entry of the stock ledger. Quantity is signed, negative when stock was taken.
+type StockMovement struct {
+	ID        uuid.UUID  `json:"id"`
+	ProductID uuid.UUID  `json:"productId"`
+	VariantID *uuid.UUID `json:"variantId,omitempty"`
+	Kind      string     `json:"kind"`
+	Quantity  int        `json:"quantity"`
+	OrderID   *uuid.UUID `json:"orderId,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// StockAdjustment is a stock change recorded by hand. Without a variant it applies to the product's own stock.
+type StockAdjustment struct {
+	VariantID *uuid.UUID
+	Kind      string
+	Quantity  int
+	Reason    string
+	CreatedBy uuid.UUID
+}
+
+// StockLevel is the stock of a product or variant after an adjustment
+type StockLevel struct {
+	ProductID uuid.UUID     `json:"productId"`
+	VariantID *uuid.UUID    `json:"variantId,omitempty"`
+	Stock     int           `json:"stock"`
+	Movement  StockMovement `json:"movement"`
+}
+
+func DatabaseMovementToMovement(movement database.StockMovement) StockMovement {
+	return StockMovement{
+		ID:        movement.ID,
+		ProductID: movement.ProductID,
+		VariantID: NullUUIDToUUID(movement.VariantID),
+		Kind:      movement.Kind,
+		Quantity:  int(movement.Quantity),
+		OrderID:   NullUUIDToUUID(movement.OrderID),
+		Reason:    NullStringToString(movement.Reason),
+		CreatedBy: NullUUIDToUUID(movement.CreatedBy),
+		CreatedAt: movement.CreatedAt,
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// BlobStore stores binary objects such as uploaded images under slash separated keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type BlobInfo struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type AuthTokens struct {
+	AccessToken           string    `json:"-"`
+	AccessTokenExpiresAt  time.Time `json:"accessTokenExpiresAt"`
+	RefreshToken          string    `json:"-"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
@@ -8,28 +8,38 @@ import (
 )
 
 type User struct {
-	CreatedAt      time.Time `json:"createdAt,omitempty"`
-	UpdatedAt      time.Time `json:"updatedAt,omitempty"`
-	Email          string    `json:"email"`
-	HashedPassword string    `json:"-"`
-	Name           string    `json:"name"`
-	ID             uuid.UUID `json:"id"`
+	CreatedAt       time.Time  `json:"createdAt,omitempty"`
+	UpdatedAt       time.Time  `json:"updatedAt,omitempty"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+	Email           string     `json:"email"`
+	HashedPassword  string     `json:"-"`
+	Name            string     `json:"name"`
+	Role            string     `json:"role"`
+	ID              uuid.UUID  `json:"id"`
 }
 
 type UserProfile struct {
-	ID    uuid.UUID `json:"id"`
-	Name  string    `json:"name"`
-	Email string    `json:"email"`
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	Role          string    `json:"role"`
 }
 
 // Database User to User mappings
 func DatabaseUserToUser(user database.User) User {
 	return User{
-		ID:             user.ID,
-		Email:          user.Email,
-		Name:           NullStringToString(user.Name),
-		HashedPassword: user.HashedPassword,
-		CreatedAt:      user.CreatedAt,
-		UpdatedAt:      user.UpdatedAt,
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            NullStringToString(user.Name),
+		HashedPassword:  user.HashedPassword,
+		Role:            user.Role,
+		EmailVerifiedAt: NullTimeToTime(user.EmailVerifiedAt),
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 }
+
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"github.com/google/uuid"
+)
+
+type Wishlist struct {
+	ID            uuid.UUID      `json:"id"`
+	Name          string         `json:"name"`
+	SavedForLater bool           `json:"savedForLater"`
+	ShareToken    string         `json:"shareToken,omitempty"`
+	ItemCount     int            `json:"itemCount"`
+	Items         []WishlistItem `json:"items,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+type WishlistItem struct {
+	ProductID  uuid.UUID       `json:"productId"`
+	VariantID  *uuid.UUID      `json:"variantId,omitempty"`
+	Quantity   int             `json:"quantity"`
+	Name       string          `json:"productName"`
+	Price      money.Money     `json:"price"`
+	Sku        string          `json:"sku,omitempty"`
+	Attributes json.RawMessage `json:"attributes,omitempty"`
+	Available  bool            `json:"available"`
+	InStock    bool            `json:"inStock"`
+	AddedAt    time.Time       `json:"addedAt"`
+}
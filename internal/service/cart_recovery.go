@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CartRecoveryService marks carts left idle as abandoned and emails their owners a link that restores the cart.
+// Abandoned carts that are never picked up again are eventually purged, guest carts included.
+type CartRecoveryService struct {
+	logger       *zap.Logger
+	db           *database.Queries
+	sqlDB        *sql.DB
+	carts        *CartService
+	mailer       models.Mailer
+	appURL       string
+	abandonAfter time.Duration
+	purgeAfter   time.Duration
+}
+
+func NewCartRecoveryService(db *database.Queries, sqlDB *sql.DB, carts *CartService, mailer models.Mailer, appURL string, cconf *config.CartConfig) *CartRecoveryService {
+	return &CartRecoveryService{
+		logger:       config.GetLogger(),
+		db:           db,
+		sqlDB:        sqlDB,
+		carts:        carts,
+		mailer:       mailer,
+		appURL:       appURL,
+		abandonAfter: cconf.AbandonAfter,
+		purgeAfter:   cconf.PurgeAfter,
+	}
+}
+
+// Run checks for abandoned carts every interval until the context is cancelled
+func (s *CartRecoveryService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.Check(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Check(ctx)
+		}
+	}
+}
+
+// Check abandons idle carts, emails the owners of abandoned carts that have not been emailed yet, which retries
+// emails that failed to send, and purges carts abandoned for too long
+func (s *CartRecoveryService) Check(ctx context.Context) {
+	logger := s.logger.With(zap.String("method", "Check"))
+	now := time.Now()
+
+	if err := s.abandonIdleCarts(ctx, now); err != nil {
+		logger.Error("failed to abandon idle carts", zap.Error(err))
+	}
+
+	sent, err := s.sendRecoveryEmails(ctx, now)
+	if err != nil {
+		logger.Error("failed to send cart recovery emails", zap.Error(err))
+	}
+	if sent > 0 {
+		logger.Info("cart recovery emails sent", zap.Int("emails", sent))
+	}
+
+	purged, err := s.db.PurgeAbandonedCarts(ctx, now.Add(-s.purgeAfter))
+	if err != nil {
+		logger.Error("failed to purge abandoned carts", zap.Error(err))
+	} else if purged > 0 {
+		logger.Info("abandoned carts purged", zap.Int64("carts", purged))
+	}
+}
+
+func (s *CartRecoveryService) abandonIdleCarts(ctx context.Context, now time.Time) error {
+	abandoned, err := s.db.MarkAbandonedCarts(ctx, now.Add(-s.abandonAfter))
+	if err != nil {
+		return fmt.Errorf("failed to mark abandoned carts: %w", err)
+	}
+
+	recoveries := 0
+	for _, cart := range abandoned {
+		// Guest carts are abandoned too but there is no one to email
+		if !cart.UserID.Valid {
+			continue
+		}
+
+		created, err := s.db.CreateCartRecovery(ctx, database.CreateCartRecoveryParams{
+			ID:          uuid.New(),
+			CartID:      cart.ID,
+			AbandonedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record abandoned cart: %w", err)
+		}
+		recoveries += int(created)
+	}
+
+	if len(abandoned) > 0 {
+		s.logger.Info("idle carts abandoned", zap.Int("carts", len(abandoned)), zap.Int("recoveries", recoveries))
+	}
+	return nil
+}
+
+// sendRecoveryEmails emails a recovery link to the owner of every abandoned cart not emailed yet. Failed emails
+// are left for the next check. It returns the number of emails sent.
+func (s *CartRecoveryService) sendRecoveryEmails(ctx context.Context, now time.Time) (int, error) {
+	pending, err := s.db.GetPendingCartRecoveries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch pending cart recoveries: %w", err)
+	}
+
+	sent := 0
+	for _, recovery := range pending {
+		// Stop between emails on shutdown, unsent ones are retried by the next check
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		logger := s.logger.With(zap.String("recoveryID", recovery.ID.String()), zap.String("cartID", recovery.CartID.String()))
+
+		cart, err := s.carts.withItems(ctx, models.Cart{
+			ID:       recovery.CartID,
+			Currency: s.carts.cartCurrency(recovery.Currency),
+		})
+		if err != nil {
+			logger.Error("failed to load abandoned cart", zap.Error(err))
+			continue
+		}
+
+		greeting := "Hi"
+		if recovery.Name.Valid && recovery.Name.String != "" {
+			greeting = "Hi " + recovery.Name.String
+		}
+
+		var items strings.Builder
+		for _, item := range cart.Items {
+			fmt.Fprintf(&items, "- %d x %s, %s each\n", item.Quantity, item.Name, item.Price)
+		}
+
+		link := fmt.Sprintf("%s/cart/recover?token=%s", s.appURL, s.RecoveryToken(recovery.ID, now.Add(s.purgeAfter)))
+		err = s.mailer.Send(ctx, &models.EmailMessage{
+			To:      recovery.Email,
+			Subject: "You left something in your cart",
+			Body: fmt.Sprintf("%s,\n\nYou left these items in your cart:\n\n%s\nPick up where you left off with the link below:\n\n%s\n",
+				greeting, items.String(), link),
+		})
+		if err != nil {
+			logger.Error("failed to send cart recovery email", zap.Error(err))
+			continue
+		}
+
+		err = s.db.MarkCartRecoveryEmailSent(ctx, database.MarkCartRecoveryEmailSentParams{
+			ID:          recovery.ID,
+			EmailSentAt: sql.NullTime{Time: now, Valid: true},
+		})
+		if err != nil {
+			logger.Error("failed to mark cart recovery email as sent", zap.Error(err))
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// RecoveryToken signs the link in a cart recovery email, which stops working once it expires
+func (s *CartRecoveryService) RecoveryToken(recoveryID uuid.UUID, expiresAt time.Time) string {
+	message := recoveryID.String() + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return message + "." + s.carts.sign([]byte("recovery."+message))
+}
+
+// parseRecoveryToken returns the recovery of a token created by RecoveryToken. It fails with
+// apperrors.ErrInvalidToken when the token is malformed, expired or its signature does not match.
+func (s *CartRecoveryService) parseRecoveryToken(token string) (uuid.UUID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	message := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.carts.sign([]byte("recovery."+message)))) {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	recoveryID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	return recoveryID, nil
+}
+
+// RestoreCart makes the cart of a recovery link active again and returns it. If the user started another cart in
+// the meantime the abandoned cart's items are merged into it. It fails with apperrors.ErrInvalidToken for invalid
+// links and apperrors.ErrNotFound once the cart has been checked out or purged.
+func (s *CartRecoveryService) RestoreCart(ctx context.Context, token string) (models.Cart, error) {
+	recoveryID, err := s.parseRecoveryToken(token)
+	if err != nil {
+		return models.Cart{}, err
+	}
+
+	logger := s.logger.With(
+		zap.String("method", "RestoreCart"),
+		zap.String("recoveryID", recoveryID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.Cart{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	recovery, err := qtx.GetCartRecovery(ctx, recoveryID)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Cart{}, fmt.Errorf("cart recovery %s: %w", recoveryID, apperrors.ErrNotFound)
+		}
+		logger.Error("failed to fetch cart recovery", zap.Error(err))
+		return models.Cart{}, fmt.Errorf("failed to fetch cart recovery: %w", err)
+	}
+
+	if _, err := qtx.GetCartByID(ctx, recovery.CartID); err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Cart{}, fmt.Errorf("cart %s: %w", recovery.CartID, apperrors.ErrNotFound)
+		}
+		logger.Error("failed to fetch abandoned cart", zap.Error(err))
+		return models.Cart{}, fmt.Errorf("failed to fetch abandoned cart: %w", err)
+	}
+
+	// The user's newest cart is the abandoned one unless they started another since
+	cartID, err := s.carts.userCartID(ctx, qtx, recovery.UserID)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	if cartID != recovery.CartID {
+		if _, err := s.carts.mergeItems(ctx, qtx, recovery.CartID, cartID); err != nil {
+			return models.Cart{}, err
+		}
+		if err := qtx.DeleteCart(ctx, recovery.CartID); err != nil {
+			logger.Error("failed to delete abandoned cart", zap.Error(err))
+			return models.Cart{}, fmt.Errorf("failed to delete abandoned cart: %w", err)
+		}
+	}
+
+	// Conversions are tracked by cart, so the recovery follows the items into the cart they were merged into
+	err = qtx.RestoreCartRecovery(ctx, database.RestoreCartRecoveryParams{
+		ID:         recoveryID,
+		CartID:     cartID,
+		RestoredAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		logger.Error("failed to record cart recovery", zap.Error(err))
+		return models.Cart{}, fmt.Errorf("failed to record cart recovery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.Cart{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("abandoned cart restored", zap.String("cartID", cartID.String()))
+	return s.carts.GetCart(ctx, recovery.UserID)
+}
+
+// RecordConversion marks the recoveries of a cart that was just checked out as converted into the order
+func (s *CartRecoveryService) RecordConversion(ctx context.Context, cartID, orderID uuid.UUID) error {
+	converted, err := s.db.ConvertCartRecoveries(ctx, database.ConvertCartRecoveriesParams{
+		CartID:      cartID,
+		OrderID:     uuid.NullUUID{UUID: orderID, Valid: true},
+		ConvertedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("failed to record cart conversion", zap.Error(err), zap.String("cartID", cartID.String()))
+		return fmt.Errorf("failed to record cart conversion: %w", err)
+	}
+
+	if converted > 0 {
+		s.logger.Info("abandoned cart converted", zap.String("cartID", cartID.String()), zap.String("orderID", orderID.String()))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+)
+
+func TestRecoveryToken(t *testing.T) {
+	carts := &CartService{secret: []byte("cart-secret")}
+	recoveries := &CartRecoveryService{carts: carts}
+
+	recoveryID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+	token := recoveries.RecoveryToken(recoveryID, expiresAt)
+	parts := strings.Split(token, ".")
+
+	// A guest cart token for the same ID must not open the recovery, nor the other way round
+	guestToken := carts.GuestCartToken(recoveryID)
+	_, guestSignature, _ := strings.Cut(guestToken, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"round trip", token, nil},
+		{"other recovery id", uuid.New().String() + "." + parts[1] + "." + parts[2], apperrors.ErrInvalidToken},
+		{"extended expiry", parts[0] + "." + "99999999999" + "." + parts[2], apperrors.ErrInvalidToken},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + tamper(parts[2]), apperrors.ErrInvalidToken},
+		{"expired", recoveries.RecoveryToken(recoveryID, time.Now().Add(-time.Minute)), apperrors.ErrInvalidToken},
+		{"guest cart token", guestToken, apperrors.ErrInvalidToken},
+		{"guest cart signature", parts[0] + "." + parts[1] + "." + guestSignature, apperrors.ErrInvalidToken},
+		{"empty", "", apperrors.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := recoveries.parseRecoveryToken(tt.token)
+
+			if err != tt.err {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+			if err == nil && result != recoveryID {
+				t.Fatalf("expected recovery %s but got %s", recoveryID, result)
+			}
+		})
+	}
+
+	// The recovery signature covers the "recovery." prefix, so it cannot pass as a guest cart token either
+	if _, err := carts.ParseGuestCartToken(parts[0] + "." + parts[2]); err != apperrors.ErrInvalidToken {
+		t.Fatalf("expected a recovery token to be rejected as a guest cart token but got %v", err)
+	}
+	if _, err := carts.ParseGuestCartToken(token); err != apperrors.ErrInvalidToken {
+		t.Fatalf("expected a recovery token to be rejected as a guest cart token but got %v", err)
+	}
+}
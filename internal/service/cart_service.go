@@ -2,28 +2,47 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/database"
+	domaincart "github.com/CP-Payne/ecomstore/internal/domain/cart"
 	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	cartStatusActive = "active"
+	// Carts left idle are marked abandoned, see CartRecoveryService, and become active again when used
+	cartStatusAbandoned = "abandoned"
+)
+
 type CartService struct {
-	logger *zap.Logger
-	db     *database.Queries
+	logger     *zap.Logger
+	db         *database.Queries
+	sqlDB      *sql.DB
+	currencies *CurrencyService
+	secret     []byte
 }
 
-func NewCartService(db *database.Queries) *CartService {
+func NewCartService(db *database.Queries, sqlDB *sql.DB, currencies *CurrencyService, cconf *config.CartConfig) *CartService {
 	return &CartService{
-		logger: config.GetLogger(),
-		db:     db,
+		logger:     config.GetLogger(),
+		db:         db,
+		sqlDB:      sqlDB,
+		currencies: currencies,
+		secret:     []byte(cconf.Secret),
 	}
 }
 
@@ -40,7 +59,7 @@ func (s *CartService) GetCart(ctx context.Context, userID uuid.UUID) (models.Car
 		// If ErrNotFound then there is no active cart for user, create new one
 		if errors.Is(err, apperrors.ErrNotFound) {
 			logger.Info("creating new user cart")
-			cart, err = s.createCart(ctx, userID)
+			cart, err = s.createCart(ctx, s.db, &userID)
 			if err != nil {
 				logger.Error("failed to create user cart", zap.Error(err))
 				return models.Cart{}, fmt.Errorf("failed to retrieve user cart: %w", err)
@@ -52,6 +71,95 @@ func (s *CartService) GetCart(ctx context.Context, userID uuid.UUID) (models.Car
 		}
 	}
 
+	return s.withItems(ctx, cart)
+}
+
+// GetGuestCart returns a guest's cart with its items. When cartID is nil or no longer refers to an active guest
+// cart, such as one merged into a user's cart, a new guest cart is created, or when create is false an empty cart
+// that is not saved and has no ID is returned. Viewing a cart does not need a saved one, so browsing guests and
+// crawlers do not leave empty carts behind.
+func (s *CartService) GetGuestCart(ctx context.Context, cartID *uuid.UUID, create bool) (models.Cart, error) {
+	logger := s.logger.With(zap.String("method", "GetGuestCart"))
+
+	newCart := func() (models.Cart, error) {
+		if !create {
+			return models.Cart{
+				Items:    []models.CartItem{},
+				Status:   cartStatusActive,
+				Currency: s.currencies.Base(),
+			}, nil
+		}
+		return s.createCart(ctx, s.db, nil)
+	}
+
+	if cartID == nil {
+		return newCart()
+	}
+
+	cartRecord, err := s.db.GetGuestCart(ctx, *cartID)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("guest cart no longer active", zap.String("cartID", cartID.String()))
+			return newCart()
+		}
+		logger.Error("failed to fetch guest cart", zap.Error(err), zap.String("cartID", cartID.String()))
+		return models.Cart{}, fmt.Errorf("failed to fetch guest cart: %w", err)
+	}
+
+	if err := s.reactivate(ctx, s.db, cartRecord.ID, cartRecord.Status); err != nil {
+		return models.Cart{}, err
+	}
+
+	return s.withItems(ctx, models.Cart{
+		ID:        cartRecord.ID,
+		Status:    cartStatusActive,
+		Currency:  s.cartCurrency(cartRecord.Currency),
+		CreatedAt: cartRecord.CreatedAt,
+	})
+}
+
+// GuestCartToken signs a guest cart's ID so it can be handed to the guest, for example in a cookie, without
+// letting them guess the IDs of other guests' carts
+func (s *CartService) GuestCartToken(cartID uuid.UUID) string {
+	return cartID.String() + "." + s.sign(cartID[:])
+}
+
+// ParseGuestCartToken returns the cart ID of a token created by GuestCartToken. It fails with
+// apperrors.ErrInvalidToken when the token is malformed or its signature does not match.
+func (s *CartService) ParseGuestCartToken(token string) (uuid.UUID, error) {
+	strCartID, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	cartID, err := uuid.Parse(strCartID)
+	if err != nil {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(cartID[:]))) {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	return cartID, nil
+}
+
+// sign returns the signature of a token's message, so tokens handed out by the store can be trusted when they
+// come back
+func (s *CartService) sign(message []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(message)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// withItems loads the cart's items, priced in the cart's currency and with warnings for any that changed since
+// they were added
+func (s *CartService) withItems(ctx context.Context, cart models.Cart) (models.Cart, error) {
+	logger := s.logger.With(
+		zap.String("method", "withItems"),
+		zap.String("cartID", cart.ID.String()),
+	)
+
 	cartWithItems, err := s.db.GetCartWithItems(ctx, cart.ID)
 	if err != nil {
 		logger.Error("failed to retrieve cart items", zap.Error(err), zap.String("cartID", cart.ID.String()))
@@ -61,18 +169,36 @@ func (s *CartService) GetCart(ctx context.Context, userID uuid.UUID) (models.Car
 	itemsInfo := make([]models.CartItem, 0, len(cartWithItems))
 
 	for _, cartItem := range cartWithItems {
-		p, err := strconv.ParseFloat(cartItem.Price, 32)
+		price, err := s.cartPrice(cartItem.Price, cart.Currency)
+		if err != nil {
+			logger.Error("failed to price cart item", zap.Error(err),
+				zap.String("productID", cartItem.ProductID.String()), zap.String("price", cartItem.Price))
+			return cart, fmt.Errorf("failed to price cart items: %w", err)
+		}
+		addedPrice, err := s.cartPrice(cartItem.PriceAtAdd, cart.Currency)
 		if err != nil {
-			logger.Error("failed to convert cart item price (string) to (float)", zap.Error(err),
-				zap.String("productID", cartItem.CartID.String()), zap.String("price", cartItem.Price))
-			return cart, fmt.Errorf("failed to convert item prices to float: %w", err)
+			logger.Error("failed to price cart item", zap.Error(err),
+				zap.String("productID", cartItem.ProductID.String()), zap.String("price", cartItem.PriceAtAdd))
+			return cart, fmt.Errorf("failed to price cart items: %w", err)
+		}
+
+		line := domaincart.Line{
+			Quantity:   int(cartItem.Quantity),
+			AddedPrice: addedPrice,
+			Price:      price,
+			Stock:      int(cartItem.StockQuantity),
+			Available:  cartItem.Available,
 		}
 
 		itemsInfo = append(itemsInfo, models.CartItem{
-			ProductID: cartItem.ProductID,
-			Quantity:  int(cartItem.Quantity),
-			Price:     float32(p),
-			Name:      cartItem.Name,
+			ProductID:  cartItem.ProductID,
+			VariantID:  nullUuidToUuid(cartItem.VariantID),
+			Quantity:   int(cartItem.Quantity),
+			Price:      price,
+			Name:       cartItem.Name,
+			Sku:        sqlNullStringToString(cartItem.VariantSku),
+			Attributes: models.NullRawMessageToRawMessage(cartItem.VariantAttributes),
+			Warnings:   line.Warnings(),
 		})
 	}
 
@@ -80,47 +206,93 @@ func (s *CartService) GetCart(ctx context.Context, userID uuid.UUID) (models.Car
 	return cart, nil
 }
 
-func (s *CartService) createCart(ctx context.Context, userID uuid.UUID) (models.Cart, error) {
-	logger := s.logger.With(
-		zap.String("method", "createCart"),
-		zap.String("userID", userID.String()),
-	)
+// cartPrice converts a price stored in the base currency into the cart's currency
+func (s *CartService) cartPrice(decimal string, currency money.Currency) (money.Money, error) {
+	price, err := money.Parse(decimal, s.currencies.Base())
+	if err != nil {
+		return money.Money{}, err
+	}
+	return s.currencies.Convert(price, currency)
+}
+
+// createCart creates an empty cart for the user, or a guest cart when userID is nil
+func (s *CartService) createCart(ctx context.Context, q *database.Queries, userID *uuid.UUID) (models.Cart, error) {
+	logger := s.logger.With(zap.String("method", "createCart"))
+
 	cart := models.Cart{
-		ID:     uuid.New(),
-		UserID: userID,
-		Items:  []models.CartItem{},
+		ID:       uuid.New(),
+		Items:    []models.CartItem{},
+		Status:   cartStatusActive,
+		Currency: s.currencies.Base(),
+	}
+	if userID != nil {
+		cart.UserID = *userID
+		logger = logger.With(zap.String("userID", userID.String()))
 	}
 
-	err := s.db.CreateCart(ctx, database.CreateCartParams{
+	err := q.CreateCart(ctx, database.CreateCartParams{
 		ID:     cart.ID,
-		UserID: cart.UserID,
+		UserID: uuidToNullUuid(userID),
 	})
 	if err != nil {
-		logger.Error("failed to create new user cart", zap.Error(err))
-		return models.Cart{}, fmt.Errorf("failed to create user cart: %w", err)
+		logger.Error("failed to create new cart", zap.Error(err))
+		return models.Cart{}, fmt.Errorf("failed to create cart: %w", err)
 	}
-	logger.Info("new user cart created", zap.String("cartID", cart.ID.String()))
+	logger.Info("new cart created", zap.String("cartID", cart.ID.String()))
 	return cart, nil
 }
 
-func (s *CartService) CreateTemporaryProductCart(ctx context.Context, userID uuid.UUID, product models.Product, quantity int) models.Cart {
+// CreateTemporaryProductCart holds a single item bought without the user's cart, priced in the chosen currency.
+// An empty currency selects the base currency.
+func (s *CartService) CreateTemporaryProductCart(ctx context.Context, userID uuid.UUID, item models.CartItem, currencyCode string) (models.Cart, error) {
+	currency, err := s.currencies.Currency(currencyCode)
+	if err != nil {
+		return models.Cart{}, err
+	}
+
+	item.Price, err = s.currencies.Convert(item.Price, currency)
+	if err != nil {
+		return models.Cart{}, fmt.Errorf("failed to convert item price: %w", err)
+	}
+
 	cart := models.Cart{
-		ID:     uuid.New(),
-		UserID: userID,
-		Status: "temporary",
-		Items: []models.CartItem{
-			{
-				ProductID: product.ID,
-				Quantity:  quantity,
-				Price:     product.Price,
-				Name:      product.Name,
-			},
-		},
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    "temporary",
+		Items:     []models.CartItem{item},
+		Currency:  currency,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	return cart
+	return cart, nil
+}
+
+// SetCurrency changes the currency a cart is priced and checked out in. It fails with
+// apperrors.ErrUnsupportedCurrency for currencies without an exchange rate.
+func (s *CartService) SetCurrency(ctx context.Context, cartID uuid.UUID, currencyCode string) error {
+	logger := s.logger.With(
+		zap.String("method", "SetCurrency"),
+		zap.String("cartID", cartID.String()),
+	)
+
+	currency, err := s.currencies.Currency(currencyCode)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetCartCurrency(ctx, database.SetCartCurrencyParams{
+		ID:        cartID,
+		Currency:  sql.NullString{String: string(currency), Valid: true},
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("failed to set cart currency", zap.Error(err))
+		return fmt.Errorf("failed to set cart currency: %w", err)
+	}
+
+	logger.Info("cart currency changed", zap.String("currency", string(currency)))
+	return nil
 }
 
 func (s *CartService) DeleteCart(ctx context.Context, cartID uuid.UUID) error {
@@ -139,96 +311,444 @@ func (s *CartService) DeleteCart(ctx context.Context, cartID uuid.UUID) error {
 	return nil
 }
 
-func (s *CartService) AddToCart(ctx context.Context, userID, productID uuid.UUID, quantity int) error {
+func (s *CartService) AddToCart(ctx context.Context, cartID, productID uuid.UUID, variantID *uuid.UUID, quantity int) error {
 
 	logger := s.logger.With(
 		zap.String("method", "AddToCart"),
-		zap.String("userID", userID.String()),
+		zap.String("cartID", cartID.String()),
 	)
 
-	cart, err := s.GetCart(ctx, userID)
-	if err != nil {
-		logger.Error("failed to retrieve user cart", zap.Error(err))
-		return fmt.Errorf("failed to retrieve user cart: %w", err)
-	}
-
 	// Add item to cart
-	err = s.db.AddItemToCart(ctx, database.AddItemToCartParams{
+	err := s.db.AddItemToCart(ctx, database.AddItemToCartParams{
 		ID:        uuid.New(),
-		CartID:    cart.ID,
+		CartID:    cartID,
 		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
 		Quantity:  int32(quantity),
 	})
 	if err != nil {
-		logger.Error("failed to add item to cart", zap.Error(err), zap.String("cartID", cart.ID.String()))
+		logger.Error("failed to add item to cart", zap.Error(err))
 		return fmt.Errorf("failed to add item to cart: %w", err)
 	}
 
-	logger.Info("successfully added item to cart",
-		zap.String("cartID", cart.ID.String()),
-		zap.String("productID", productID.String()))
+	logger.Info("successfully added item to cart", zap.String("productID", productID.String()))
 	return nil
 }
 
-func (s *CartService) ReduceFromCart(ctx context.Context, userID, productID uuid.UUID, quantity int) error {
+func (s *CartService) ReduceFromCart(ctx context.Context, cartID, productID uuid.UUID, variantID *uuid.UUID, quantity int) error {
 
 	logger := s.logger.With(
 		zap.String("method", "ReduceFromCart"),
-		zap.String("userID", userID.String()),
+		zap.String("cartID", cartID.String()),
 		zap.String("productID", productID.String()),
 		zap.Int("quantity", quantity),
 	)
 
-	cart, err := s.GetCart(ctx, userID)
-	if err != nil {
-		logger.Error("failed to retrieve user cart", zap.Error(err))
-		return fmt.Errorf("failed to retrieve user cart: %w", err)
-	}
-
 	// Reduce item from cart
-	err = s.db.ReduceItemFromCart(ctx, database.ReduceItemFromCartParams{
-		CartID:    cart.ID,
+	err := s.db.ReduceItemFromCart(ctx, database.ReduceItemFromCartParams{
+		CartID:    cartID,
 		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
 		Quantity:  int32(quantity),
 	})
 	if err == nil {
 		// If item was succesfully reduced with quantity still being greater than 0
-		logger.Info("succesfully reduced item in cart", zap.String("cartID", cart.ID.String()))
+		logger.Info("succesfully reduced item in cart")
 		return nil
 	}
 
 	if apperrors.IsCheckViolation(err) {
 		// Reducing quantity resulted in violation (quantity < 1), remove the produt from cart
 		err = s.db.RemoveItemFromCart(ctx, database.RemoveItemFromCartParams{
-			CartID:    cart.ID,
+			CartID:    cartID,
 			ProductID: productID,
+			VariantID: uuidToNullUuid(variantID),
 		})
 		if err != nil {
-			logger.Error("failed to remove product from cart", zap.Error(err), zap.String("cartID", cart.ID.String()))
+			logger.Error("failed to remove product from cart", zap.Error(err))
 			return fmt.Errorf("failed to remove item from cart: %w", err)
 		}
-		logger.Info("succesfully removed product from cart (quantity < 1)", zap.String("cartID", cart.ID.String()))
+		logger.Info("succesfully removed product from cart (quantity < 1)")
 		return nil
 	}
-	logger.Error("failed to reduce cart item quantity", zap.Error(err), zap.String("cartID", cart.ID.String()))
+	logger.Error("failed to reduce cart item quantity", zap.Error(err))
 	return fmt.Errorf("failed to reduce cart item quantity: %w", err)
 }
 
-func (s *CartService) RemoveFromCart(ctx context.Context, userID, productID uuid.UUID) error {
+func (s *CartService) RemoveFromCart(ctx context.Context, cartID, productID uuid.UUID, variantID *uuid.UUID) error {
+	err := s.db.RemoveItemFromCart(ctx, database.RemoveItemFromCartParams{
+		CartID:    cartID,
+		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
+	})
+	if err != nil {
+		s.logger.Error("failed to remove item from cart", zap.Error(err), zap.String("cartID", cartID.String()))
+		return fmt.Errorf("failed to remove item from cart: %w", err)
+	}
+	return nil
+}
+
+// CartItemError is the reason an update of UpdateItems could not be applied. Index is the update's position in
+// the batch.
+type CartItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *CartItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index+1, e.Err)
+}
+
+func (e *CartItemError) Unwrap() error {
+	return e.Err
+}
+
+// UpdateItems sets the quantities of items in a cart in a single transaction, so either every update is applied
+// or none are. Items set to a quantity of 0 are removed. Each item is checked against its current stock, which
+// stays locked until the updates are applied. Items that cannot be bought in the quantity asked for fail with a
+// *CartItemError wrapping the errors of ProductService.GetPurchasableItem.
+func (s *CartService) UpdateItems(ctx context.Context, cartID uuid.UUID, updates []models.CartItemUpdate) error {
 	logger := s.logger.With(
-		zap.String("method", "RemoveFromCart"),
-		zap.String("userID", userID.String()),
-		zap.String("productID", productID.String()),
+		zap.String("method", "UpdateItems"),
+		zap.String("cartID", cartID.String()),
 	)
-	cart, err := s.getActiveCart(ctx, userID)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
 	if err != nil {
-		logger.Error("failed to retrieve user cart", zap.Error(err))
-		return fmt.Errorf("failed to retrieve user cart: %w", err)
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return s.db.RemoveItemFromCart(ctx, database.RemoveItemFromCartParams{
-		CartID:    cart.ID,
-		ProductID: productID,
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	// Stock is locked in the same order as when reserving it for orders, so the two cannot deadlock
+	order := make([]int, len(updates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return cartUpdateKey(updates[order[a]]) < cartUpdateKey(updates[order[b]])
 	})
+
+	for _, i := range order {
+		update := updates[i]
+		if update.Quantity == 0 {
+			continue
+		}
+		if err := s.checkItemStock(ctx, qtx, update); err != nil {
+			if isPurchaseError(err) {
+				return &CartItemError{Index: i, Err: err}
+			}
+			logger.Error("failed to check cart item stock", zap.Error(err), zap.String("productID", update.ProductID.String()))
+			return err
+		}
+	}
+
+	for _, update := range updates {
+		if update.Quantity == 0 {
+			err = qtx.RemoveItemFromCart(ctx, database.RemoveItemFromCartParams{
+				CartID:    cartID,
+				ProductID: update.ProductID,
+				VariantID: uuidToNullUuid(update.VariantID),
+			})
+		} else {
+			err = qtx.SetCartItemQuantity(ctx, database.SetCartItemQuantityParams{
+				ID:        uuid.New(),
+				CartID:    cartID,
+				ProductID: update.ProductID,
+				VariantID: uuidToNullUuid(update.VariantID),
+				Quantity:  int32(update.Quantity),
+			})
+		}
+		if err != nil {
+			logger.Error("failed to update cart item", zap.Error(err), zap.String("productID", update.ProductID.String()))
+			return fmt.Errorf("failed to update cart item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("cart items updated", zap.Int("items", len(updates)))
+	return nil
+}
+
+// isPurchaseError reports whether an item cannot be bought, rather than failed to be checked
+func isPurchaseError(err error) bool {
+	return errors.Is(err, apperrors.ErrNotFound) || errors.Is(err, apperrors.ErrInvalidRef) ||
+		errors.Is(err, apperrors.ErrVariantNeeded) || errors.Is(err, apperrors.ErrOutOfStock)
+}
+
+func cartUpdateKey(update models.CartItemUpdate) string {
+	return reservationKey(models.CartItem{ProductID: update.ProductID, VariantID: update.VariantID})
+}
+
+// checkItemStock locks the stock of an updated item and checks that the product, or its variant, can be bought
+// in the quantity asked for, failing like ProductService.GetPurchasableItem
+func (s *CartService) checkItemStock(ctx context.Context, qtx *database.Queries, update models.CartItemUpdate) error {
+	if _, err := qtx.GetProduct(ctx, update.ProductID); err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("product %s: %w", update.ProductID, apperrors.ErrNotFound)
+		}
+		return fmt.Errorf("failed to retrieve product: %w", err)
+	}
+
+	var stock int32
+	if update.VariantID == nil {
+		hasVariants, err := qtx.ProductHasVariants(ctx, update.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to check product variants: %w", err)
+		}
+		if hasVariants {
+			return apperrors.ErrVariantNeeded
+		}
+
+		stock, err = qtx.LockProductStock(ctx, update.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to lock product stock: %w", err)
+		}
+	} else {
+		variant, err := qtx.GetProductVariant(ctx, database.GetProductVariantParams{
+			ID:        *update.VariantID,
+			ProductID: update.ProductID,
+		})
+		if err != nil {
+			if apperrors.IsNoRowsError(err) {
+				return fmt.Errorf("variant %s of product %s: %w", update.VariantID, update.ProductID, apperrors.ErrInvalidRef)
+			}
+			return fmt.Errorf("failed to retrieve product variant: %w", err)
+		}
+		if !variant.IsActive {
+			return fmt.Errorf("variant %s is archived: %w", update.VariantID, apperrors.ErrInvalidRef)
+		}
+
+		stock, err = qtx.LockVariantStock(ctx, *update.VariantID)
+		if err != nil {
+			return fmt.Errorf("failed to lock variant stock: %w", err)
+		}
+	}
+
+	if update.Quantity > int(stock) {
+		return apperrors.ErrOutOfStock
+	}
+	return nil
+}
+
+// AdjustCart resolves every cart warning in one transaction: unavailable items are removed, quantities are lowered
+// to the stock available and the prices items were added at are brought up to date.
+func (s *CartService) AdjustCart(ctx context.Context, cartID uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "AdjustCart"),
+		zap.String("cartID", cartID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	items, err := qtx.GetCartItemsWithStock(ctx, cartID)
+	if err != nil {
+		logger.Error("failed to fetch cart items", zap.Error(err))
+		return fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+
+	removed := 0
+	for _, item := range items {
+		line := domaincart.Line{
+			Quantity:  int(item.Quantity),
+			Stock:     int(item.StockQuantity),
+			Available: item.Available,
+		}
+
+		// Adjusting the item also stores the current price
+		quantity := line.Adjusted()
+		if quantity == 0 {
+			err = qtx.RemoveItemFromCart(ctx, database.RemoveItemFromCartParams{
+				CartID:    cartID,
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+			})
+			removed++
+		} else {
+			err = qtx.AdjustCartItem(ctx, database.AdjustCartItemParams{
+				CartID:    cartID,
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Quantity:  int32(quantity),
+			})
+		}
+		if err != nil {
+			logger.Error("failed to adjust cart item", zap.Error(err), zap.String("productID", item.ProductID.String()))
+			return fmt.Errorf("failed to adjust cart item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("cart adjusted", zap.Int("removed", removed))
+	return nil
+}
+
+// MergeGuestCart moves the items of a guest's cart into the user's active cart and deletes the guest cart. The
+// quantities of items in both carts are summed but capped at the stock available, and items that can no longer
+// be bought are dropped.
+func (s *CartService) MergeGuestCart(ctx context.Context, guestCartID, userID uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "MergeGuestCart"),
+		zap.String("guestCartID", guestCartID.String()),
+		zap.String("userID", userID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	if _, err := qtx.GetGuestCart(ctx, guestCartID); err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("guest cart %s: %w", guestCartID, apperrors.ErrNotFound)
+		}
+		logger.Error("failed to fetch guest cart", zap.Error(err))
+		return fmt.Errorf("failed to fetch guest cart: %w", err)
+	}
+
+	userCartID, err := s.userCartID(ctx, qtx, userID)
+	if err != nil {
+		return err
+	}
+
+	merged, err := s.mergeItems(ctx, qtx, guestCartID, userCartID)
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteCart(ctx, guestCartID); err != nil {
+		logger.Error("failed to delete guest cart", zap.Error(err))
+		return fmt.Errorf("failed to delete guest cart: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("guest cart merged", zap.String("cartID", userCartID.String()), zap.Int("items", merged))
+	return nil
+}
+
+// userCartID returns the ID of the user's current cart, reactivating it if it was abandoned, or creates one
+func (s *CartService) userCartID(ctx context.Context, q *database.Queries, userID uuid.UUID) (uuid.UUID, error) {
+	cartRecord, err := q.GetActiveCart(ctx, userID)
+	switch {
+	case err == nil:
+		if err := s.reactivate(ctx, q, cartRecord.ID, cartRecord.Status); err != nil {
+			return uuid.Nil, err
+		}
+		return cartRecord.ID, nil
+	case apperrors.IsNoRowsError(err):
+		created, err := s.createCart(ctx, q, &userID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return created.ID, nil
+	default:
+		s.logger.Error("failed to fetch user cart", zap.Error(err), zap.String("userID", userID.String()))
+		return uuid.Nil, fmt.Errorf("failed to fetch user cart: %w", err)
+	}
+}
+
+// mergeItems adds the items of one cart to another. The quantities of items in both carts are summed but capped at
+// the stock available, and items that can no longer be bought are dropped. It returns the number of items merged.
+func (s *CartService) mergeItems(ctx context.Context, q *database.Queries, fromCartID, intoCartID uuid.UUID) (int, error) {
+	logger := s.logger.With(
+		zap.String("method", "mergeItems"),
+		zap.String("fromCartID", fromCartID.String()),
+		zap.String("intoCartID", intoCartID.String()),
+	)
+
+	fromItems, err := q.GetCartItemsWithStock(ctx, fromCartID)
+	if err != nil {
+		logger.Error("failed to fetch cart items", zap.Error(err))
+		return 0, fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+
+	intoItems, err := q.GetCartItems(ctx, intoCartID)
+	if err != nil {
+		logger.Error("failed to fetch cart items", zap.Error(err))
+		return 0, fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+	existing := make(map[cartLine]int32, len(intoItems))
+	for _, item := range intoItems {
+		existing[cartLine{productID: item.ProductID, variantID: item.VariantID}] = item.Quantity
+	}
+
+	merged := 0
+	for _, item := range fromItems {
+		if !item.Available {
+			logger.Info("dropping unavailable cart item", zap.String("productID", item.ProductID.String()))
+			continue
+		}
+
+		current := existing[cartLine{productID: item.ProductID, variantID: item.VariantID}]
+		quantity := min(current+item.Quantity, item.StockQuantity)
+		if quantity <= current {
+			continue
+		}
+
+		err := q.SetCartItemQuantity(ctx, database.SetCartItemQuantityParams{
+			ID:        uuid.New(),
+			CartID:    intoCartID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  quantity,
+		})
+		if err != nil {
+			logger.Error("failed to merge cart item", zap.Error(err), zap.String("productID", item.ProductID.String()))
+			return 0, fmt.Errorf("failed to merge cart item: %w", err)
+		}
+		merged++
+	}
+
+	return merged, nil
+}
+
+// reactivate makes an abandoned cart active again once its owner comes back to it
+func (s *CartService) reactivate(ctx context.Context, q *database.Queries, cartID uuid.UUID, status string) error {
+	if status != cartStatusAbandoned {
+		return nil
+	}
+
+	if err := q.ReactivateCart(ctx, database.ReactivateCartParams{ID: cartID, UpdatedAt: time.Now()}); err != nil {
+		s.logger.Error("failed to reactivate cart", zap.Error(err), zap.String("cartID", cartID.String()))
+		return fmt.Errorf("failed to reactivate cart: %w", err)
+	}
+
+	s.logger.Info("abandoned cart reactivated", zap.String("cartID", cartID.String()))
+	return nil
 }
 
 func (s *CartService) getActiveCart(ctx context.Context, userID uuid.UUID) (models.Cart, error) {
@@ -248,10 +768,15 @@ func (s *CartService) getActiveCart(ctx context.Context, userID uuid.UUID) (mode
 		return models.Cart{}, fmt.Errorf("failed to fetch user cart: %w", err)
 	}
 
+	if err := s.reactivate(ctx, s.db, cartRecord.ID, cartRecord.Status); err != nil {
+		return models.Cart{}, err
+	}
+
 	cart := models.Cart{
-		ID:     cartRecord.ID,
-		UserID: userID,
-		Status: cartRecord.Status,
+		ID:       cartRecord.ID,
+		UserID:   userID,
+		Status:   cartStatusActive,
+		Currency: s.cartCurrency(cartRecord.Currency),
 	}
 
 	// Fetch cart items
@@ -265,9 +790,25 @@ func (s *CartService) getActiveCart(ctx context.Context, userID uuid.UUID) (mode
 	for _, item := range cartItems {
 		cart.Items = append(cart.Items, models.CartItem{
 			ProductID: item.ProductID,
+			VariantID: nullUuidToUuid(item.VariantID),
 			Quantity:  int(item.Quantity),
 		})
 	}
 
 	return cart, nil
 }
+
+// cartCurrency falls back to the base currency for carts that never chose one, or whose currency is no longer
+// offered
+func (s *CartService) cartCurrency(stored sql.NullString) money.Currency {
+	if !stored.Valid {
+		return s.currencies.Base()
+	}
+
+	currency, err := s.currencies.Currency(stored.String)
+	if err != nil {
+		s.logger.Warn("cart currency is no longer supported, using the base currency", zap.String("currency", stored.String))
+		return s.currencies.Base()
+	}
+	return currency
+}
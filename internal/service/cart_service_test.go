@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+)
+
+func TestGuestCartToken(t *testing.T) {
+	carts := &CartService{secret: []byte("cart-secret")}
+	cartID := uuid.New()
+	token := carts.GuestCartToken(cartID)
+	id, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"round trip", token, nil},
+		{"other cart id", uuid.New().String() + "." + signature, apperrors.ErrInvalidToken},
+		{"tampered signature", id + "." + tamper(signature), apperrors.ErrInvalidToken},
+		{"other secret", (&CartService{secret: []byte("other-secret")}).GuestCartToken(cartID), apperrors.ErrInvalidToken},
+		{"missing signature", id, apperrors.ErrInvalidToken},
+		{"malformed id", "not-a-uuid." + signature, apperrors.ErrInvalidToken},
+		{"empty", "", apperrors.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := carts.ParseGuestCartToken(tt.token)
+
+			if err != tt.err {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+			if err == nil && result != cartID {
+				t.Fatalf("expected cart %s but got %s", cartID, result)
+			}
+		})
+	}
+}
+
+func TestCartItemError(t *testing.T) {
+	var err error = &CartItemError{Index: 2, Err: fmt.Errorf("variant archived: %w", apperrors.ErrInvalidRef)}
+	wrapped := fmt.Errorf("failed to update cart: %w", err)
+
+	var itemErr *CartItemError
+	if !errors.As(wrapped, &itemErr) || itemErr.Index != 2 {
+		t.Fatalf("expected the item error of item 3 but got %v", wrapped)
+	}
+	if !errors.Is(wrapped, apperrors.ErrInvalidRef) {
+		t.Fatalf("expected the item error to wrap %v", apperrors.ErrInvalidRef)
+	}
+	if err.Error() != "item 3: variant archived: "+apperrors.ErrInvalidRef.Error() {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
+
+// tamper changes the first character of a token part
+func tamper(s string) string {
+	if strings.HasPrefix(s, "A") {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
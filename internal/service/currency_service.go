@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"go.uber.org/zap"
+)
+
+// CurrencyService converts prices, which are stored in the base currency, into the currencies customers can pay
+// in. Exchange rates are loaded once at startup.
+type CurrencyService struct {
+	logger *zap.Logger
+	base   money.Currency
+	rates  map[money.Currency]*big.Rat
+}
+
+func NewCurrencyService(cconf *config.CurrencyConfig) (*CurrencyService, error) {
+	s := &CurrencyService{
+		logger: config.GetLogger(),
+		base:   cconf.Base,
+		rates:  map[money.Currency]*big.Rat{cconf.Base: big.NewRat(1, 1)},
+	}
+
+	if cconf.RatesFile == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(cconf.RatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	// Rates may be written as numbers or strings, both are read exactly
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange rates: %w", err)
+	}
+
+	for code, value := range raw {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate currency %q: %w", code, err)
+		}
+
+		rate, ok := new(big.Rat).SetString(fmt.Sprint(value))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("exchange rate of %s must be a positive number", currency)
+		}
+		if currency == s.base && rate.Cmp(big.NewRat(1, 1)) != 0 {
+			return nil, fmt.Errorf("exchange rate of the base currency %s must be 1", currency)
+		}
+
+		s.rates[currency] = rate
+	}
+
+	s.logger.Info("exchange rates loaded", zap.String("base", string(s.base)), zap.Int("currencies", len(s.rates)))
+	return s, nil
+}
+
+func (s *CurrencyService) Base() money.Currency {
+	return s.base
+}
+
+// Currencies lists the currencies customers can pay in, the base currency first
+func (s *CurrencyService) Currencies() []money.Currency {
+	currencies := make([]money.Currency, 0, len(s.rates))
+	for currency := range s.rates {
+		if currency != s.base {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	return append([]money.Currency{s.base}, currencies...)
+}
+
+// Currency validates a currency chosen by a customer, an empty code selects the base currency. It fails with
+// apperrors.ErrUnsupportedCurrency for currencies without an exchange rate.
+func (s *CurrencyService) Currency(code string) (money.Currency, error) {
+	if code == "" {
+		return s.base, nil
+	}
+
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", apperrors.ErrUnsupportedCurrency, err)
+	}
+	if _, ok := s.rates[currency]; !ok {
+		return "", fmt.Errorf("%s: %w", currency, apperrors.ErrUnsupportedCurrency)
+	}
+
+	return currency, nil
+}
+
+// Rate returns how many units of the currency one unit of the base currency buys
+func (s *CurrencyService) Rate(currency money.Currency) (*big.Rat, error) {
+	rate, ok := s.rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", currency, apperrors.ErrUnsupportedCurrency)
+	}
+
+	return rate, nil
+}
+
+// Convert prices an amount in another currency, rounding to the nearest minor unit
+func (s *CurrencyService) Convert(m money.Money, to money.Currency) (money.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	from, err := s.Rate(m.Currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+	rate, err := s.Rate(to)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return m.Convert(new(big.Rat).Quo(rate, from), to), nil
+}
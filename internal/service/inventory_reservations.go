@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/inventory"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	reservationReleased  = "released"
+	reservationConverted = "converted"
+)
+
+// reserveStock holds the items' stock for the order until expiresAt. Available stock is the stock quantity minus
+// the quantities of unexpired active reservations; the product or variant row is locked while it is computed so
+// concurrent checkouts cannot both take the last unit. Rows are locked in a fixed order to avoid deadlocks.
+func (s *OrderService) reserveStock(ctx context.Context, qtx *database.Queries, orderID uuid.UUID, items []models.CartItem, expiresAt time.Time) error {
+	now := time.Now()
+
+	sorted := make([]models.CartItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return reservationKey(sorted[i]) < reservationKey(sorted[j])
+	})
+
+	for _, item := range sorted {
+		var stock int32
+		var err error
+		if item.VariantID != nil {
+			stock, err = qtx.LockVariantStock(ctx, *item.VariantID)
+		} else {
+			stock, err = qtx.LockProductStock(ctx, item.ProductID)
+		}
+		if err != nil {
+			if apperrors.IsNoRowsError(err) {
+				return fmt.Errorf("failed to reserve product %s: %w", item.ProductID, apperrors.ErrNotFound)
+			}
+			return fmt.Errorf("failed to lock stock of product %s: %w", item.ProductID, err)
+		}
+
+		reserved, err := qtx.GetReservedQuantity(ctx, database.GetReservedQuantityParams{
+			ProductID: item.ProductID,
+			VariantID: uuidToNullUuid(item.VariantID),
+			ExpiresAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve reserved stock of product %s: %w", item.ProductID, err)
+		}
+
+		if int(stock-reserved) < item.Quantity {
+			return fmt.Errorf("failed to reserve product %s: %w", item.ProductID, apperrors.ErrOutOfStock)
+		}
+
+		err = qtx.CreateReservation(ctx, database.CreateReservationParams{
+			ID:        uuid.New(),
+			OrderID:   orderID,
+			ProductID: item.ProductID,
+			VariantID: uuidToNullUuid(item.VariantID),
+			Quantity:  int32(item.Quantity),
+			ExpiresAt: expiresAt,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reserve product %s: %w", item.ProductID, err)
+		}
+
+		err = recordStockMovement(ctx, qtx, database.CreateStockMovementParams{
+			ProductID: item.ProductID,
+			VariantID: uuidToNullUuid(item.VariantID),
+			Kind:      string(inventory.MovementReservation),
+			Quantity:  -int32(item.Quantity),
+			OrderID:   uuid.NullUUID{UUID: orderID, Valid: true},
+			Reason:    models.StringToNullString("order created"),
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func reservationKey(item models.CartItem) string {
+	if item.VariantID != nil {
+		return item.ProductID.String() + item.VariantID.String()
+	}
+	return item.ProductID.String()
+}
+
+// CheckReservation reports whether the order's stock is still reserved. Payment must not be captured for an
+// order whose reservation expired, as its stock may have been sold to someone else in the meantime.
+func (s *OrderService) CheckReservation(ctx context.Context, orderID uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "CheckReservation"),
+		zap.String("orderID", orderID.String()),
+	)
+
+	held, err := s.db.OrderReservationsHeld(ctx, database.OrderReservationsHeldParams{
+		OrderID:   orderID,
+		ExpiresAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("failed to check order reservation", zap.Error(err))
+		return fmt.Errorf("failed to check order reservation: %w", err)
+	}
+	if !held {
+		return fmt.Errorf("failed to check order reservation: %w", apperrors.ErrReservationExpired)
+	}
+
+	return nil
+}
+
+// ConvertReservation permanently takes a paid order's items from stock and marks its reservations as converted.
+// Should the reservation have been released after it was checked, the stock is taken from the order items all
+// the same since the payment has already been captured.
+func (s *OrderService) ConvertReservation(ctx context.Context, order models.Order) error {
+	logger := s.logger.With(
+		zap.String("method", "ConvertReservation"),
+		zap.String("orderID", order.ID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	reservations, err := qtx.GetActiveOrderReservations(ctx, order.ID)
+	if err != nil {
+		logger.Error("failed to retrieve order reservations", zap.Error(err))
+		return fmt.Errorf("failed to retrieve order reservations: %w", err)
+	}
+
+	items := make([]models.CartItem, 0, len(reservations))
+	for _, reservation := range reservations {
+		items = append(items, models.CartItem{
+			ProductID: reservation.ProductID,
+			VariantID: nullUuidToUuid(reservation.VariantID),
+			Quantity:  int(reservation.Quantity),
+		})
+	}
+	if len(reservations) == 0 {
+		logger.Warn("order reservation was released before capture, taking stock from order items")
+		for _, item := range order.OrderItems {
+			items = append(items, models.CartItem{
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Quantity:  item.Quantity,
+			})
+		}
+	}
+
+	now := time.Now()
+
+	// Items bought as a variant only take from the variant's stock
+	for _, item := range items {
+		var taken int64
+		if item.VariantID != nil {
+			taken, err = qtx.UpdateVariantStock(ctx, database.UpdateVariantStockParams{
+				ID:            *item.VariantID,
+				StockQuantity: int32(item.Quantity),
+			})
+		} else {
+			taken, err = qtx.UpdateStock(ctx, database.UpdateStockParams{
+				ID:            item.ProductID,
+				StockQuantity: int32(item.Quantity),
+			})
+		}
+		if err != nil {
+			logger.Error("failed to update stock", zap.Error(err), zap.String("productID", item.ProductID.String()))
+			return fmt.Errorf("failed to update stock of product %s: %w", item.ProductID, err)
+		}
+		if taken == 0 {
+			// Only possible when stock was lowered by hand while the order was reserved
+			logger.Error("paid order exceeds remaining stock", zap.String("productID", item.ProductID.String()), zap.Int("quantity", item.Quantity))
+			continue
+		}
+
+		err = recordStockMovement(ctx, qtx, database.CreateStockMovementParams{
+			ProductID: item.ProductID,
+			VariantID: uuidToNullUuid(item.VariantID),
+			Kind:      string(inventory.MovementSale),
+			Quantity:  -int32(item.Quantity),
+			OrderID:   uuid.NullUUID{UUID: order.ID, Valid: true},
+			CreatedAt: now,
+		})
+		if err != nil {
+			logger.Error("failed to record sale", zap.Error(err))
+			return err
+		}
+	}
+
+	// Converting returns the reserved quantities to the ledger, which the sales above took for good
+	_, err = qtx.SetOrderReservationsStatus(ctx, database.SetOrderReservationsStatusParams{
+		OrderID:   order.ID,
+		Status:    reservationConverted,
+		UpdatedAt: now,
+		Reason:    models.StringToNullString("order paid"),
+	})
+	if err != nil {
+		logger.Error("failed to convert order reservations", zap.Error(err))
+		return fmt.Errorf("failed to convert order reservations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, item := range items {
+		s.alerts.queueCheck(item.ProductID, item.VariantID)
+	}
+
+	logger.Info("order reservation converted")
+	return nil
+}
+
+// CancelOrder cancels an unpaid order and releases its reservation. Like capture, it is addressed by the payment
+// processor's order ID the buyer is redirected back with.
+func (s *OrderService) CancelOrder(ctx context.Context, processorOrderID string) error {
+	logger := s.logger.With(
+		zap.String("method", "CancelOrder"),
+		zap.String("processorOrderID", processorOrderID),
+	)
+
+	order, err := s.db.GetOrderByProcessorOrderID(ctx, sql.NullString{
+		Valid:  true,
+		String: processorOrderID,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("failed to cancel order: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve order", zap.Error(err))
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	now := time.Now()
+
+	cancelled, err := qtx.CancelOrder(ctx, database.CancelOrderParams{
+		ID:        order.ID,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to cancel order", zap.Error(err))
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if cancelled == 0 {
+		logger.Info("attempted to cancel order that is no longer open", zap.String("status", order.Status))
+		return fmt.Errorf("failed to cancel order: %w", apperrors.ErrConflict)
+	}
+
+	_, err = qtx.SetOrderReservationsStatus(ctx, database.SetOrderReservationsStatusParams{
+		OrderID:   order.ID,
+		Status:    reservationReleased,
+		UpdatedAt: now,
+		Reason:    models.StringToNullString("order cancelled"),
+	})
+	if err != nil {
+		logger.Error("failed to release order reservations", zap.Error(err))
+		return fmt.Errorf("failed to release order reservations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("order cancelled", zap.String("orderID", order.ID.String()))
+	return nil
+}
+
+// RunReservationExpiry releases expired reservations every interval until the context is cancelled, so unpaid
+// orders expire on time rather than on the next checkout
+func (s *OrderService) RunReservationExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Errors are logged by ReleaseExpiredReservations, the next tick retries
+			_, _ = s.ReleaseExpiredReservations(ctx)
+		}
+	}
+}
+
+// ReleaseExpiredReservations releases reservations past their expiry and marks their unpaid orders as expired,
+// returning the number of orders that expired
+func (s *OrderService) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	logger := s.logger.With(zap.String("method", "ReleaseExpiredReservations"))
+
+	expired, err := s.db.ReleaseExpiredReservations(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to release expired reservations", zap.Error(err))
+		return 0, fmt.Errorf("failed to release expired reservations: %w", err)
+	}
+
+	if expired > 0 {
+		logger.Info("expired unpaid orders", zap.Int64("orders", expired))
+	}
+	return expired, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"go.uber.org/zap"
+)
+
+const defaultMailFrom = "no-reply@localhost"
+
+// NewMailer returns the mailer implementation selected by the mailer config
+func NewMailer(mconf *config.MailerConfig) (models.Mailer, error) {
+	from := mconf.From
+	if from == "" {
+		from = defaultMailFrom
+	}
+
+	switch mconf.Driver {
+	case "log":
+		return &LogMailer{logger: config.GetLogger()}, nil
+	case "file":
+		if err := os.MkdirAll(mconf.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+		return &FileMailer{logger: config.GetLogger(), dir: mconf.Dir, from: from}, nil
+	case "smtp":
+		if mconf.SMTPHost == "" || mconf.SMTPPort == "" {
+			return nil, fmt.Errorf("smtp mailer requires SMTP_HOST and SMTP_PORT")
+		}
+		return &SMTPMailer{logger: config.GetLogger(), mailerConfig: mconf, from: from}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", mconf.Driver)
+	}
+}
+
+// LogMailer writes emails to the application log, intended for development
+type LogMailer struct {
+	logger *zap.Logger
+}
+
+func (m *LogMailer) Send(ctx context.Context, message *models.EmailMessage) error {
+	m.logger.Info("email sent",
+		zap.String("mailer", "log"),
+		zap.String("to", message.To),
+		zap.String("subject", message.Subject),
+		zap.String("body", message.Body),
+	)
+	return nil
+}
+
+// FileMailer writes every email to its own file in a directory, intended for development
+type FileMailer struct {
+	logger *zap.Logger
+	dir    string
+	from   string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+
+func (m *FileMailer) Send(ctx context.Context, message *models.EmailMessage) error {
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(message.To, "_"))
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, buildMessage(m.from, message), 0o644); err != nil {
+		m.logger.Error("failed to write email to file", zap.Error(err), zap.String("path", path))
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	m.logger.Info("email written to file", zap.String("path", path), zap.String("to", message.To))
+	return nil
+}
+
+// SMTPMailer delivers emails through an SMTP server
+type SMTPMailer struct {
+	logger       *zap.Logger
+	mailerConfig *config.MailerConfig
+	from         string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message *models.EmailMessage) error {
+	addr := fmt.Sprintf("%s:%s", m.mailerConfig.SMTPHost, m.mailerConfig.SMTPPort)
+
+	var auth smtp.Auth
+	if m.mailerConfig.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.mailerConfig.SMTPUsername, m.mailerConfig.SMTPPassword, m.mailerConfig.SMTPHost)
+	}
+
+	if err := smtp.SendMail(addr, auth, m.from, []string{message.To}, buildMessage(m.from, message)); err != nil {
+		m.logger.Error("failed to send email", zap.Error(err), zap.String("to", message.To))
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	m.logger.Info("email sent", zap.String("mailer", "smtp"), zap.String("to", message.To))
+	return nil
+}
+
+func buildMessage(from string, message *models.EmailMessage) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(message.Body)
+	return []byte(b.String())
+}
@@ -5,34 +5,43 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/database"
 	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // TODO: Add endpoint for user to list there purchases (orders)
-var globalShipping float32 = 0
+// globalShipping is the shipping price in minor units of the base currency
+var globalShipping int64 = 0
 
 type OrderService struct {
-	logger *zap.Logger
-	db     *database.Queries
-	sqlDB  *sql.DB
+	logger         *zap.Logger
+	db             *database.Queries
+	sqlDB          *sql.DB
+	reservationTTL time.Duration
+	alerts         *StockAlertService
+	currencies     *CurrencyService
 }
 
-func NewOrderService(db *database.Queries, sqlDB *sql.DB) *OrderService {
+func NewOrderService(db *database.Queries, sqlDB *sql.DB, reservationTTL time.Duration, alerts *StockAlertService, currencies *CurrencyService) *OrderService {
 	return &OrderService{
-		logger: config.GetLogger(),
-		sqlDB:  sqlDB,
-		db:     db,
+		logger:         config.GetLogger(),
+		sqlDB:          sqlDB,
+		db:             db,
+		reservationTTL: reservationTTL,
+		alerts:         alerts,
+		currencies:     currencies,
 	}
 }
 
+// CreateOrder creates an order for the cart's items, in the cart's currency, and reserves their stock until the
+// reservation TTL has passed. It fails with apperrors.ErrOutOfStock when an item is no longer available.
 func (s *OrderService) CreateOrder(ctx context.Context, cart models.Cart, tempCart bool) (models.Order, error) {
 
 	logger := s.logger.With(
@@ -40,13 +49,23 @@ func (s *OrderService) CreateOrder(ctx context.Context, cart models.Cart, tempCa
 		zap.String("cartID", cart.ID.String()),
 	)
 
+	if cart.Currency == "" {
+		cart.Currency = s.currencies.Base()
+	}
+	// The rate is kept with the order so its amounts can be traced back to the base currency prices
+	rate, err := s.currencies.Rate(cart.Currency)
+	if err != nil {
+		return models.Order{}, err
+	}
+
 	productTotal, err := s.getCartTotal(&cart)
 	if err != nil {
 		logger.Error("failed to calculate cart total", zap.Error(err))
 		return models.Order{}, fmt.Errorf("failed to calculate cart total: %w", err)
 	}
 
-	orderTotal := productTotal + globalShipping
+	shippingPrice := money.New(globalShipping, s.currencies.Base()).Convert(rate, cart.Currency)
+	orderTotal := productTotal.Add(shippingPrice)
 
 	cartID := uuid.NullUUID{
 		Valid: true,
@@ -57,6 +76,11 @@ func (s *OrderService) CreateOrder(ctx context.Context, cart models.Cart, tempCa
 		cartID.Valid = false
 	}
 
+	// Free the stock held by abandoned checkouts before checking availability
+	if _, err := s.ReleaseExpiredReservations(ctx); err != nil {
+		logger.Warn("failed to release expired reservations", zap.Error(err))
+	}
+
 	// Start transaction
 	tx, err := s.sqlDB.Begin()
 	if err != nil {
@@ -70,17 +94,31 @@ func (s *OrderService) CreateOrder(ctx context.Context, cart models.Cart, tempCa
 	}()
 	qtx := s.db.WithTx(tx)
 
+	// A new checkout of the same cart replaces any earlier unpaid one, which would otherwise hold the same stock
+	if cartID.Valid {
+		_, err := qtx.SupersedeCartOrders(ctx, database.SupersedeCartOrdersParams{
+			CartID:    cartID,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			logger.Error("failed to cancel earlier orders of cart", zap.Error(err))
+			return models.Order{}, fmt.Errorf("failed to cancel earlier orders of cart: %w", err)
+		}
+	}
+
 	orderId, err := qtx.CreateOrder(ctx, database.CreateOrderParams{
 		ID:            uuid.New(),
 		UserID:        cart.UserID,
-		ProductTotal:  floatToString(productTotal),
-		OrderTotal:    floatToString(orderTotal),
+		ProductTotal:  productTotal.Decimal(),
+		OrderTotal:    orderTotal.Decimal(),
 		Status:        "created",
 		PaymentMethod: "paypal",
-		ShippingPrice: floatToString(globalShipping),
+		ShippingPrice: shippingPrice.Decimal(),
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 		CartID:        cartID,
+		Currency:      string(cart.Currency),
+		ExchangeRate:  rate.FloatString(10),
 	})
 	if err != nil {
 		logger.Error("failed to create database order", zap.Error(err))
@@ -92,7 +130,8 @@ func (s *OrderService) CreateOrder(ctx context.Context, cart models.Cart, tempCa
 			ID:        uuid.New(),
 			OrderID:   orderId,
 			ProductID: item.ProductID,
-			Price:     floatToString(item.Price),
+			VariantID: uuidToNullUuid(item.VariantID),
+			Price:     item.Price.Decimal(),
 			Quantity:  int32(item.Quantity),
 		}); err != nil {
 			logger.Error("failed to create order item from cart item", zap.Error(err), zap.String("productID", item.ProductID.String()))
@@ -101,6 +140,11 @@ func (s *OrderService) CreateOrder(ctx context.Context, cart models.Cart, tempCa
 		}
 	}
 
+	if err := s.reserveStock(ctx, qtx, orderId, cart.Items, time.Now().Add(s.reservationTTL)); err != nil {
+		logger.Info("failed to reserve stock for order", zap.Error(err))
+		return models.Order{}, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		logger.Error("failed to commit transaction", zap.Error(err))
 		return models.Order{}, fmt.Errorf("failed to commit transaction: %w", err)
@@ -144,6 +188,7 @@ func (s *OrderService) GetUserOrders(ctx context.Context, userID uuid.UUID) ([]m
 		order.PaymentEmail = ""
 		order.ProcessorOrderID = ""
 		order.Status = ""
+		order.ExchangeRate = ""
 		userOrders = append(userOrders, order)
 	}
 
@@ -209,19 +254,21 @@ func (s *OrderService) DatabaseOrderToOrder(ctx context.Context, orderRecord dat
 		return models.Order{}, fmt.Errorf("failed to retrieve order items from db: %w", err)
 	}
 
-	productTotal, err := stringToFloat32(orderRecord.ProductTotal)
+	currency := money.Currency(orderRecord.Currency)
+
+	productTotal, err := money.Parse(orderRecord.ProductTotal, currency)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("failed to convert string product total to float: %w", err)
+		return models.Order{}, fmt.Errorf("failed to parse product total: %w", err)
 	}
 
-	shippingPrice, err := stringToFloat32(orderRecord.ShippingPrice)
+	shippingPrice, err := money.Parse(orderRecord.ShippingPrice, currency)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("failed to convert string shipping price to float: %w", err)
+		return models.Order{}, fmt.Errorf("failed to parse shipping price: %w", err)
 	}
 
-	orderTotal, err := stringToFloat32(orderRecord.OrderTotal)
+	orderTotal, err := money.Parse(orderRecord.OrderTotal, currency)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("failed to convert string order total to float: %w", err)
+		return models.Order{}, fmt.Errorf("failed to parse order total: %w", err)
 	}
 
 	// Create order
@@ -236,6 +283,8 @@ func (s *OrderService) DatabaseOrderToOrder(ctx context.Context, orderRecord dat
 		PaymentEmail:     sqlNullStringToString(orderRecord.PaymentEmail),
 		PayerID:          sqlNullStringToString(orderRecord.PayerID),
 		ShippingPrice:    shippingPrice,
+		Currency:         currency,
+		ExchangeRate:     orderRecord.ExchangeRate,
 		CartID:           nullUuidToUuid(orderRecord.CartID),
 		CreatedAt:        orderRecord.CreatedAt,
 		UpdatedAt:        orderRecord.UpdatedAt,
@@ -243,15 +292,17 @@ func (s *OrderService) DatabaseOrderToOrder(ctx context.Context, orderRecord dat
 
 	orderItems := make([]models.OrderItem, 0, len(orderItemsRecord))
 	for _, item := range orderItemsRecord {
-		priceF, err := stringToFloat32(item.Price)
+		price, err := money.Parse(item.Price, currency)
 		if err != nil {
-			return models.Order{}, fmt.Errorf("failed to convert item price to float: %w", err)
+			return models.Order{}, fmt.Errorf("failed to parse item price: %w", err)
 		}
 		orderItem := models.OrderItem{
 			ProductID: item.ProductID,
+			VariantID: nullUuidToUuid(item.VariantID),
+			Sku:       sqlNullStringToString(item.VariantSku),
 			Name:      item.Name,
 			Quantity:  int(item.Quantity),
-			Price:     priceF,
+			Price:     price,
 		}
 		orderItems = append(orderItems, orderItem)
 	}
@@ -315,23 +366,15 @@ func (s *OrderService) UpdateOrderCompleted(ctx context.Context, orderResult *mo
 	return nil
 }
 
-func stringToFloat32(s string) (float32, error) {
-	f, err := strconv.ParseFloat(s, 32)
-	if err != nil {
-		return 0, err
-	}
-	return float32(f), nil
-}
-
-func (p *OrderService) getCartTotal(cart *models.Cart) (float32, error) {
+func (p *OrderService) getCartTotal(cart *models.Cart) (money.Money, error) {
 
 	if cart == nil {
 		p.logger.Error("failed to calculate cart total for nil cart")
-		return 0, errors.New("nil cart")
+		return money.Money{}, errors.New("nil cart")
 	}
-	var cartTotal float32 = 0
+	cartTotal := money.New(0, cart.Currency)
 	for _, ci := range cart.Items {
-		cartTotal += ci.Price * float32(ci.Quantity)
+		cartTotal = cartTotal.Add(ci.Price.Mul(ci.Quantity))
 	}
 
 	return cartTotal, nil
@@ -17,9 +17,10 @@ type PaymentService struct {
 	orderSrv         *OrderService
 	productSrv       *ProductService
 	cartSrv          *CartService
+	recoverySrv      *CartRecoveryService
 }
 
-func NewPaymentService(db *database.Queries, processor models.PaymentProcessor, orderSrv *OrderService, productSrv *ProductService, cartSrv *CartService) *PaymentService {
+func NewPaymentService(db *database.Queries, processor models.PaymentProcessor, orderSrv *OrderService, productSrv *ProductService, cartSrv *CartService, recoverySrv *CartRecoveryService) *PaymentService {
 	return &PaymentService{
 		logger:           config.GetLogger(),
 		db:               db,
@@ -27,6 +28,7 @@ func NewPaymentService(db *database.Queries, processor models.PaymentProcessor,
 		orderSrv:         orderSrv,
 		productSrv:       productSrv,
 		cartSrv:          cartSrv,
+		recoverySrv:      recoverySrv,
 	}
 }
 
@@ -51,6 +53,9 @@ func (p *PaymentService) CreateProcessorOrder(ctx context.Context, order *models
 	return orderResult, nil
 }
 
+// CaptureOrder captures the payment of an order whose stock is still reserved and turns the reservation into a
+// permanent stock decrement. Orders whose reservation expired fail with apperrors.ErrReservationExpired without
+// being charged.
 func (p *PaymentService) CaptureOrder(ctx context.Context, orderID string) error {
 
 	logger := p.logger.With(
@@ -58,6 +63,17 @@ func (p *PaymentService) CaptureOrder(ctx context.Context, orderID string) error
 		zap.String("orderID", orderID),
 	)
 
+	order, err := p.orderSrv.GetOrderByProcessorOrderID(ctx, orderID)
+	if err != nil {
+		logger.Error("failed to get order by processor order ID", zap.Error(err))
+		return fmt.Errorf("failed to retrieve order by processor order ID: %w", err)
+	}
+
+	if err := p.orderSrv.CheckReservation(ctx, order.ID); err != nil {
+		logger.Info("refusing to capture order without reserved stock", zap.Error(err))
+		return fmt.Errorf("failed to capture order: %w", err)
+	}
+
 	orderResult, err := p.paymentProcessor.CaptureOrder(ctx, orderID)
 	if err != nil {
 		logger.Error("failed to capture order", zap.Error(err))
@@ -70,23 +86,18 @@ func (p *PaymentService) CaptureOrder(ctx context.Context, orderID string) error
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
-	order, err := p.orderSrv.GetOrderByProcessorOrderID(ctx, orderResult.ID)
+	err = p.orderSrv.ConvertReservation(ctx, order)
 	if err != nil {
-		logger.Error("failed to get order by processor order ID", zap.Error(err))
-		return fmt.Errorf("failed to retrieve order by processor order ID: %w", err)
+		logger.Error("failed to take captured order from stock", zap.Error(err))
+		return fmt.Errorf("failed to update stock: %w", err)
 	}
 
-	orderItems := order.OrderItems
-
-	// Update product Stock
-	for _, item := range orderItems {
-		err = p.productSrv.UpdateStock(ctx, item.ProductID, item.Quantity)
-		if err != nil {
-			logger.Error("failed to update product stock", zap.Error(err), zap.String("productID", item.ProductID.String()))
+	if order.CartID != nil {
+		// The payment went through, so a failure to record the conversion is only logged
+		if err := p.recoverySrv.RecordConversion(ctx, *order.CartID, order.ID); err != nil {
+			logger.Error("failed to record cart conversion", zap.Error(err))
 		}
-	}
 
-	if order.CartID != nil {
 		err = p.cartSrv.DeleteCart(ctx, *order.CartID)
 		if err != nil {
 			logger.Info("failed to delete cart", zap.Error(err))
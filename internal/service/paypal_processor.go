@@ -7,6 +7,7 @@ import (
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/pkg/money"
 	"github.com/plutov/paypal/v4"
 	"go.uber.org/zap"
 )
@@ -56,26 +57,33 @@ func (p *PayPalProcessor) CreateProcessorOrder(ctx context.Context, order *model
 		zap.String("method", "CreateProcessorOrder"),
 	)
 
-	items, err := p.orderItemsToPaypalItems(order.OrderItems)
+	items, itemTotal, err := p.orderItemsToPaypalItems(order.OrderItems)
 	if err != nil {
 		logger.Error("failed to convert order items into paypal items", zap.Error(err))
 		return nil, fmt.Errorf("failed to process order items into paypal items: %w", err)
 	}
 
+	// PayPal rejects orders whose breakdown does not add up, so the totals are checked against the line items
+	// before sending them
+	orderTotal := itemTotal.Add(order.ShippingPrice)
+	if itemTotal != order.ProductTotal || orderTotal != order.OrderTotal {
+		logger.Error("order totals do not match its items",
+			zap.String("orderID", order.ID.String()),
+			zap.Stringer("itemTotal", itemTotal),
+			zap.Stringer("productTotal", order.ProductTotal),
+			zap.Stringer("orderTotal", order.OrderTotal),
+		)
+		return nil, fmt.Errorf("order total %s does not match the sum of its items %s", order.OrderTotal, orderTotal)
+	}
+
 	units := []paypal.PurchaseUnitRequest{
 		{
 			Amount: &paypal.PurchaseUnitAmount{
-				Currency: "USD",
-				Value:    floatToString(order.OrderTotal),
+				Currency: string(orderTotal.Currency),
+				Value:    orderTotal.Decimal(),
 				Breakdown: &paypal.PurchaseUnitAmountBreakdown{
-					ItemTotal: &paypal.Money{
-						Currency: "USD",
-						Value:    floatToString(order.ProductTotal),
-					},
-					Shipping: &paypal.Money{
-						Currency: "USD",
-						Value:    floatToString(order.ShippingPrice),
-					},
+					ItemTotal: paypalMoney(itemTotal),
+					Shipping:  paypalMoney(order.ShippingPrice),
 				},
 			},
 			Items: items,
@@ -132,24 +140,31 @@ func (p *PayPalProcessor) CaptureOrder(ctx context.Context, processorOrderID str
 	return &orderResult, nil
 }
 
-func (p *PayPalProcessor) orderItemsToPaypalItems(orderItems []models.OrderItem) ([]paypal.Item, error) {
+// orderItemsToPaypalItems converts the order items and returns the sum of their amounts, as PayPal will add them up
+func (p *PayPalProcessor) orderItemsToPaypalItems(orderItems []models.OrderItem) ([]paypal.Item, money.Money, error) {
 	if len(orderItems) <= 0 {
-		return nil, fmt.Errorf("cannot conver orderItems to paypal items: %w", errors.New("no order items"))
+		return nil, money.Money{}, fmt.Errorf("cannot conver orderItems to paypal items: %w", errors.New("no order items"))
 	}
 
 	paypalItems := make([]paypal.Item, 0, len(orderItems))
+	var itemTotal money.Money
 
 	for _, oi := range orderItems {
 		paypalItem := paypal.Item{
-			Name: oi.Name,
-			UnitAmount: &paypal.Money{
-				Currency: "USD",
-				Value:    floatToString(oi.Price),
-			},
-			Quantity: intToString(oi.Quantity),
+			Name:       oi.Name,
+			UnitAmount: paypalMoney(oi.Price),
+			Quantity:   intToString(oi.Quantity),
 		}
 		paypalItems = append(paypalItems, paypalItem)
+		itemTotal = itemTotal.Add(oi.Price.Mul(oi.Quantity))
 	}
 
-	return paypalItems, nil
+	return paypalItems, itemTotal, nil
+}
+
+func paypalMoney(m money.Money) *paypal.Money {
+	return &paypal.Money{
+		Currency: string(m.Currency),
+		Value:    m.Decimal(),
+	}
 }
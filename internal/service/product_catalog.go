@@ -0,0 +1,337 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/category"
+	"github.com/CP-Payne/ecomstore/internal/domain/inventory"
+	"github.com/CP-Payne/ecomstore/internal/domain/product"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/catalogio"
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// Reading stops after this many invalid rows, the report would not get more useful
+	maxImportErrors = 100
+	exportBatchSize = 500
+)
+
+// ImportCatalog upserts the products read from r by SKU, creating missing categories by slug. All rows are
+// validated first and written in a single transaction, so an import either applies completely or not at all.
+// A dry run performs the writes and rolls them back to report what would change.
+func (s *ProductService) ImportCatalog(ctx context.Context, r *catalogio.Reader, dryRun bool) (models.CatalogImportReport, error) {
+	logger := s.logger.With(
+		zap.String("method", "ImportCatalog"),
+		zap.Bool("dryRun", dryRun),
+	)
+
+	report := models.CatalogImportReport{DryRun: dryRun}
+
+	var records []catalogio.Record
+	seen := make(map[string]int)
+
+	for len(report.Errors) < maxImportErrors {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var errs errsx.Map
+		if err != nil && !errors.As(err, &errs) {
+			logger.Warn("failed to read import", zap.Error(err))
+			return models.CatalogImportReport{}, fmt.Errorf("failed to read import: %w", err)
+		}
+		report.Rows++
+
+		if errs == nil {
+			errs = validateCatalogRecord(&rec)
+		}
+		if line, ok := seen[rec.Sku]; ok {
+			errs.Set("sku", fmt.Sprintf("sku already appears on line %d", line))
+		} else if rec.Sku != "" {
+			seen[rec.Sku] = r.Line()
+		}
+
+		if errs != nil {
+			report.Errors = append(report.Errors, models.CatalogRowError{Line: r.Line(), Sku: rec.Sku, Errors: errs})
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	if len(report.Errors) > 0 {
+		logger.Info("import rejected", zap.Int("invalidRows", len(report.Errors)))
+		return report, nil
+	}
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.CatalogImportReport{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	categories := make(map[string]uuid.UUID)
+	now := time.Now()
+
+	for _, rec := range records {
+		categoryID, ok := categories[rec.Category]
+		if !ok {
+			var created bool
+			categoryID, created, err = upsertImportCategory(ctx, qtx, rec)
+			if err != nil {
+				logger.Error("failed to import category", zap.Error(err), zap.String("category", rec.Category))
+				return models.CatalogImportReport{}, fmt.Errorf("failed to import category %q: %w", rec.Category, err)
+			}
+			if created {
+				report.CategoriesCreated++
+			}
+			categories[rec.Category] = categoryID
+		}
+
+		active := true
+		if rec.Active != nil {
+			active = *rec.Active
+		}
+
+		// Stock set by the import is recorded as the change from the existing product's stock
+		previousStock, err := qtx.GetProductStockBySku(ctx, rec.Sku)
+		if err != nil && !apperrors.IsNoRowsError(err) {
+			logger.Error("failed to lock product stock", zap.Error(err), zap.String("sku", rec.Sku))
+			return models.CatalogImportReport{}, fmt.Errorf("failed to import product %q: %w", rec.Sku, err)
+		}
+
+		id := uuid.New()
+		saved, err := qtx.UpsertProductBySku(ctx, database.UpsertProductBySkuParams{
+			ID:             id,
+			Name:           rec.Name,
+			Description:    models.StringToNullString(rec.Description),
+			Price:          money.New(int64(rec.Price), config.BaseCurrency()).Decimal(),
+			Brand:          models.StringToNullString(rec.Brand),
+			Sku:            rec.Sku,
+			StockQuantity:  int32(rec.Stock),
+			CategoryID:     categoryID,
+			ImageUrl:       models.StringToNullString(rec.ImageURL),
+			ThumbnailUrl:   models.StringToNullString(rec.ThumbnailURL),
+			Specifications: models.RawMessageToNullRawMessage(rec.Specifications),
+			IsActive:       active,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			logger.Error("failed to import product", zap.Error(err), zap.String("sku", rec.Sku))
+			return models.CatalogImportReport{}, fmt.Errorf("failed to import product %q: %w", rec.Sku, err)
+		}
+
+		movement := database.CreateStockMovementParams{
+			ProductID: saved.ID,
+			Kind:      string(inventory.MovementAdjustment),
+			Quantity:  saved.StockQuantity - previousStock,
+			Reason:    models.StringToNullString("catalog import"),
+			CreatedAt: now,
+		}
+		// The generated ID is only kept when the SKU did not exist yet
+		if saved.ID == id {
+			movement.Kind = string(inventory.MovementRestock)
+			report.Created++
+		} else {
+			report.Updated++
+		}
+
+		if err := recordStockMovement(ctx, qtx, movement); err != nil {
+			logger.Error("failed to record imported stock", zap.Error(err), zap.String("sku", rec.Sku))
+			return models.CatalogImportReport{}, err
+		}
+	}
+
+	if dryRun {
+		logger.Info("import dry run completed", zap.Int("rows", report.Rows))
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.CatalogImportReport{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	report.Applied = true
+
+	s.alerts.queueCheckAll()
+
+	logger.Info("catalog imported",
+		zap.Int("created", report.Created),
+		zap.Int("updated", report.Updated),
+		zap.Int("categoriesCreated", report.CategoriesCreated),
+	)
+	return report, nil
+}
+
+// upsertImportCategory returns the ID of the record's category, creating it as a root category when the slug is
+// unknown and renaming it when the record names it differently
+func upsertImportCategory(ctx context.Context, qtx *database.Queries, rec catalogio.Record) (uuid.UUID, bool, error) {
+	existing, err := qtx.GetCategoryBySlug(ctx, rec.Category)
+	if err != nil && !apperrors.IsNoRowsError(err) {
+		return uuid.Nil, false, err
+	}
+
+	if err == nil {
+		if rec.CategoryName == "" || rec.CategoryName == existing.Name {
+			return existing.ID, false, nil
+		}
+		_, err := qtx.UpdateCategory(ctx, database.UpdateCategoryParams{
+			ID:          existing.ID,
+			Name:        rec.CategoryName,
+			Description: existing.Description,
+			ParentID:    existing.ParentID,
+			Slug:        existing.Slug,
+			SortOrder:   existing.SortOrder,
+		})
+		return existing.ID, false, err
+	}
+
+	name := rec.CategoryName
+	if name == "" {
+		name = rec.Category
+	}
+
+	created, err := qtx.CreateCategory(ctx, database.CreateCategoryParams{
+		ID:   uuid.New(),
+		Name: name,
+		Slug: rec.Category,
+	})
+	return created.ID, true, err
+}
+
+// validateCatalogRecord applies the product and category rules to an import row, normalising names on the way
+func validateCatalogRecord(rec *catalogio.Record) errsx.Map {
+	var errs errsx.Map
+
+	name, err := product.ValidateName(rec.Name)
+	if err != nil {
+		errs.Set("name", err)
+	}
+	rec.Name = string(name)
+
+	if _, err := product.ValidateSku(rec.Sku); err != nil {
+		errs.Set("sku", err)
+	}
+
+	if _, err := product.ValidatePriceAmount(int64(rec.Price)); err != nil {
+		errs.Set("price", err)
+	}
+
+	if _, err := product.ValidateStock(rec.Stock); err != nil {
+		errs.Set("stock", err)
+	}
+
+	if _, err := category.ValidateSlug(rec.Category); err != nil {
+		errs.Set("category", err)
+	}
+
+	if rec.CategoryName != "" {
+		categoryName, err := category.ValidateName(rec.CategoryName)
+		if err != nil {
+			errs.Set("categoryName", err)
+		}
+		rec.CategoryName = string(categoryName)
+	}
+
+	if len(rec.Brand) > 100 {
+		errs.Set("brand", "brand must be at most 100 characters")
+	}
+
+	if len(rec.ImageURL) > 255 {
+		errs.Set("imageUrl", "image url must be at most 255 characters")
+	}
+
+	if len(rec.ThumbnailURL) > 255 {
+		errs.Set("thumbnailUrl", "thumbnail url must be at most 255 characters")
+	}
+
+	specs := bytes.TrimSpace(rec.Specifications)
+	if len(specs) > 0 && string(specs) != "null" && specs[0] != '{' {
+		errs.Set("specifications", "specifications must be an object")
+	}
+
+	return errs
+}
+
+// ExportCatalog writes every product, archived ones included, in the import format. Products are read in batches
+// and flushed after each one so large catalogs are streamed rather than held in memory.
+func (s *ProductService) ExportCatalog(ctx context.Context, w *catalogio.Writer) (int, error) {
+	logger := s.logger.With(zap.String("method", "ExportCatalog"))
+
+	var afterCreatedAt time.Time
+	var afterID uuid.UUID
+	exported := 0
+
+	for {
+		products, err := s.db.ExportProducts(ctx, database.ExportProductsParams{
+			AfterCreatedAt: afterCreatedAt,
+			AfterID:        afterID,
+			RowLimit:       exportBatchSize,
+		})
+		if err != nil {
+			logger.Error("failed to retrieve products", zap.Error(err))
+			return exported, fmt.Errorf("failed to retrieve products: %w", err)
+		}
+
+		for _, p := range products {
+			price, err := money.Parse(p.Price, config.BaseCurrency())
+			if err != nil {
+				logger.Error("failed to parse product price", zap.Error(err), zap.String("sku", p.Sku))
+				return exported, fmt.Errorf("failed to export product %q: %w", p.Sku, err)
+			}
+
+			active := p.IsActive
+			err = w.Write(catalogio.Record{
+				Sku:            p.Sku,
+				Name:           p.Name,
+				Description:    models.NullStringToString(p.Description),
+				Price:          catalogio.Price(price.Amount),
+				Brand:          models.NullStringToString(p.Brand),
+				Stock:          int(p.StockQuantity),
+				Category:       p.CategorySlug,
+				CategoryName:   p.CategoryName,
+				ImageURL:       models.NullStringToString(p.ImageUrl),
+				ThumbnailURL:   models.NullStringToString(p.ThumbnailUrl),
+				Specifications: models.NullRawMessageToRawMessage(p.Specifications),
+				Active:         &active,
+			})
+			if err != nil {
+				return exported, fmt.Errorf("failed to write product %q: %w", p.Sku, err)
+			}
+			exported++
+		}
+
+		if err := w.Flush(); err != nil {
+			return exported, fmt.Errorf("failed to write export: %w", err)
+		}
+
+		if len(products) < exportBatchSize {
+			break
+		}
+		last := products[len(products)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+
+	logger.Info("catalog exported", zap.Int("products", exported))
+	return exported, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (s *ProductService) GetCategory(ctx context.Context, id uuid.UUID) (models.Category, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetCategory"),
+		zap.String("categoryID", id.String()),
+	)
+
+	category, err := s.db.GetCategory(ctx, id)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Category{}, fmt.Errorf("failed to retrieve category: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve category", zap.Error(err))
+		return models.Category{}, fmt.Errorf("failed to retrieve category: %w", err)
+	}
+
+	return models.DatabaseCategoryToCategory(category), nil
+}
+
+func (s *ProductService) GetCategoryBySlug(ctx context.Context, slug string) (models.Category, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetCategoryBySlug"),
+		zap.String("slug", slug),
+	)
+
+	category, err := s.db.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Category{}, fmt.Errorf("failed to retrieve category: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve category", zap.Error(err))
+		return models.Category{}, fmt.Errorf("failed to retrieve category: %w", err)
+	}
+
+	return models.DatabaseCategoryToCategory(category), nil
+}
+
+// GetCategoryBreadcrumbs returns the path from the root category down to and including the given category
+func (s *ProductService) GetCategoryBreadcrumbs(ctx context.Context, id uuid.UUID) ([]models.Category, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetCategoryBreadcrumbs"),
+		zap.String("categoryID", id.String()),
+	)
+
+	path, err := s.db.GetCategoryPath(ctx, id)
+	if err != nil {
+		logger.Error("failed to retrieve category path", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve category path: %w", err)
+	}
+
+	breadcrumbs := make([]models.Category, 0, len(path))
+	for _, category := range path {
+		breadcrumbs = append(breadcrumbs, models.DatabaseCategoryToCategory(database.Category(category)))
+	}
+
+	return breadcrumbs, nil
+}
+
+func (s *ProductService) CreateCategory(ctx context.Context, params models.CategoryParams) (models.Category, error) {
+	logger := s.logger.With(
+		zap.String("method", "CreateCategory"),
+		zap.String("slug", params.Slug),
+	)
+
+	category, err := s.db.CreateCategory(ctx, database.CreateCategoryParams{
+		ID:          uuid.New(),
+		Name:        params.Name,
+		Description: models.StringToNullString(params.Description),
+		ParentID:    uuidToNullUuid(params.ParentID),
+		Slug:        params.Slug,
+		SortOrder:   int32(params.SortOrder),
+	})
+	if err != nil {
+		if appErr := productWriteError(err); appErr != nil {
+			logger.Info("category rejected by constraint", zap.Error(err))
+			return models.Category{}, fmt.Errorf("failed to create category: %w", appErr)
+		}
+		logger.Error("failed to create category", zap.Error(err))
+		return models.Category{}, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	logger.Info("category created", zap.String("categoryID", category.ID.String()))
+	return models.DatabaseCategoryToCategory(category), nil
+}
+
+// UpdateCategory replaces all writable fields of a category. A category cannot be moved under one of its own
+// descendants, as that would detach the subtree from the root.
+func (s *ProductService) UpdateCategory(ctx context.Context, id uuid.UUID, params models.CategoryParams) (models.Category, error) {
+	logger := s.logger.With(
+		zap.String("method", "UpdateCategory"),
+		zap.String("categoryID", id.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.Category{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	if params.ParentID != nil {
+		// Locking the category and every ancestor of its new parent makes concurrent moves that could form a
+		// cycle between them, such as A under B and B under A, wait for each other, so the second one sees the
+		// first when checking descendants
+		_, err := qtx.LockCategoryPath(ctx, database.LockCategoryPathParams{
+			ParentID:   *params.ParentID,
+			CategoryID: id,
+		})
+		if err != nil {
+			logger.Error("failed to lock category path", zap.Error(err))
+			return models.Category{}, fmt.Errorf("failed to update category: %w", err)
+		}
+
+		subtree, err := qtx.GetCategoryDescendantIDs(ctx, id)
+		if err != nil {
+			logger.Error("failed to retrieve category descendants", zap.Error(err))
+			return models.Category{}, fmt.Errorf("failed to update category: %w", err)
+		}
+		for _, descendantID := range subtree {
+			if descendantID == *params.ParentID {
+				return models.Category{}, fmt.Errorf("failed to update category: %w", apperrors.ErrCategoryCycle)
+			}
+		}
+	}
+
+	category, err := qtx.UpdateCategory(ctx, database.UpdateCategoryParams{
+		ID:          id,
+		Name:        params.Name,
+		Description: models.StringToNullString(params.Description),
+		ParentID:    uuidToNullUuid(params.ParentID),
+		Slug:        params.Slug,
+		SortOrder:   int32(params.SortOrder),
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to update category that does not exist")
+			return models.Category{}, fmt.Errorf("failed to update category: %w", apperrors.ErrNotFound)
+		}
+		if appErr := productWriteError(err); appErr != nil {
+			logger.Info("category rejected by constraint", zap.Error(err))
+			return models.Category{}, fmt.Errorf("failed to update category: %w", appErr)
+		}
+		logger.Error("failed to update category", zap.Error(err))
+		return models.Category{}, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.Category{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("category updated")
+	return models.DatabaseCategoryToCategory(category), nil
+}
+
+// DeleteCategory removes an empty category. Categories that still have subcategories or products are kept
+// and reported as a conflict.
+func (s *ProductService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "DeleteCategory"),
+		zap.String("categoryID", id.String()),
+	)
+
+	deleted, err := s.db.DeleteCategory(ctx, id)
+	if err != nil {
+		if apperrors.IsForeignKeyViolation(err) {
+			logger.Info("attempted to delete category that is still in use")
+			return fmt.Errorf("failed to delete category: %w", apperrors.ErrConflict)
+		}
+		logger.Error("failed to delete category", zap.Error(err))
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("failed to delete category: %w", apperrors.ErrNotFound)
+	}
+
+	logger.Info("category deleted")
+	return nil
+}
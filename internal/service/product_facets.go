@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"go.uber.org/zap"
+)
+
+const (
+	maxFacetValues         = 20
+	maxSpecificationFacets = 10
+)
+
+// priceBucketBounds are the lower bounds, in cents of the base currency, of every price bucket after the first,
+// which starts at 0
+var priceBucketBounds = []int64{2500, 5000, 10000, 25000, 50000, 100000}
+
+// GetProductFacets counts the products matching the filter per brand, category, price bucket and specification value
+func (s *ProductService) GetProductFacets(ctx context.Context, filter models.ProductFilter) (models.ProductFacets, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetProductFacets"),
+	)
+
+	facets := models.ProductFacets{
+		Brands:         []models.FacetCount{},
+		Categories:     []models.FacetCount{},
+		Specifications: []models.SpecificationFacet{},
+	}
+
+	countQuery, args := newProductQuery(filter).countSQL()
+	if err := s.sqlDB.QueryRowContext(ctx, countQuery, args...).Scan(&facets.Total); err != nil {
+		logger.Error("failed to count products", zap.Error(err))
+		return models.ProductFacets{}, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	withoutBrands := filter
+	withoutBrands.Brands = nil
+	q := newProductQuery(withoutBrands)
+	brandQuery := fmt.Sprintf("SELECT p.brand, '', COUNT(*)\n%s\n%s AND p.brand IS NOT NULL\nGROUP BY p.brand\nORDER BY COUNT(*) DESC, p.brand\nLIMIT %d",
+		q.from(), q.whereSQL(), maxFacetValues)
+	if err := s.queryFacetCounts(ctx, brandQuery, q.args, &facets.Brands); err != nil {
+		logger.Error("failed to count brand facets", zap.Error(err))
+		return models.ProductFacets{}, fmt.Errorf("failed to count brand facets: %w", err)
+	}
+
+	withoutCategory := filter
+	withoutCategory.CategoryID = nil
+	q = newProductQuery(withoutCategory)
+	categoryQuery := fmt.Sprintf("SELECT c.id::text, c.name, COUNT(*)\n%s\nJOIN categories c ON c.id = p.category_id\n%s\nGROUP BY c.id, c.name\nORDER BY COUNT(*) DESC, c.name",
+		q.from(), q.whereSQL())
+	if err := s.queryFacetCounts(ctx, categoryQuery, q.args, &facets.Categories); err != nil {
+		logger.Error("failed to count category facets", zap.Error(err))
+		return models.ProductFacets{}, fmt.Errorf("failed to count category facets: %w", err)
+	}
+
+	prices, err := s.priceFacets(ctx, filter)
+	if err != nil {
+		logger.Error("failed to count price facets", zap.Error(err))
+		return models.ProductFacets{}, fmt.Errorf("failed to count price facets: %w", err)
+	}
+	facets.Prices = prices
+
+	specs, err := s.specificationFacets(ctx, filter)
+	if err != nil {
+		logger.Error("failed to count specification facets", zap.Error(err))
+		return models.ProductFacets{}, fmt.Errorf("failed to count specification facets: %w", err)
+	}
+	facets.Specifications = specs
+
+	return facets, nil
+}
+
+func (s *ProductService) queryFacetCounts(ctx context.Context, query string, args []interface{}, counts *[]models.FacetCount) error {
+	rows, err := s.sqlDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.FacetCount
+		if err := rows.Scan(&c.Value, &c.Label, &c.Count); err != nil {
+			return err
+		}
+		*counts = append(*counts, c)
+	}
+	return rows.Err()
+}
+
+// priceFacets returns every price bucket, including empty ones, so the sidebar layout stays stable
+func (s *ProductService) priceFacets(ctx context.Context, filter models.ProductFilter) ([]models.PriceBucket, error) {
+	withoutPrice := filter
+	withoutPrice.MinPrice = nil
+	withoutPrice.MaxPrice = nil
+	q := newProductQuery(withoutPrice)
+
+	query := fmt.Sprintf("SELECT %[1]s, COUNT(*)\n%[2]s\n%[3]s\nGROUP BY %[1]s", priceBucketSQL(), q.from(), q.whereSQL())
+	buckets := priceBuckets()
+
+	rows, err := s.sqlDB.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index int
+		var count int64
+		if err := rows.Scan(&index, &count); err != nil {
+			return nil, err
+		}
+		buckets[index].Count = count
+	}
+	return buckets, rows.Err()
+}
+
+// priceBucketSQL numbers a product's price bucket, 0 for prices under the first bound and i for prices from
+// priceBucketBounds[i-1] up to the next bound, matching the index into priceBuckets
+func priceBucketSQL() string {
+	bounds := make([]string, len(priceBucketBounds))
+	for i, bound := range priceBucketBounds {
+		bounds[i] = money.New(bound, config.BaseCurrency()).Decimal()
+	}
+	return fmt.Sprintf("width_bucket(p.price, ARRAY[%s]::numeric[])", strings.Join(bounds, ", "))
+}
+
+// priceBuckets returns the empty price buckets, the last one without an upper bound
+func priceBuckets() []models.PriceBucket {
+	currency := config.BaseCurrency()
+
+	buckets := make([]models.PriceBucket, len(priceBucketBounds)+1)
+	for i := range buckets {
+		buckets[i].Min = money.New(0, currency)
+		if i > 0 {
+			buckets[i].Min = money.New(priceBucketBounds[i-1], currency)
+		}
+		if i < len(priceBucketBounds) {
+			max := money.New(priceBucketBounds[i], currency)
+			buckets[i].Max = &max
+		}
+	}
+	return buckets
+}
+
+// specificationFacets counts string specification values, including strings inside arrays such as
+// {"colors": ["Black", "White"]}, which the specification filter matches too. Keys the filter constrains are counted without their
+// own constraint, every other key is counted against the full filter.
+func (s *ProductService) specificationFacets(ctx context.Context, filter models.ProductFilter) ([]models.SpecificationFacet, error) {
+	values := make(map[string][]models.FacetCount)
+
+	filteredKeys := make([]string, 0, len(filter.Specs))
+	for key := range filter.Specs {
+		filteredKeys = append(filteredKeys, key)
+	}
+	sort.Strings(filteredKeys)
+
+	q := newProductQuery(filter)
+	keyCondition := ""
+	if len(filteredKeys) > 0 {
+		excluded := make([]string, len(filteredKeys))
+		for i, key := range filteredKeys {
+			excluded[i] = q.arg(key)
+		}
+		keyCondition = " AND kv.key NOT IN (" + strings.Join(excluded, ", ") + ")"
+	}
+	if err := s.querySpecificationCounts(ctx, q, keyCondition, values); err != nil {
+		return nil, err
+	}
+
+	for _, key := range filteredKeys {
+		withoutKey := filter
+		withoutKey.Specs = make(map[string][]string, len(filter.Specs)-1)
+		for k, v := range filter.Specs {
+			if k != key {
+				withoutKey.Specs[k] = v
+			}
+		}
+		q := newProductQuery(withoutKey)
+		if err := s.querySpecificationCounts(ctx, q, " AND kv.key = "+q.arg(key), values); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keys shared by the most products come first
+	type keyTotal struct {
+		key   string
+		total int64
+	}
+	totals := make([]keyTotal, 0, len(values))
+	for key, counts := range values {
+		var total int64
+		for _, c := range counts {
+			total += c.Count
+		}
+		totals = append(totals, keyTotal{key: key, total: total})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].total != totals[j].total {
+			return totals[i].total > totals[j].total
+		}
+		return totals[i].key < totals[j].key
+	})
+
+	facets := make([]models.SpecificationFacet, 0, maxSpecificationFacets)
+	for _, t := range totals {
+		if len(facets) == maxSpecificationFacets {
+			break
+		}
+		counts := values[t.key]
+		if len(counts) > maxFacetValues {
+			counts = counts[:maxFacetValues]
+		}
+		facets = append(facets, models.SpecificationFacet{Key: t.key, Values: counts})
+	}
+	return facets, nil
+}
+
+func (s *ProductService) querySpecificationCounts(ctx context.Context, q *productQuery, keyCondition string, values map[string][]models.FacetCount) error {
+	query := specificationCountSQL(q, keyCondition)
+
+	rows, err := s.sqlDB.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var c models.FacetCount
+		if err := rows.Scan(&key, &c.Value, &c.Count); err != nil {
+			return err
+		}
+		values[key] = append(values[key], c)
+	}
+	return rows.Err()
+}
+
+// specificationCountSQL counts the products per string specification value, treating each string in an array
+// value as a value of its own. Products listing a value twice are still counted once.
+func specificationCountSQL(q *productQuery, keyCondition string) string {
+	return fmt.Sprintf("SELECT kv.key, e.value #>> '{}', COUNT(DISTINCT p.id)\n%s\n"+
+		"CROSS JOIN LATERAL jsonb_each(CASE WHEN jsonb_typeof(p.specifications) = 'object' THEN p.specifications ELSE '{}'::jsonb END) kv\n"+
+		"CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(kv.value) = 'array' THEN kv.value ELSE jsonb_build_array(kv.value) END) e(value)\n"+
+		"%s AND jsonb_typeof(e.value) = 'string'%s\n"+
+		"GROUP BY kv.key, e.value #>> '{}'\n"+
+		"ORDER BY kv.key, COUNT(DISTINCT p.id) DESC, e.value #>> '{}'",
+		q.from(), q.whereSQL(), keyCondition)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+)
+
+func TestPriceBuckets(t *testing.T) {
+	tests := []struct {
+		index int
+		min   int64
+		max   int64
+	}{
+		{0, 0, 2500},
+		{1, 2500, 5000},
+		{2, 5000, 10000},
+		{3, 10000, 25000},
+		{4, 25000, 50000},
+		{5, 50000, 100000},
+		{6, 100000, 0},
+	}
+
+	buckets := priceBuckets()
+	if len(buckets) != len(tests) {
+		t.Fatalf("expected %d buckets but got %d", len(tests), len(buckets))
+	}
+
+	currency := config.BaseCurrency()
+	for _, tt := range tests {
+		bucket := buckets[tt.index]
+		if min := money.New(tt.min, currency); bucket.Min != min {
+			t.Errorf("expected bucket %d to start at %v but got %v", tt.index, tt.min, bucket.Min)
+		}
+		if tt.index == len(buckets)-1 {
+			if bucket.Max != nil {
+				t.Errorf("expected the last bucket to be open but it ends at %v", *bucket.Max)
+			}
+			continue
+		}
+		if bucket.Max == nil || *bucket.Max != money.New(tt.max, currency) {
+			t.Errorf("expected bucket %d to end at %v but got %v", tt.index, tt.max, bucket.Max)
+		}
+		// Buckets must be contiguous, each starting where the previous one ends
+		if next := buckets[tt.index+1]; next.Min != *bucket.Max {
+			t.Errorf("expected bucket %d to start at %v but got %v", tt.index+1, *bucket.Max, next.Min)
+		}
+	}
+
+	if sql := priceBucketSQL(); sql != "width_bucket(p.price, ARRAY[25.00, 50.00, 100.00, 250.00, 500.00, 1000.00]::numeric[])" {
+		t.Fatalf("unexpected bucket expression %s", sql)
+	}
+}
+
+func TestSpecificationCountSQL(t *testing.T) {
+	q := newProductQuery(models.ProductFilter{Specs: map[string][]string{"color": {"Black"}}})
+	query := specificationCountSQL(q, " AND kv.key = "+q.arg("size"))
+
+	for _, fragment := range []string{
+		"jsonb_array_elements(CASE WHEN jsonb_typeof(kv.value) = 'array' THEN kv.value ELSE jsonb_build_array(kv.value) END) e(value)",
+		"jsonb_typeof(e.value) = 'string' AND kv.key = $3",
+		"COUNT(DISTINCT p.id)",
+		"GROUP BY kv.key, e.value #>> '{}'",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Errorf("expected query to contain %q\n%s", fragment, query)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/imaging"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Uploads larger than this are rejected before decoding, as decoded images take 4 bytes per pixel
+const maxImagePixels = 40_000_000
+
+const imageSizeOriginal = "original"
+
+// imageSizes are generated for every upload. Thumbnails are square crops, other sizes keep the aspect ratio.
+var imageSizes = []struct {
+	name   string
+	max    int
+	square bool
+}{
+	{name: "thumbnail", max: 200, square: true},
+	{name: "small", max: 400},
+	{name: "medium", max: 800},
+	{name: "large", max: 1600},
+}
+
+type ProductImageService struct {
+	logger    *zap.Logger
+	db        *database.Queries
+	sqlDB     *sql.DB
+	store     models.BlobStore
+	publicURL string
+}
+
+func NewProductImageService(db *database.Queries, sqlDB *sql.DB, store models.BlobStore, publicURL string) *ProductImageService {
+	return &ProductImageService{
+		logger:    config.GetLogger(),
+		db:        db,
+		sqlDB:     sqlDB,
+		store:     store,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// GetProductImages returns the images of a product in display order
+func (s *ProductImageService) GetProductImages(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetProductImages"),
+		zap.String("productID", productID.String()),
+	)
+
+	records, err := s.db.GetProductImages(ctx, productID)
+	if err != nil {
+		logger.Error("failed to retrieve product images", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve product images: %w", err)
+	}
+
+	images := make([]models.ProductImage, 0, len(records))
+	for _, record := range records {
+		images = append(images, models.DatabaseImageToImage(record, s.imageURLs(record)))
+	}
+	return images, nil
+}
+
+// UploadProductImage stores an image in every size and adds it after the product's existing images. JPEG and GIF
+// uploads are stored as JPEG, PNG uploads stay PNG to keep their transparency.
+func (s *ProductImageService) UploadProductImage(ctx context.Context, productID uuid.UUID, r io.Reader, altText string) (models.ProductImage, error) {
+	logger := s.logger.With(
+		zap.String("method", "UploadProductImage"),
+		zap.String("productID", productID.String()),
+	)
+
+	if _, err := s.db.GetProductAnyStatus(ctx, productID); err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.ProductImage{}, fmt.Errorf("failed to upload product image: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve product", zap.Error(err))
+		return models.ProductImage{}, fmt.Errorf("failed to upload product image: %w", err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	// Check the dimensions from the header before decoding the whole image
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width*cfg.Height > maxImagePixels {
+		return models.ProductImage{}, fmt.Errorf("failed to upload product image: %w", apperrors.ErrInvalidImage)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("failed to upload product image: %w", apperrors.ErrInvalidImage)
+	}
+	if format != "png" {
+		format = "jpeg"
+	}
+
+	imageID := uuid.New()
+	record := database.ProductImage{ID: imageID, ProductID: productID, Format: format}
+
+	// Store the original re-encoded as well, which drops metadata such as the location a photo was taken
+	variants := map[string]image.Image{imageSizeOriginal: src}
+	for _, size := range imageSizes {
+		if size.square {
+			variants[size.name] = imaging.Thumbnail(src, size.max)
+		} else {
+			variants[size.name] = imaging.Fit(src, size.max)
+		}
+	}
+
+	stored := make([]string, 0, len(variants))
+	for name, img := range variants {
+		key := s.imageKey(record, name)
+		if err := s.putImage(ctx, key, img, format); err != nil {
+			logger.Error("failed to store image", zap.Error(err), zap.String("key", key))
+			s.deleteBlobs(ctx, logger, stored)
+			return models.ProductImage{}, fmt.Errorf("failed to store image: %w", err)
+		}
+		stored = append(stored, key)
+	}
+
+	record, err = s.db.CreateProductImage(ctx, database.CreateProductImageParams{
+		ID:        imageID,
+		ProductID: productID,
+		AltText:   models.StringToNullString(altText),
+		Format:    format,
+		Width:     int32(src.Bounds().Dx()),
+		Height:    int32(src.Bounds().Dy()),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("failed to save product image", zap.Error(err))
+		s.deleteBlobs(ctx, logger, stored)
+		return models.ProductImage{}, fmt.Errorf("failed to save product image: %w", err)
+	}
+
+	if err := s.syncProductImageURLs(ctx, productID); err != nil {
+		logger.Error("failed to update product image urls", zap.Error(err))
+	}
+
+	logger.Info("product image uploaded", zap.String("imageID", imageID.String()))
+	return models.DatabaseImageToImage(record, s.imageURLs(record)), nil
+}
+
+// ReorderProductImages sets the display order of a product's images. The IDs must be exactly the product's images.
+func (s *ProductImageService) ReorderProductImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]models.ProductImage, error) {
+	logger := s.logger.With(
+		zap.String("method", "ReorderProductImages"),
+		zap.String("productID", productID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	current, err := qtx.GetProductImages(ctx, productID)
+	if err != nil {
+		logger.Error("failed to retrieve product images", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve product images: %w", err)
+	}
+	if len(current) != len(imageIDs) {
+		return nil, fmt.Errorf("failed to reorder product images: %w", apperrors.ErrInvalidRef)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(imageIDs))
+	for position, imageID := range imageIDs {
+		if seen[imageID] {
+			return nil, fmt.Errorf("failed to reorder product images: %w", apperrors.ErrInvalidRef)
+		}
+		seen[imageID] = true
+
+		updated, err := qtx.SetProductImagePosition(ctx, database.SetProductImagePositionParams{
+			ID:        imageID,
+			ProductID: productID,
+			Position:  int32(position),
+		})
+		if err != nil {
+			logger.Error("failed to update image position", zap.Error(err), zap.String("imageID", imageID.String()))
+			return nil, fmt.Errorf("failed to reorder product images: %w", err)
+		}
+		if updated == 0 {
+			return nil, fmt.Errorf("failed to reorder product images: %w", apperrors.ErrInvalidRef)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := s.syncProductImageURLs(ctx, productID); err != nil {
+		logger.Error("failed to update product image urls", zap.Error(err))
+	}
+
+	logger.Info("product images reordered")
+	return s.GetProductImages(ctx, productID)
+}
+
+func (s *ProductImageService) DeleteProductImage(ctx context.Context, productID, imageID uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "DeleteProductImage"),
+		zap.String("productID", productID.String()),
+		zap.String("imageID", imageID.String()),
+	)
+
+	record, err := s.db.GetProductImage(ctx, database.GetProductImageParams{
+		ID:        imageID,
+		ProductID: productID,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("failed to delete product image: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve product image", zap.Error(err))
+		return fmt.Errorf("failed to delete product image: %w", err)
+	}
+
+	if _, err := s.db.DeleteProductImage(ctx, database.DeleteProductImageParams{
+		ID:        imageID,
+		ProductID: productID,
+	}); err != nil {
+		logger.Error("failed to delete product image", zap.Error(err))
+		return fmt.Errorf("failed to delete product image: %w", err)
+	}
+
+	// The row is gone, so leftover files are only wasted space
+	keys := []string{s.imageKey(record, imageSizeOriginal)}
+	for _, size := range imageSizes {
+		keys = append(keys, s.imageKey(record, size.name))
+	}
+	s.deleteBlobs(ctx, logger, keys)
+
+	if err := s.syncProductImageURLs(ctx, productID); err != nil {
+		logger.Error("failed to update product image urls", zap.Error(err))
+	}
+
+	logger.Info("product image deleted")
+	return nil
+}
+
+// OpenImage opens a stored image for serving
+func (s *ProductImageService) OpenImage(ctx context.Context, key string) (io.ReadCloser, models.BlobInfo, error) {
+	return s.store.Open(ctx, key)
+}
+
+// syncProductImageURLs points the product's image and thumbnail URLs at its first uploaded image. Products without
+// uploaded images keep whatever URLs they were given.
+func (s *ProductImageService) syncProductImageURLs(ctx context.Context, productID uuid.UUID) error {
+	images, err := s.db.GetProductImages(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	urls := s.imageURLs(images[0])
+	return s.db.SetProductImageURLs(ctx, database.SetProductImageURLsParams{
+		ID:           productID,
+		ImageUrl:     models.StringToNullString(urls["large"]),
+		ThumbnailUrl: models.StringToNullString(urls["thumbnail"]),
+		UpdatedAt:    time.Now(),
+	})
+}
+
+func (s *ProductImageService) putImage(ctx context.Context, key string, img image.Image, format string) error {
+	var buf bytes.Buffer
+	var err error
+	contentType := "image/jpeg"
+	if format == "png" {
+		contentType = "image/png"
+		err = png.Encode(&buf, img)
+	} else {
+		// JPEG has no transparency, so transparent GIF pixels are put on white instead of turning black
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		err = jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return s.store.Put(ctx, key, &buf, contentType)
+}
+
+func (s *ProductImageService) deleteBlobs(ctx context.Context, logger *zap.Logger, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			logger.Warn("failed to delete stored image", zap.Error(err), zap.String("key", key))
+		}
+	}
+}
+
+// imageKey names a stored size of an image. Keys contain the image ID, so the content behind a key never changes.
+func (s *ProductImageService) imageKey(record database.ProductImage, size string) string {
+	ext := "jpg"
+	if record.Format == "png" {
+		ext = "png"
+	}
+	return fmt.Sprintf("products/%s/%s/%s.%s", record.ProductID, record.ID, size, ext)
+}
+
+func (s *ProductImageService) imageURLs(record database.ProductImage) map[string]string {
+	urls := map[string]string{imageSizeOriginal: s.publicURL + "/" + s.imageKey(record, imageSizeOriginal)}
+	for _, size := range imageSizes {
+		urls[size.name] = s.publicURL + "/" + s.imageKey(record, size.name)
+	}
+	return urls
+}
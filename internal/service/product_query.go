@@ -0,0 +1,179 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/product"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
+	"github.com/google/uuid"
+)
+
+const productColumns = "p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold"
+
+// productSortSpec describes how a listing is ordered. The sort expression is selected as text to build cursors
+// and cast back to sqlType when continuing from one.
+type productSortSpec struct {
+	expr       string
+	sqlType    string
+	desc       bool
+	joinRating bool
+}
+
+var productSorts = map[product.Sort]productSortSpec{
+	product.SortNewest:    {expr: "p.created_at", sqlType: "timestamp", desc: true},
+	product.SortOldest:    {expr: "p.created_at", sqlType: "timestamp"},
+	product.SortPriceAsc:  {expr: "p.price", sqlType: "numeric"},
+	product.SortPriceDesc: {expr: "p.price", sqlType: "numeric", desc: true},
+	product.SortRating:    {expr: "coalesce(r.avg_rating, 0)", sqlType: "float8", desc: true, joinRating: true},
+}
+
+// productQuery builds a product listing query from optional filters, numbering placeholders as arguments are added
+type productQuery struct {
+	conditions []string
+	args       []interface{}
+	joinRating bool
+}
+
+func (q *productQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *productQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+func newProductQuery(filter models.ProductFilter) *productQuery {
+	q := &productQuery{}
+	q.where("p.is_active = true")
+
+	if filter.MinPrice != nil {
+		q.where("p.price >= " + q.arg(filter.MinPrice.Decimal()) + "::numeric")
+	}
+	if filter.MaxPrice != nil {
+		q.where("p.price <= " + q.arg(filter.MaxPrice.Decimal()) + "::numeric")
+	}
+	if len(filter.Brands) > 0 {
+		brands := make([]string, len(filter.Brands))
+		for i, brand := range filter.Brands {
+			brands[i] = q.arg(strings.ToLower(brand))
+		}
+		q.where("lower(p.brand) IN (" + strings.Join(brands, ", ") + ")")
+	}
+	if filter.InStock {
+		// Products with variants are in stock when one of their variants is
+		q.where("(EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active = true AND v.stock_quantity > 0)" +
+			" OR (p.stock_quantity > 0 AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active = true)))")
+	}
+	if filter.CategoryID != nil {
+		q.where("p.category_id = " + q.arg(*filter.CategoryID))
+	}
+
+	// Sorted so the same filters always produce the same SQL
+	keys := make([]string, 0, len(filter.Specs))
+	for key := range filter.Specs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// Containment lets a product match values stored as strings inside arrays as well, e.g. {"colors": ["Black"]}
+		alternatives := make([]string, 0, len(filter.Specs[key]))
+		for _, value := range filter.Specs[key] {
+			exact, _ := json.Marshal(map[string]string{key: value})
+			inArray, _ := json.Marshal(map[string][]string{key: {value}})
+			alternatives = append(alternatives,
+				"p.specifications @> "+q.arg(string(exact))+"::jsonb",
+				"p.specifications @> "+q.arg(string(inArray))+"::jsonb",
+			)
+		}
+		q.where("(" + strings.Join(alternatives, " OR ") + ")")
+	}
+
+	return q
+}
+
+func (q *productQuery) from() string {
+	from := "FROM products p"
+	if q.joinRating {
+		from += "\nLEFT JOIN (\n    SELECT product_id, AVG(rating)::float8 AS avg_rating FROM reviews WHERE deleted IS NOT true GROUP BY product_id\n) r ON r.product_id = p.id"
+	}
+	return from
+}
+
+func (q *productQuery) whereSQL() string {
+	return "WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// countSQL counts every product matching the filters, ignoring pagination
+func (q *productQuery) countSQL() (string, []interface{}) {
+	return "SELECT COUNT(*)\n" + q.from() + "\n" + q.whereSQL(), q.args
+}
+
+// listSQL selects a page of products after the cursor, with the sort key as the last column. It adds to the
+// query's conditions, so count before listing.
+func (q *productQuery) listSQL(spec productSortSpec, cursor *pagination.Cursor, cursorKey string, limit int) (string, []interface{}) {
+	q.joinRating = spec.joinRating
+
+	direction, comparison := "ASC", ">"
+	if spec.desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if cursor != nil {
+		key := q.arg(cursorKey) + "::" + spec.sqlType
+		id := q.arg(cursor.ID)
+		q.where(fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND p.id > %[4]s))", spec.expr, comparison, key, id))
+	}
+
+	query := fmt.Sprintf("SELECT %s, (%s)::text AS sort_key\n%s\n%s\nORDER BY %s %s, p.id\nLIMIT %s",
+		productColumns, spec.expr, q.from(), q.whereSQL(), spec.expr, direction, q.arg(limit))
+	return query, q.args
+}
+
+// productCursor splits a listing cursor into its sort and sort key, rejecting cursors from another sort order
+func productCursor(cursor pagination.Cursor, order product.Sort) (string, error) {
+	cursorOrder, key, ok := strings.Cut(cursor.Key, ":")
+	if !ok || product.Sort(cursorOrder) != order {
+		return "", pagination.ErrInvalidCursor
+	}
+	return key, nil
+}
+
+func productRowCursor(order product.Sort, sortKey string, id uuid.UUID) string {
+	return pagination.Cursor{Key: string(order) + ":" + sortKey, ID: id}.Encode()
+}
+
+type productScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProduct scans the product columns followed by the given extra destinations
+func scanProduct(row productScanner, extra ...interface{}) (database.Product, error) {
+	var i database.Product
+	dest := append([]interface{}{
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Brand,
+		&i.Sku,
+		&i.StockQuantity,
+		&i.CategoryID,
+		&i.ImageUrl,
+		&i.ThumbnailUrl,
+		&i.Specifications,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReorderThreshold,
+	}, extra...)
+	err := row.Scan(dest...)
+	return i, err
+}
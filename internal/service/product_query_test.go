@@ -0,0 +1,142 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CP-Payne/ecomstore/internal/domain/product"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"github.com/google/uuid"
+)
+
+func TestProductQueryPlaceholders(t *testing.T) {
+	minPrice := money.New(1000, money.USD)
+	filter := models.ProductFilter{
+		MinPrice: &minPrice,
+		Brands:   []string{"Acme", "Zeta"},
+		Specs: map[string][]string{
+			"size":  {"M"},
+			"color": {"Black", "Red"},
+		},
+	}
+	cursor := &pagination.Cursor{Key: "price_asc:10.00", ID: uuid.New()}
+
+	query, args := newProductQuery(filter).listSQL(productSorts[product.SortPriceAsc], cursor, "10.00", 20)
+
+	for _, fragment := range []string{
+		"p.price >= $1::numeric",
+		"lower(p.brand) IN ($2, $3)",
+		"(p.specifications @> $4::jsonb OR p.specifications @> $5::jsonb OR p.specifications @> $6::jsonb OR p.specifications @> $7::jsonb)",
+		"(p.specifications @> $8::jsonb OR p.specifications @> $9::jsonb)",
+		"(p.price > $10::numeric OR (p.price = $10::numeric AND p.id > $11))",
+		"LIMIT $12",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Errorf("expected query to contain %q\n%s", fragment, query)
+		}
+	}
+
+	expected := []interface{}{
+		"10.00", "acme", "zeta",
+		`{"color":"Black"}`, `{"color":["Black"]}`, `{"color":"Red"}`, `{"color":["Red"]}`,
+		`{"size":"M"}`, `{"size":["M"]}`,
+		"10.00", cursor.ID, 20,
+	}
+	if len(args) != len(expected) {
+		t.Fatalf("expected %d arguments but got %d: %v", len(expected), len(args), args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("expected argument $%d to be %v but got %v", i+1, expected[i], args[i])
+		}
+	}
+}
+
+func TestProductQuerySpecOrder(t *testing.T) {
+	filter := models.ProductFilter{
+		Specs: map[string][]string{
+			"size":     {"M"},
+			"color":    {"Black"},
+			"material": {"Cotton"},
+			"fit":      {"Slim"},
+		},
+	}
+
+	first, _ := newProductQuery(filter).countSQL()
+	for i := 0; i < 20; i++ {
+		if query, _ := newProductQuery(filter).countSQL(); query != first {
+			t.Fatalf("expected the same query for the same filters\n%s\n%s", first, query)
+		}
+	}
+
+	_, args := newProductQuery(filter).countSQL()
+	keys := []string{"color", "fit", "material", "size"}
+	for i, key := range keys {
+		if arg := args[i*2].(string); !strings.HasPrefix(arg, `{"`+key+`"`) {
+			t.Errorf("expected spec %d to be %s but got %s", i, key, arg)
+		}
+	}
+}
+
+func TestProductQueryCursorCondition(t *testing.T) {
+	tests := []struct {
+		sort      product.Sort
+		condition string
+		order     string
+	}{
+		{product.SortOldest, "(p.created_at > $1::timestamp OR (p.created_at = $1::timestamp AND p.id > $2))", "ORDER BY p.created_at ASC, p.id"},
+		{product.SortNewest, "(p.created_at < $1::timestamp OR (p.created_at = $1::timestamp AND p.id > $2))", "ORDER BY p.created_at DESC, p.id"},
+		{product.SortPriceAsc, "(p.price > $1::numeric OR (p.price = $1::numeric AND p.id > $2))", "ORDER BY p.price ASC, p.id"},
+		{product.SortPriceDesc, "(p.price < $1::numeric OR (p.price = $1::numeric AND p.id > $2))", "ORDER BY p.price DESC, p.id"},
+		{product.SortRating, "(coalesce(r.avg_rating, 0) < $1::float8 OR (coalesce(r.avg_rating, 0) = $1::float8 AND p.id > $2))", "ORDER BY coalesce(r.avg_rating, 0) DESC, p.id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			cursor := &pagination.Cursor{ID: uuid.New()}
+			query, _ := newProductQuery(models.ProductFilter{}).listSQL(productSorts[tt.sort], cursor, "key", 10)
+
+			if !strings.Contains(query, tt.condition) {
+				t.Errorf("expected query to contain %q\n%s", tt.condition, query)
+			}
+			if !strings.Contains(query, tt.order) {
+				t.Errorf("expected query to contain %q\n%s", tt.order, query)
+			}
+		})
+	}
+
+	query, args := newProductQuery(models.ProductFilter{}).listSQL(productSorts[product.SortOldest], nil, "", 10)
+	if strings.Contains(query, "p.id >") || len(args) != 1 {
+		t.Fatalf("expected no cursor condition on the first page\n%s", query)
+	}
+}
+
+func TestProductCursor(t *testing.T) {
+	tests := []struct {
+		key      string
+		order    product.Sort
+		expected string
+		err      error
+	}{
+		{"price_asc:10.00", product.SortPriceAsc, "10.00", nil},
+		{"newest:2024-01-02 10:30:00", product.SortNewest, "2024-01-02 10:30:00", nil},
+		{"price_asc:10.00", product.SortPriceDesc, "", pagination.ErrInvalidCursor},
+		{"newest:2024-01-02 10:30:00", product.SortOldest, "", pagination.ErrInvalidCursor},
+		{"10.00", product.SortPriceAsc, "", pagination.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"/"+string(tt.order), func(t *testing.T) {
+			result, err := productCursor(pagination.Cursor{Key: tt.key, ID: uuid.New()}, tt.order)
+
+			if err != tt.err {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+			if result != tt.expected {
+				t.Fatalf("expected %q but got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -2,25 +2,38 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/inventory"
+	"github.com/CP-Payne/ecomstore/internal/domain/product"
 	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type ProductService struct {
-	logger *zap.Logger
-	db     *database.Queries
+	logger        *zap.Logger
+	db            *database.Queries
+	sqlDB         *sql.DB
+	alerts        *StockAlertService
+	subscriptions *StockSubscriptionService
 }
 
-func NewProductService(db *database.Queries) *ProductService {
+func NewProductService(db *database.Queries, sqlDB *sql.DB, alerts *StockAlertService, subscriptions *StockSubscriptionService) *ProductService {
 	return &ProductService{
-		logger: config.GetLogger(),
-		db:     db,
+		logger:        config.GetLogger(),
+		db:            db,
+		sqlDB:         sqlDB,
+		alerts:        alerts,
+		subscriptions: subscriptions,
 	}
 }
 
@@ -60,29 +73,88 @@ func (s *ProductService) GetProduct(ctx context.Context, id uuid.UUID) (models.P
 		return models.Product{}, fmt.Errorf("failed to process database product: %w", err)
 	}
 
+	p.Variants, err = s.GetProductVariants(ctx, id, false)
+	if err != nil {
+		return models.Product{}, err
+	}
+
 	return p, nil
 }
 
-func (s *ProductService) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+// ListProducts returns a page of active products matching the filter in the given order
+func (s *ProductService) ListProducts(ctx context.Context, filter models.ProductFilter, sort product.Sort, page pagination.Params) (models.Page[models.Product], error) {
 	logger := s.logger.With(
-		zap.String("method", "GetAllProducts"),
+		zap.String("method", "ListProducts"),
+		zap.String("sort", string(sort)),
 	)
 
-	products, err := s.db.GetAllProducts(ctx)
+	spec, ok := productSorts[sort]
+	if !ok {
+		return models.Page[models.Product]{}, fmt.Errorf("unsupported product sort %q", sort)
+	}
+
+	var cursorKey string
+	if page.Cursor != nil {
+		var err error
+		cursorKey, err = productCursor(*page.Cursor, sort)
+		if err != nil {
+			return models.Page[models.Product]{}, fmt.Errorf("failed to list products: %w", err)
+		}
+	}
+
+	q := newProductQuery(filter)
+
+	var total *int64
+	if page.IncludeTotal {
+		countQuery, args := q.countSQL()
+		var count int64
+		if err := s.sqlDB.QueryRowContext(ctx, countQuery, args...).Scan(&count); err != nil {
+			logger.Error("failed to count products", zap.Error(err))
+			return models.Page[models.Product]{}, fmt.Errorf("failed to count products: %w", err)
+		}
+		total = &count
+	}
+
+	// Fetch one extra row to find out whether there is a next page
+	listQuery, args := q.listSQL(spec, page.Cursor, cursorKey, page.Limit+1)
+	rows, err := s.sqlDB.QueryContext(ctx, listQuery, args...)
 	if err != nil {
 		logger.Error("failed to retrieve products", zap.Error(err))
-		return nil, fmt.Errorf("failed to retrieve products: %w", err)
+		return models.Page[models.Product]{}, fmt.Errorf("failed to retrieve products: %w", err)
 	}
+	defer rows.Close()
 
-	pl, ok := models.DatabaseProductsToProducts(products, false).([]models.Product)
-	if !ok {
-		logger.Error("failed to convert database products into product model")
-		return []models.Product{}, fmt.Errorf("failed to process database products: %w", err)
+	var products []database.Product
+	var sortKeys []string
+	for rows.Next() {
+		var sortKey string
+		p, err := scanProduct(rows, &sortKey)
+		if err != nil {
+			logger.Error("failed to scan product", zap.Error(err))
+			return models.Page[models.Product]{}, fmt.Errorf("failed to retrieve products: %w", err)
+		}
+		products = append(products, p)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("failed to iterate products", zap.Error(err))
+		return models.Page[models.Product]{}, fmt.Errorf("failed to retrieve products: %w", err)
+	}
+
+	var nextCursor string
+	if len(products) > page.Limit {
+		products = products[:page.Limit]
+		nextCursor = productRowCursor(sort, sortKeys[page.Limit-1], products[page.Limit-1].ID)
 	}
 
-	return pl, nil
+	return models.Page[models.Product]{
+		Items:      models.DatabaseProductsToProducts(products, false).([]models.Product),
+		NextCursor: nextCursor,
+		Total:      total,
+	}, nil
 }
 
+// GetProductCategories returns the category tree, ordered by sort order and name within each level
 func (s *ProductService) GetProductCategories(ctx context.Context) ([]models.Category, error) {
 	logger := s.logger.With(
 		zap.String("method", "GetProductCategories"),
@@ -92,50 +164,441 @@ func (s *ProductService) GetProductCategories(ctx context.Context) ([]models.Cat
 		logger.Error("failed to retrieve product categories", zap.Error(err))
 		return nil, fmt.Errorf("failed to retrieve product categories: %w", err)
 	}
-	return models.DatabaseCategoriesToCategories(categories), nil
+	return models.CategoryTree(models.DatabaseCategoriesToCategories(categories)), nil
 }
 
-func (s *ProductService) GetProductsByCategory(ctx context.Context, categoryID uuid.UUID) ([]models.Product, error) {
+// GetProductsByCategory returns a page of the category's active products ordered by creation time, optionally
+// including the products of all its descendant categories
+func (s *ProductService) GetProductsByCategory(ctx context.Context, categoryID uuid.UUID, includeDescendants bool, page pagination.Params) (models.Page[models.Product], error) {
 	logger := s.logger.With(
 		zap.String("method", "GetProductsByCategory"),
 		zap.String("categoryID", categoryID.String()),
 	)
 
-	products, err := s.db.GetProductsByCategory(ctx, categoryID)
+	after, afterID, err := timeCursor(page.Cursor)
+	if err != nil {
+		return models.Page[models.Product]{}, fmt.Errorf("failed to retrieve products by category: %w", err)
+	}
+
+	products, err := s.db.GetProductsByCategory(ctx, database.GetProductsByCategoryParams{
+		CategoryID:         categoryID,
+		IncludeDescendants: includeDescendants,
+		AfterCreatedAt:     after,
+		AfterID:            afterID,
+		RowLimit:           int32(page.Limit + 1),
+	})
+	if err != nil {
+		logger.Error("failed to retrieve products by category", zap.Error(err))
+		return models.Page[models.Product]{}, fmt.Errorf("failed to retrieve products by category: %w", err)
+	}
+
+	result := productPage(products, page.Limit)
+
+	if page.IncludeTotal {
+		total, err := s.db.GetTotalProductsByCategory(ctx, database.GetTotalProductsByCategoryParams{
+			CategoryID:         categoryID,
+			IncludeDescendants: includeDescendants,
+		})
+		if err != nil {
+			logger.Error("failed to count products by category", zap.Error(err))
+			return models.Page[models.Product]{}, fmt.Errorf("failed to count products by category: %w", err)
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
+// GetProductWithMetadata retrieves a product regardless of whether it is archived
+func (s *ProductService) GetProductWithMetadata(ctx context.Context, id uuid.UUID) (models.ProductWithMetadata, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetProductWithMetadata"),
+		zap.String("productID", id.String()),
+	)
+
+	product, err := s.db.GetProductAnyStatus(ctx, id)
 	if err != nil {
 		if apperrors.IsNoRowsError(err) {
-			logger.Info("attempted to find products for category that does not exist", zap.Error(err))
-			return []models.Product{}, fmt.Errorf("failed to retrieve products by category: %w", apperrors.ErrNotFound)
+			logger.Info("product not found")
+			return models.ProductWithMetadata{}, fmt.Errorf("failed to retrieve product: %w", apperrors.ErrNotFound)
 		}
+		logger.Error("failed to retrieve product", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to retrieve product: %w", err)
+	}
 
-		logger.Error("failed to retrieve products by category", zap.Error(err))
-		return []models.Product{}, fmt.Errorf("failed to retrieve products by category: %w", err)
+	p := models.DatabaseProductToProduct(product, true).(models.ProductWithMetadata)
+	p.Variants, err = s.GetProductVariants(ctx, id, true)
+	if err != nil {
+		return models.ProductWithMetadata{}, err
 	}
 
-	pl, ok := models.DatabaseProductsToProducts(products, false).([]models.Product)
-	if !ok {
+	return p, nil
+}
+
+// GetAllProductsWithMetadata retrieves all products, including archived ones
+func (s *ProductService) GetAllProductsWithMetadata(ctx context.Context) ([]models.ProductWithMetadata, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetAllProductsWithMetadata"),
+	)
+
+	products, err := s.db.ListAllProducts(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve products", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve products: %w", err)
+	}
+
+	return models.DatabaseProductsToProducts(products, true).([]models.ProductWithMetadata), nil
+}
+
+func (s *ProductService) CreateProduct(ctx context.Context, params models.ProductParams) (models.ProductWithMetadata, error) {
+	logger := s.logger.With(
+		zap.String("method", "CreateProduct"),
+		zap.String("sku", params.Sku),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	now := time.Now()
+
+	product, err := qtx.CreateProduct(ctx, database.CreateProductParams{
+		ID:               uuid.New(),
+		Name:             params.Name,
+		Description:      models.StringToNullString(params.Description),
+		Price:            params.Price.Decimal(),
+		Brand:            models.StringToNullString(params.Brand),
+		Sku:              params.Sku,
+		StockQuantity:    int32(params.Stock),
+		CategoryID:       params.CategoryID,
+		ImageUrl:         models.StringToNullString(params.ImageURL),
+		ThumbnailUrl:     models.StringToNullString(params.ThumbnailURL),
+		Specifications:   models.RawMessageToNullRawMessage(params.Specifications),
+		IsActive:         true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		ReorderThreshold: int32(params.ReorderThreshold),
+	})
+	if err != nil {
+		if appErr := productWriteError(err); appErr != nil {
+			logger.Info("product rejected by constraint", zap.Error(err))
+			return models.ProductWithMetadata{}, fmt.Errorf("failed to create product: %w", appErr)
+		}
+		logger.Error("failed to create product", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to create product: %w", err)
+	}
+
+	err = recordStockMovement(ctx, qtx, database.CreateStockMovementParams{
+		ProductID: product.ID,
+		Kind:      string(inventory.MovementRestock),
+		Quantity:  product.StockQuantity,
+		Reason:    models.StringToNullString("initial stock"),
+		CreatedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to record initial stock", zap.Error(err))
+		return models.ProductWithMetadata{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.alerts.queueCheck(product.ID, nil)
+
+	logger.Info("product created", zap.String("productID", product.ID.String()))
+	return models.DatabaseProductToProduct(product, true).(models.ProductWithMetadata), nil
+}
+
+// UpdateProduct replaces all writable fields of a product, archived products can be updated as well
+func (s *ProductService) UpdateProduct(ctx context.Context, id uuid.UUID, params models.ProductParams) (models.ProductWithMetadata, error) {
+	logger := s.logger.With(
+		zap.String("method", "UpdateProduct"),
+		zap.String("productID", id.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
 
-		logger.Error("failed to convert database products into products model")
-		return []models.Product{}, fmt.Errorf("failed to process database products: %w", err)
+	// The previous stock is locked so the change recorded in the ledger is exact
+	previousStock, err := qtx.LockProductStock(ctx, id)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to update product that does not exist")
+			return models.ProductWithMetadata{}, fmt.Errorf("failed to update product: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to lock product stock", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to update product: %w", err)
 	}
-	return pl, nil
+
+	now := time.Now()
+
+	product, err := qtx.UpdateProduct(ctx, database.UpdateProductParams{
+		ID:               id,
+		Name:             params.Name,
+		Description:      models.StringToNullString(params.Description),
+		Price:            params.Price.Decimal(),
+		Brand:            models.StringToNullString(params.Brand),
+		Sku:              params.Sku,
+		StockQuantity:    int32(params.Stock),
+		CategoryID:       params.CategoryID,
+		ImageUrl:         models.StringToNullString(params.ImageURL),
+		ThumbnailUrl:     models.StringToNullString(params.ThumbnailURL),
+		Specifications:   models.RawMessageToNullRawMessage(params.Specifications),
+		UpdatedAt:        now,
+		ReorderThreshold: int32(params.ReorderThreshold),
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to update product that does not exist")
+			return models.ProductWithMetadata{}, fmt.Errorf("failed to update product: %w", apperrors.ErrNotFound)
+		}
+		if appErr := productWriteError(err); appErr != nil {
+			logger.Info("product rejected by constraint", zap.Error(err))
+			return models.ProductWithMetadata{}, fmt.Errorf("failed to update product: %w", appErr)
+		}
+		logger.Error("failed to update product", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	err = recordStockMovement(ctx, qtx, database.CreateStockMovementParams{
+		ProductID: id,
+		Kind:      string(inventory.MovementAdjustment),
+		Quantity:  product.StockQuantity - previousStock,
+		Reason:    models.StringToNullString("product updated"),
+		CreatedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to record stock change", zap.Error(err))
+		return models.ProductWithMetadata{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.alerts.queueCheck(id, nil)
+
+	logger.Info("product updated")
+	return models.DatabaseProductToProduct(product, true).(models.ProductWithMetadata), nil
 }
 
-func (s *ProductService) UpdateStock(ctx context.Context, productID uuid.UUID, reduceBy int) error {
+// SetProductActive archives or restores a product. Archived products are hidden from the public catalogue
+// but remain referenced by existing orders.
+func (s *ProductService) SetProductActive(ctx context.Context, id uuid.UUID, active bool) (models.ProductWithMetadata, error) {
 	logger := s.logger.With(
-		zap.String("method", "UpdateStock"),
-		zap.String("productID", productID.String()),
-		zap.Int("amount", reduceBy),
+		zap.String("method", "SetProductActive"),
+		zap.String("productID", id.String()),
+		zap.Bool("active", active),
 	)
-	err := s.db.UpdateStock(ctx, database.UpdateStockParams{
-		ID:            productID,
-		StockQuantity: int32(reduceBy),
+
+	product, err := s.db.SetProductActive(ctx, database.SetProductActiveParams{
+		ID:        id,
+		IsActive:  active,
+		UpdatedAt: time.Now(),
 	})
 	if err != nil {
-		logger.Error("failed to update product stock")
-		return fmt.Errorf("failed to update product's stock: %w", err)
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to change status of product that does not exist")
+			return models.ProductWithMetadata{}, fmt.Errorf("failed to update product status: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to update product status", zap.Error(err))
+		return models.ProductWithMetadata{}, fmt.Errorf("failed to update product status: %w", err)
+	}
+
+	logger.Info("product status updated")
+	return models.DatabaseProductToProduct(product, true).(models.ProductWithMetadata), nil
+}
+
+const (
+	// Ranks of both search modes lie in [0, 1], so the first page starts below this rank
+	searchFirstPageRank = 2
+	// Minimum word similarity between the query and a product's name or brand for fuzzy matches
+	searchMinSimilarity = 0.3
+
+	searchCursorFullText = "t"
+	searchCursorFuzzy    = "f"
+)
+
+// SearchProducts runs a ranked full-text search with prefix matching. When nothing matches, it falls back to a
+// trigram similarity search on the raw text so that misspelled queries still find products.
+func (s *ProductService) SearchProducts(ctx context.Context, query product.SearchQuery, text string, page pagination.Params) (models.ProductSearchPage, error) {
+	logger := s.logger.With(
+		zap.String("method", "SearchProducts"),
+		zap.String("query", string(query)),
+	)
+
+	afterRank := float32(searchFirstPageRank)
+	afterID := uuid.Nil
+	mode := searchCursorFullText
+
+	if page.Cursor != nil {
+		var err error
+		mode, afterRank, err = parseSearchCursor(*page.Cursor)
+		if err != nil {
+			return models.ProductSearchPage{}, fmt.Errorf("failed to search products: %w", err)
+		}
+		afterID = page.Cursor.ID
+	}
+
+	var rows []database.SearchProductsRow
+	if mode == searchCursorFullText {
+		var err error
+		rows, err = s.db.SearchProducts(ctx, database.SearchProductsParams{
+			Query:     string(query),
+			AfterRank: afterRank,
+			AfterID:   afterID,
+			RowLimit:  int32(page.Limit + 1),
+		})
+		if err != nil {
+			logger.Error("failed to search products", zap.Error(err))
+			return models.ProductSearchPage{}, fmt.Errorf("failed to search products: %w", err)
+		}
+
+		// Only fall back on the first page, later pages keep the mode of the first one
+		if len(rows) == 0 && page.Cursor == nil {
+			mode = searchCursorFuzzy
+		}
+	}
+
+	if mode == searchCursorFuzzy {
+		fuzzyRows, err := s.searchProductsFuzzy(ctx, database.SearchProductsFuzzyParams{
+			Query:     text,
+			AfterRank: afterRank,
+			AfterID:   afterID,
+			RowLimit:  int32(page.Limit + 1),
+		})
+		if err != nil {
+			logger.Error("failed to run fuzzy product search", zap.Error(err))
+			return models.ProductSearchPage{}, fmt.Errorf("failed to search products: %w", err)
+		}
+		rows = make([]database.SearchProductsRow, len(fuzzyRows))
+		for i, row := range fuzzyRows {
+			rows[i] = database.SearchProductsRow(row)
+		}
+	}
+
+	var nextCursor string
+	if len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+		last := rows[page.Limit-1]
+		nextCursor = pagination.Cursor{
+			Key: mode + ":" + strconv.FormatFloat(float64(last.Rank), 'g', -1, 32),
+			ID:  last.ID,
+		}.Encode()
+	}
+
+	results := make([]models.ProductSearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = models.DatabaseSearchRowToSearchResult(row)
+	}
+
+	return models.ProductSearchPage{
+		Page: models.Page[models.ProductSearchResult]{
+			Items:      results,
+			NextCursor: nextCursor,
+		},
+		Fuzzy: mode == searchCursorFuzzy,
+	}, nil
+}
+
+// searchProductsFuzzy runs the trigram search in its own transaction, so the word similarity threshold that its <%
+// operator matches against only applies to this search
+func (s *ProductService) searchProductsFuzzy(ctx context.Context, params database.SearchProductsFuzzyParams) ([]database.SearchProductsFuzzyRow, error) {
+	tx, err := s.sqlDB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	if err := qtx.SetWordSimilarityThreshold(ctx, searchMinSimilarity); err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %w", err)
+	}
+
+	rows, err := qtx.SearchProductsFuzzy(ctx, params)
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Info("product stock successfully updated")
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return rows, nil
+}
+
+// parseSearchCursor splits a search cursor key into the search mode and the rank of the last result
+func parseSearchCursor(cursor pagination.Cursor) (string, float32, error) {
+	mode, strRank, ok := strings.Cut(cursor.Key, ":")
+	if !ok || (mode != searchCursorFullText && mode != searchCursorFuzzy) {
+		return "", 0, pagination.ErrInvalidCursor
+	}
+
+	rank, err := strconv.ParseFloat(strRank, 32)
+	if err != nil {
+		return "", 0, pagination.ErrInvalidCursor
+	}
+	return mode, float32(rank), nil
+}
+
+// timeCursor returns the position to continue a creation time ordered listing from, the zero values start at the beginning
+func timeCursor(cursor *pagination.Cursor) (time.Time, uuid.UUID, error) {
+	if cursor == nil {
+		return time.Time{}, uuid.Nil, nil
+	}
+
+	after, err := cursor.Time()
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return after, cursor.ID, nil
+}
+
+// productPage trims the extra row fetched to detect a next page and builds the page
+func productPage(products []database.Product, limit int) models.Page[models.Product] {
+	var nextCursor string
+	if len(products) > limit {
+		products = products[:limit]
+		last := products[limit-1]
+		nextCursor = pagination.TimeCursor(last.CreatedAt, last.ID).Encode()
+	}
+
+	return models.Page[models.Product]{
+		Items:      models.DatabaseProductsToProducts(products, false).([]models.Product),
+		NextCursor: nextCursor,
+	}
+}
+
+// productWriteError maps constraint violations on product writes to application errors, returning nil for any other error
+func productWriteError(err error) error {
+	switch {
+	case apperrors.IsUniqueViolation(err):
+		return apperrors.ErrConflict
+	case apperrors.IsForeignKeyViolation(err):
+		return apperrors.ErrInvalidRef
+	}
 	return nil
 }
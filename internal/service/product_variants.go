@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/inventory"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetProductVariants retrieves the variants of a product. Archived variants are only included when requested.
+func (s *ProductService) GetProductVariants(ctx context.Context, productID uuid.UUID, includeArchived bool) ([]models.ProductVariant, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetProductVariants"),
+		zap.String("productID", productID.String()),
+	)
+
+	var variants []database.ProductVariant
+	var err error
+	if includeArchived {
+		variants, err = s.db.ListProductVariants(ctx, productID)
+	} else {
+		variants, err = s.db.GetProductVariants(ctx, productID)
+	}
+	if err != nil {
+		logger.Error("failed to retrieve product variants", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve product variants: %w", err)
+	}
+
+	result, err := models.DatabaseVariantsToVariants(variants)
+	if err != nil {
+		logger.Error("failed to convert database variants", zap.Error(err))
+		return nil, fmt.Errorf("failed to process product variants: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *ProductService) CreateProductVariant(ctx context.Context, productID uuid.UUID, params models.ProductVariantParams) (models.ProductVariant, error) {
+	logger := s.logger.With(
+		zap.String("method", "CreateProductVariant"),
+		zap.String("productID", productID.String()),
+		zap.String("sku", params.Sku),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	now := time.Now()
+
+	variant, err := qtx.CreateProductVariant(ctx, database.CreateProductVariantParams{
+		ID:            uuid.New(),
+		ProductID:     productID,
+		Sku:           params.Sku,
+		Price:         params.Price.Decimal(),
+		StockQuantity: int32(params.Stock),
+		Attributes:    params.Attributes,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		if apperrors.IsForeignKeyViolation(err) {
+			logger.Info("attempted to add variant to product that does not exist")
+			return models.ProductVariant{}, fmt.Errorf("failed to create product variant: %w", apperrors.ErrNotFound)
+		}
+		if apperrors.IsUniqueViolation(err) {
+			logger.Info("variant sku already exists", zap.Error(err))
+			return models.ProductVariant{}, fmt.Errorf("failed to create product variant: %w", apperrors.ErrConflict)
+		}
+		logger.Error("failed to create product variant", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to create product variant: %w", err)
+	}
+
+	err = recordStockMovement(ctx, qtx, database.CreateStockMovementParams{
+		ProductID: productID,
+		VariantID: uuid.NullUUID{UUID: variant.ID, Valid: true},
+		Kind:      string(inventory.MovementRestock),
+		Quantity:  variant.StockQuantity,
+		Reason:    models.StringToNullString("initial stock"),
+		CreatedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to record initial stock", zap.Error(err))
+		return models.ProductVariant{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.alerts.queueCheck(productID, &variant.ID)
+
+	logger.Info("product variant created", zap.String("variantID", variant.ID.String()))
+	return models.DatabaseVariantToVariant(variant)
+}
+
+// UpdateProductVariant replaces all writable fields of a variant, archived variants can be updated as well
+func (s *ProductService) UpdateProductVariant(ctx context.Context, productID, variantID uuid.UUID, params models.ProductVariantParams) (models.ProductVariant, error) {
+	logger := s.logger.With(
+		zap.String("method", "UpdateProductVariant"),
+		zap.String("productID", productID.String()),
+		zap.String("variantID", variantID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	// The previous stock is locked so the change recorded in the ledger is exact. A variant of another product
+	// is locked here but not updated below, which rolls back and reports it as not found.
+	previousStock, err := qtx.LockVariantStock(ctx, variantID)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to update variant that does not exist")
+			return models.ProductVariant{}, fmt.Errorf("failed to update product variant: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to lock variant stock", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to update product variant: %w", err)
+	}
+
+	now := time.Now()
+
+	variant, err := qtx.UpdateProductVariant(ctx, database.UpdateProductVariantParams{
+		ID:            variantID,
+		ProductID:     productID,
+		Sku:           params.Sku,
+		Price:         params.Price.Decimal(),
+		StockQuantity: int32(params.Stock),
+		Attributes:    params.Attributes,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to update variant that does not exist")
+			return models.ProductVariant{}, fmt.Errorf("failed to update product variant: %w", apperrors.ErrNotFound)
+		}
+		if apperrors.IsUniqueViolation(err) {
+			logger.Info("variant sku already exists", zap.Error(err))
+			return models.ProductVariant{}, fmt.Errorf("failed to update product variant: %w", apperrors.ErrConflict)
+		}
+		logger.Error("failed to update product variant", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to update product variant: %w", err)
+	}
+
+	err = recordStockMovement(ctx, qtx, database.CreateStockMovementParams{
+		ProductID: productID,
+		VariantID: uuid.NullUUID{UUID: variantID, Valid: true},
+		Kind:      string(inventory.MovementAdjustment),
+		Quantity:  variant.StockQuantity - previousStock,
+		Reason:    models.StringToNullString("variant updated"),
+		CreatedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to record stock change", zap.Error(err))
+		return models.ProductVariant{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.alerts.queueCheck(productID, &variantID)
+
+	logger.Info("product variant updated")
+	return models.DatabaseVariantToVariant(variant)
+}
+
+// SetProductVariantActive archives or restores a variant. Archived variants cannot be added to carts or bought.
+func (s *ProductService) SetProductVariantActive(ctx context.Context, productID, variantID uuid.UUID, active bool) (models.ProductVariant, error) {
+	logger := s.logger.With(
+		zap.String("method", "SetProductVariantActive"),
+		zap.String("productID", productID.String()),
+		zap.String("variantID", variantID.String()),
+		zap.Bool("active", active),
+	)
+
+	variant, err := s.db.SetProductVariantActive(ctx, database.SetProductVariantActiveParams{
+		ID:        variantID,
+		ProductID: productID,
+		IsActive:  active,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("attempted to change status of variant that does not exist")
+			return models.ProductVariant{}, fmt.Errorf("failed to update product variant status: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to update product variant status", zap.Error(err))
+		return models.ProductVariant{}, fmt.Errorf("failed to update product variant status: %w", err)
+	}
+
+	logger.Info("product variant status updated")
+	return models.DatabaseVariantToVariant(variant)
+}
+
+// GetPurchasableItem prices a quantity of a product, or of one of its variants, and checks that it is in stock.
+// Products with active variants can only be bought through a variant, which must be active and belong to the product.
+func (s *ProductService) GetPurchasableItem(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, quantity int) (models.CartItem, error) {
+	logger := s.logger.With(
+		zap.String("method", "GetPurchasableItem"),
+		zap.String("productID", productID.String()),
+	)
+
+	product, err := s.GetProduct(ctx, productID)
+	if err != nil {
+		return models.CartItem{}, err
+	}
+
+	item := models.CartItem{
+		ProductID: product.ID,
+		Quantity:  quantity,
+		Name:      product.Name,
+		Price:     product.Price,
+	}
+
+	if variantID == nil {
+		hasVariants, err := s.db.ProductHasVariants(ctx, productID)
+		if err != nil {
+			logger.Error("failed to check if product has variants", zap.Error(err))
+			return models.CartItem{}, fmt.Errorf("failed to check product variants: %w", err)
+		}
+		if hasVariants {
+			return models.CartItem{}, apperrors.ErrVariantNeeded
+		}
+		if quantity > product.Stock {
+			return models.CartItem{}, apperrors.ErrOutOfStock
+		}
+		return item, nil
+	}
+
+	record, err := s.db.GetProductVariant(ctx, database.GetProductVariantParams{
+		ID:        *variantID,
+		ProductID: productID,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.CartItem{}, fmt.Errorf("variant %s of product %s: %w", variantID, productID, apperrors.ErrInvalidRef)
+		}
+		logger.Error("failed to retrieve product variant", zap.Error(err), zap.String("variantID", variantID.String()))
+		return models.CartItem{}, fmt.Errorf("failed to retrieve product variant: %w", err)
+	}
+	if !record.IsActive {
+		return models.CartItem{}, fmt.Errorf("variant %s is archived: %w", variantID, apperrors.ErrInvalidRef)
+	}
+
+	variant, err := models.DatabaseVariantToVariant(record)
+	if err != nil {
+		logger.Error("failed to convert database variant", zap.Error(err))
+		return models.CartItem{}, fmt.Errorf("failed to process product variant: %w", err)
+	}
+	if quantity > variant.Stock {
+		return models.CartItem{}, apperrors.ErrOutOfStock
+	}
+
+	item.VariantID = &variant.ID
+	item.Price = variant.Price
+	item.Sku = variant.Sku
+	item.Attributes = variant.Attributes
+	return item, nil
+}
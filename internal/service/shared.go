@@ -7,10 +7,6 @@ import (
 	"github.com/google/uuid"
 )
 
-func floatToString(f float32) string {
-	return fmt.Sprintf("%.2f", f)
-}
-
 func intToString(i int) string {
 	return fmt.Sprintf("%d", i)
 }
@@ -28,3 +24,16 @@ func nullUuidToUuid(nuuid uuid.NullUUID) *uuid.UUID {
 	}
 	return nil
 }
+
+func uuidToNullUuid(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+// cartLine identifies an item in a cart, comparable so it can be used as a map key
+type cartLine struct {
+	productID uuid.UUID
+	variantID uuid.NullUUID
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// stockAlertQueueSize bounds the stock changes waiting to be checked. Changes beyond it are left to the next
+// scheduled check.
+const stockAlertQueueSize = 256
+
+// stockItem is a product's own stock or, with a variant, the stock of that variant
+type stockItem struct {
+	productID uuid.UUID
+	variantID *uuid.UUID
+}
+
+// StockAlertService raises an alert when a product or variant reaches its reorder threshold or runs out. Each
+// alert stays open until the stock recovers, so the notifier is called once per shortage rather than on every
+// check.
+type StockAlertService struct {
+	logger   *zap.Logger
+	db       *database.Queries
+	notifier models.StockNotifier
+	queue    chan stockItem
+	sweep    chan struct{}
+}
+
+func NewStockAlertService(db *database.Queries, notifier models.StockNotifier) *StockAlertService {
+	return &StockAlertService{
+		logger:   config.GetLogger(),
+		db:       db,
+		notifier: notifier,
+		queue:    make(chan stockItem, stockAlertQueueSize),
+		sweep:    make(chan struct{}, 1),
+	}
+}
+
+// Run checks stock changes as they are queued and every stock level once per interval, until the context is
+// cancelled. Checks run one at a time so the same shortage is never alerted twice.
+func (s *StockAlertService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.CheckAll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-s.queue:
+			if err := s.check(ctx, item); err != nil {
+				s.logger.Error("failed to check stock level", zap.Error(err), zap.String("productID", item.productID.String()))
+			}
+		case <-s.sweep:
+			s.CheckAll(ctx)
+		case <-ticker.C:
+			s.CheckAll(ctx)
+		}
+	}
+}
+
+// queueCheck schedules a check of stock that just changed without waiting for it
+func (s *StockAlertService) queueCheck(productID uuid.UUID, variantID *uuid.UUID) {
+	select {
+	case s.queue <- stockItem{productID: productID, variantID: variantID}:
+	default:
+		s.logger.Warn("stock alert queue is full, leaving check to the next scheduled run", zap.String("productID", productID.String()))
+	}
+}
+
+// queueCheckAll schedules a check of every stock level, for changes too large to queue one by one
+func (s *StockAlertService) queueCheckAll() {
+	select {
+	case s.sweep <- struct{}{}:
+	default:
+	}
+}
+
+// CheckAll checks every product and variant that is low or out of stock or has an open alert, returning the
+// number of checks that failed
+func (s *StockAlertService) CheckAll(ctx context.Context) (int, error) {
+	logger := s.logger.With(zap.String("method", "CheckAll"))
+
+	candidates, err := s.db.GetStockAlertCandidates(ctx)
+	if err != nil {
+		logger.Error("failed to retrieve stock alert candidates", zap.Error(err))
+		return 0, fmt.Errorf("failed to retrieve stock alert candidates: %w", err)
+	}
+
+	failed := 0
+	for _, candidate := range candidates {
+		item := stockItem{productID: candidate.ProductID, variantID: nullUuidToUuid(candidate.VariantID)}
+		if err := s.check(ctx, item); err != nil {
+			logger.Error("failed to check stock level", zap.Error(err), zap.String("productID", item.productID.String()))
+			failed++
+		}
+	}
+
+	return failed, nil
+}
+
+// check compares an item's stock with its reorder threshold, opening an alert when it reached a new level and
+// resolving the open one when it no longer applies. Alerts whose notification failed are retried.
+func (s *StockAlertService) check(ctx context.Context, item stockItem) error {
+	var level database.GetProductStockLevelRow
+	var err error
+	if item.variantID != nil {
+		var variantLevel database.GetVariantStockLevelRow
+		variantLevel, err = s.db.GetVariantStockLevel(ctx, *item.variantID)
+		level = database.GetProductStockLevelRow(variantLevel)
+	} else {
+		level, err = s.db.GetProductStockLevel(ctx, item.productID)
+	}
+	if err != nil && !apperrors.IsNoRowsError(err) {
+		return fmt.Errorf("failed to retrieve stock level: %w", err)
+	}
+
+	// Archived items and products sold through their variants are not tracked
+	var alertLevel string
+	switch {
+	case err != nil || !level.Tracked:
+	case level.StockQuantity <= 0:
+		alertLevel = models.StockLevelOut
+	case level.StockQuantity <= level.ReorderThreshold:
+		alertLevel = models.StockLevelLow
+	}
+
+	open, err := s.db.GetOpenStockAlert(ctx, database.GetOpenStockAlertParams{
+		ProductID: item.productID,
+		VariantID: uuidToNullUuid(item.variantID),
+	})
+	hasOpen := err == nil
+	if err != nil && !apperrors.IsNoRowsError(err) {
+		return fmt.Errorf("failed to retrieve open stock alert: %w", err)
+	}
+
+	now := time.Now()
+
+	if hasOpen && open.Level == alertLevel {
+		if open.NotifiedAt.Valid {
+			return nil
+		}
+		return s.notify(ctx, stockAlert(open, item, level))
+	}
+
+	if hasOpen {
+		err := s.db.ResolveStockAlert(ctx, database.ResolveStockAlertParams{
+			ID:         open.ID,
+			ResolvedAt: sql.NullTime{Time: now, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve stock alert: %w", err)
+		}
+		s.logger.Info("stock alert resolved", zap.String("productID", item.productID.String()), zap.String("level", open.Level))
+	}
+
+	if alertLevel == "" {
+		return nil
+	}
+
+	alert := database.StockAlert{
+		ID:               uuid.New(),
+		ProductID:        item.productID,
+		VariantID:        uuidToNullUuid(item.variantID),
+		Level:            alertLevel,
+		StockQuantity:    level.StockQuantity,
+		ReorderThreshold: level.ReorderThreshold,
+		CreatedAt:        now,
+	}
+	created, err := s.db.CreateStockAlert(ctx, database.CreateStockAlertParams{
+		ID:               alert.ID,
+		ProductID:        alert.ProductID,
+		VariantID:        alert.VariantID,
+		Level:            alert.Level,
+		StockQuantity:    alert.StockQuantity,
+		ReorderThreshold: alert.ReorderThreshold,
+		CreatedAt:        alert.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create stock alert: %w", err)
+	}
+	if created == 0 {
+		// Another process opened the alert first and notifies for it
+		return nil
+	}
+
+	return s.notify(ctx, stockAlert(alert, item, level))
+}
+
+func (s *StockAlertService) notify(ctx context.Context, alert models.StockAlert) error {
+	if err := s.notifier.Notify(ctx, alert); err != nil {
+		return fmt.Errorf("failed to send stock alert: %w", err)
+	}
+
+	err := s.db.SetStockAlertNotified(ctx, database.SetStockAlertNotifiedParams{
+		ID:         alert.ID,
+		NotifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark stock alert as sent: %w", err)
+	}
+
+	s.logger.Info("stock alert sent", zap.String("productID", alert.ProductID.String()), zap.String("level", alert.Level))
+	return nil
+}
+
+func stockAlert(alert database.StockAlert, item stockItem, level database.GetProductStockLevelRow) models.StockAlert {
+	return models.StockAlert{
+		ID:               alert.ID,
+		ProductID:        alert.ProductID,
+		VariantID:        item.variantID,
+		Sku:              level.Sku,
+		Name:             level.Name,
+		Level:            alert.Level,
+		Stock:            int(alert.StockQuantity),
+		ReorderThreshold: int(alert.ReorderThreshold),
+		CreatedAt:        alert.CreatedAt,
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/pagination"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recordStockMovement appends a movement to the stock ledger. It must run in the transaction that changes the
+// stock so the ledger never disagrees with it. Movements that do not change stock are not recorded.
+func recordStockMovement(ctx context.Context, qtx *database.Queries, movement database.CreateStockMovementParams) error {
+	if movement.Quantity == 0 {
+		return nil
+	}
+
+	if movement.ID == uuid.Nil {
+		movement.ID = uuid.New()
+	}
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = time.Now()
+	}
+
+	if err := qtx.CreateStockMovement(ctx, movement); err != nil {
+		return fmt.Errorf("failed to record stock movement of product %s: %w", movement.ProductID, err)
+	}
+	return nil
+}
+
+// AdjustStock changes the stock of a product or one of its variants by hand and records why. Adjustments that
+// would take the stock below zero are rejected with ErrOutOfStock.
+func (s *ProductService) AdjustStock(ctx context.Context, productID uuid.UUID, adjustment models.StockAdjustment) (models.StockLevel, error) {
+	logger := s.logger.With(
+		zap.String("method", "AdjustStock"),
+		zap.String("productID", productID.String()),
+		zap.String("kind", adjustment.Kind),
+		zap.Int("quantity", adjustment.Quantity),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.StockLevel{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	now := time.Now()
+
+	var stock int32
+	if adjustment.VariantID != nil {
+		stock, err = qtx.AdjustVariantStock(ctx, database.AdjustVariantStockParams{
+			Quantity:  int32(adjustment.Quantity),
+			UpdatedAt: now,
+			ID:        *adjustment.VariantID,
+			ProductID: productID,
+		})
+	} else {
+		stock, err = qtx.AdjustProductStock(ctx, database.AdjustProductStockParams{
+			Quantity:  int32(adjustment.Quantity),
+			UpdatedAt: now,
+			ID:        productID,
+		})
+	}
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.StockLevel{}, s.stockAdjustmentError(ctx, productID, adjustment.VariantID)
+		}
+		logger.Error("failed to adjust stock", zap.Error(err))
+		return models.StockLevel{}, fmt.Errorf("failed to adjust stock: %w", err)
+	}
+
+	movement := database.CreateStockMovementParams{
+		ID:        uuid.New(),
+		ProductID: productID,
+		VariantID: uuidToNullUuid(adjustment.VariantID),
+		Kind:      adjustment.Kind,
+		Quantity:  int32(adjustment.Quantity),
+		Reason:    models.StringToNullString(adjustment.Reason),
+		CreatedBy: uuid.NullUUID{UUID: adjustment.CreatedBy, Valid: adjustment.CreatedBy != uuid.Nil},
+		CreatedAt: now,
+	}
+	if err := recordStockMovement(ctx, qtx, movement); err != nil {
+		logger.Error("failed to record stock movement", zap.Error(err))
+		return models.StockLevel{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.StockLevel{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.alerts.queueCheck(productID, adjustment.VariantID)
+	if adjustment.Quantity > 0 && stock > 0 {
+		s.subscriptions.notifyBackInStockAsync(productID, adjustment.VariantID)
+	}
+
+	logger.Info("stock adjusted", zap.Int32("stock", stock))
+	return models.StockLevel{
+		ProductID: productID,
+		VariantID: adjustment.VariantID,
+		Stock:     int(stock),
+		Movement: models.StockMovement{
+			ID:        movement.ID,
+			ProductID: productID,
+			VariantID: adjustment.VariantID,
+			Kind:      adjustment.Kind,
+			Quantity:  adjustment.Quantity,
+			Reason:    adjustment.Reason,
+			CreatedBy: models.NullUUIDToUUID(movement.CreatedBy),
+			CreatedAt: now,
+		},
+	}, nil
+}
+
+// stockAdjustmentError tells apart an adjustment of a product or variant that does not exist from one that
+// would take its stock below zero, as neither updates a row
+func (s *ProductService) stockAdjustmentError(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID) error {
+	var err error
+	if variantID != nil {
+		_, err = s.db.GetProductVariant(ctx, database.GetProductVariantParams{ID: *variantID, ProductID: productID})
+	} else {
+		_, err = s.db.GetProductAnyStatus(ctx, productID)
+	}
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("failed to adjust stock: %w", apperrors.ErrNotFound)
+		}
+		return fmt.Errorf("failed to adjust stock: %w", err)
+	}
+	return fmt.Errorf("failed to adjust stock: %w", apperrors.ErrOutOfStock)
+}
+
+// GetStockMovements returns a page of a product's stock movements, newest first, optionally narrowed to one of
+// its variants
+func (s *ProductService) GetStockMovements(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID, page pagination.Params) (models.Page[models.StockMovement], error) {
+	logger := s.logger.With(
+		zap.String("method", "GetStockMovements"),
+		zap.String("productID", productID.String()),
+	)
+
+	exists, err := s.db.ProductExists(ctx, productID)
+	if err != nil {
+		logger.Error("failed to check product existence", zap.Error(err))
+		return models.Page[models.StockMovement]{}, fmt.Errorf("failed to check product existence: %w", err)
+	}
+	if !exists {
+		return models.Page[models.StockMovement]{}, fmt.Errorf("failed to retrieve stock movements: %w", apperrors.ErrNotFound)
+	}
+
+	before, beforeID, err := timeCursor(page.Cursor)
+	if err != nil {
+		return models.Page[models.StockMovement]{}, fmt.Errorf("failed to retrieve stock movements: %w", err)
+	}
+
+	movements, err := s.db.GetStockMovements(ctx, database.GetStockMovementsParams{
+		ProductID:       productID,
+		VariantID:       uuidToNullUuid(variantID),
+		HasCursor:       page.Cursor != nil,
+		BeforeCreatedAt: before,
+		BeforeID:        beforeID,
+		RowLimit:        int32(page.Limit + 1),
+	})
+	if err != nil {
+		logger.Error("failed to retrieve stock movements", zap.Error(err))
+		return models.Page[models.StockMovement]{}, fmt.Errorf("failed to retrieve stock movements: %w", err)
+	}
+
+	var result models.Page[models.StockMovement]
+	if len(movements) > page.Limit {
+		movements = movements[:page.Limit]
+		last := movements[page.Limit-1]
+		result.NextCursor = pagination.TimeCursor(last.CreatedAt, last.ID).Encode()
+	}
+	result.Items = make([]models.StockMovement, 0, len(movements))
+	for _, movement := range movements {
+		result.Items = append(result.Items, models.DatabaseMovementToMovement(movement))
+	}
+
+	if page.IncludeTotal {
+		total, err := s.db.GetTotalStockMovements(ctx, database.GetTotalStockMovementsParams{
+			ProductID: productID,
+			VariantID: uuidToNullUuid(variantID),
+		})
+		if err != nil {
+			logger.Error("failed to count stock movements", zap.Error(err))
+			return models.Page[models.StockMovement]{}, fmt.Errorf("failed to count stock movements: %w", err)
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
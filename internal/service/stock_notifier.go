@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"go.uber.org/zap"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// NewStockNotifier returns the stock alert notifier selected by the alert config
+func NewStockNotifier(aconf *config.AlertConfig, mailer models.Mailer) (models.StockNotifier, error) {
+	switch aconf.Driver {
+	case "log":
+		return &LogStockNotifier{logger: config.GetLogger()}, nil
+	case "email":
+		if aconf.EmailTo == "" {
+			return nil, fmt.Errorf("email stock alerts require STOCK_ALERT_EMAIL")
+		}
+		return &EmailStockNotifier{logger: config.GetLogger(), mailer: mailer, to: aconf.EmailTo}, nil
+	case "webhook":
+		if aconf.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook stock alerts require STOCK_ALERT_WEBHOOK_URL")
+		}
+		return &WebhookStockNotifier{
+			logger: config.GetLogger(),
+			client: &http.Client{Timeout: webhookTimeout},
+			url:    aconf.WebhookURL,
+			secret: aconf.WebhookSecret,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown stock alert driver %q", aconf.Driver)
+	}
+}
+
+// LogStockNotifier writes stock alerts to the application log
+type LogStockNotifier struct {
+	logger *zap.Logger
+}
+
+func (n *LogStockNotifier) Notify(ctx context.Context, alert models.StockAlert) error {
+	n.logger.Warn("stock alert",
+		zap.String("level", alert.Level),
+		zap.String("productID", alert.ProductID.String()),
+		zap.String("sku", alert.Sku),
+		zap.Int("stock", alert.Stock),
+		zap.Int("reorderThreshold", alert.ReorderThreshold),
+	)
+	return nil
+}
+
+// EmailStockNotifier emails stock alerts to a single address, such as a purchasing team's mailbox
+type EmailStockNotifier struct {
+	logger *zap.Logger
+	mailer models.Mailer
+	to     string
+}
+
+func (n *EmailStockNotifier) Notify(ctx context.Context, alert models.StockAlert) error {
+	subject := fmt.Sprintf("Low stock: %s (%s)", alert.Name, alert.Sku)
+	status := fmt.Sprintf("is down to %d, at or below its reorder threshold of %d", alert.Stock, alert.ReorderThreshold)
+	if alert.Level == models.StockLevelOut {
+		subject = fmt.Sprintf("Out of stock: %s (%s)", alert.Name, alert.Sku)
+		status = "has run out"
+	}
+
+	err := n.mailer.Send(ctx, &models.EmailMessage{
+		To:      n.to,
+		Subject: subject,
+		Body:    fmt.Sprintf("The stock of %s (SKU %s) %s.\n\nYou will not be alerted again until it has been restocked.\n", alert.Name, alert.Sku, status),
+	})
+	if err != nil {
+		n.logger.Error("failed to send stock alert email", zap.Error(err), zap.String("sku", alert.Sku))
+		return fmt.Errorf("failed to send stock alert email: %w", err)
+	}
+	return nil
+}
+
+// WebhookStockNotifier posts stock alerts as JSON to a URL. When a secret is configured the body is signed with
+// HMAC-SHA256 in the X-Signature header so the receiver can verify it came from the store.
+type WebhookStockNotifier struct {
+	logger *zap.Logger
+	client *http.Client
+	url    string
+	secret string
+}
+
+func (n *WebhookStockNotifier) Notify(ctx context.Context, alert models.StockAlert) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event": "stock." + alert.Level,
+		"alert": alert,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode stock alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create stock alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.logger.Error("failed to post stock alert", zap.Error(err), zap.String("sku", alert.Sku))
+		return fmt.Errorf("failed to post stock alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		n.logger.Error("stock alert webhook rejected alert", zap.Int("status", resp.StatusCode), zap.String("sku", alert.Sku))
+		return fmt.Errorf("failed to post stock alert: webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
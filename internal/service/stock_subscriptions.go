@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// backInStockTimeout bounds notifying the subscribers of a restocked item, which runs after the restock's
+// request has completed
+const backInStockTimeout = 5 * time.Minute
+
+type StockSubscriptionService struct {
+	logger *zap.Logger
+	db     *database.Queries
+	mailer models.Mailer
+	appURL string
+}
+
+func NewStockSubscriptionService(db *database.Queries, mailer models.Mailer, appURL string) *StockSubscriptionService {
+	return &StockSubscriptionService{
+		logger: config.GetLogger(),
+		db:     db,
+		mailer: mailer,
+		appURL: appURL,
+	}
+}
+
+// Subscribe asks for the user to be emailed when the product, or one of its variants, is restocked. It reports
+// false when the user is already waiting for the item.
+func (s *StockSubscriptionService) Subscribe(ctx context.Context, userID, productID uuid.UUID, variantID *uuid.UUID) (bool, error) {
+	logger := s.logger.With(
+		zap.String("method", "Subscribe"),
+		zap.String("userID", userID.String()),
+		zap.String("productID", productID.String()),
+	)
+
+	created, err := s.db.CreateStockSubscription(ctx, database.CreateStockSubscriptionParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("failed to create stock subscription", zap.Error(err))
+		return false, fmt.Errorf("failed to create stock subscription: %w", err)
+	}
+
+	if created > 0 {
+		logger.Info("stock subscription created")
+	}
+	return created > 0, nil
+}
+
+// NotifyBackInStock emails everyone waiting for the item if it is in stock, marking their subscriptions as
+// fulfilled. Subscriptions whose email could not be sent are kept for the next restock. It returns the number of
+// subscribers notified.
+func (s *StockSubscriptionService) NotifyBackInStock(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID) (int, error) {
+	logger := s.logger.With(
+		zap.String("method", "NotifyBackInStock"),
+		zap.String("productID", productID.String()),
+	)
+
+	var level database.GetProductStockLevelRow
+	var err error
+	if variantID != nil {
+		var variantLevel database.GetVariantStockLevelRow
+		variantLevel, err = s.db.GetVariantStockLevel(ctx, *variantID)
+		level = database.GetProductStockLevelRow(variantLevel)
+	} else {
+		level, err = s.db.GetProductStockLevel(ctx, productID)
+	}
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return 0, nil
+		}
+		logger.Error("failed to retrieve stock level", zap.Error(err))
+		return 0, fmt.Errorf("failed to retrieve stock level: %w", err)
+	}
+	if !level.Tracked || level.StockQuantity <= 0 {
+		return 0, nil
+	}
+
+	// Subscriptions are claimed before sending so concurrent restocks never email a subscriber twice
+	subscribers, err := s.db.ClaimStockSubscriptions(ctx, database.ClaimStockSubscriptionsParams{
+		ProductID:  productID,
+		VariantID:  uuidToNullUuid(variantID),
+		NotifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		logger.Error("failed to claim stock subscriptions", zap.Error(err))
+		return 0, fmt.Errorf("failed to claim stock subscriptions: %w", err)
+	}
+
+	productLink := fmt.Sprintf("%s/products/%s", s.appURL, productID)
+	notified := 0
+	for _, subscriber := range subscribers {
+		greeting := "Hi"
+		if subscriber.Name.Valid && subscriber.Name.String != "" {
+			greeting = "Hi " + subscriber.Name.String
+		}
+
+		err := s.mailer.Send(ctx, &models.EmailMessage{
+			To:      subscriber.Email,
+			Subject: fmt.Sprintf("%s is back in stock", level.Name),
+			Body: fmt.Sprintf("%s,\n\n%s (SKU %s) is back in stock. Stock is limited, so order soon to avoid missing out again.\n\n%s\n",
+				greeting, level.Name, level.Sku, productLink),
+		})
+		if err != nil {
+			logger.Error("failed to send back in stock email", zap.Error(err), zap.String("subscriptionID", subscriber.ID.String()))
+			if err := s.db.ReopenStockSubscription(ctx, subscriber.ID); err != nil {
+				logger.Error("failed to reopen stock subscription", zap.Error(err), zap.String("subscriptionID", subscriber.ID.String()))
+			}
+			continue
+		}
+		notified++
+	}
+
+	if notified > 0 {
+		logger.Info("notified subscribers of restock", zap.Int("subscribers", notified))
+	}
+	return notified, nil
+}
+
+// notifyBackInStockAsync notifies the item's subscribers without holding up the request that restocked it
+func (s *StockSubscriptionService) notifyBackInStockAsync(productID uuid.UUID, variantID *uuid.UUID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backInStockTimeout)
+		defer cancel()
+
+		s.NotifyBackInStock(ctx, productID, variantID)
+	}()
+}
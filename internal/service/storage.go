@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"go.uber.org/zap"
+)
+
+// NewBlobStore returns the blob storage implementation selected by the storage config
+func NewBlobStore(sconf *config.StorageConfig) (models.BlobStore, error) {
+	switch sconf.Driver {
+	case "local":
+		if err := os.MkdirAll(sconf.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+		return &LocalBlobStore{logger: config.GetLogger(), dir: sconf.Dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", sconf.Driver)
+	}
+}
+
+// LocalBlobStore keeps objects as files in a directory, the key being the file's path relative to it
+type LocalBlobStore struct {
+	logger *zap.Logger
+	dir    string
+}
+
+var errInvalidKey = errors.New("invalid blob key")
+
+// path maps a key to a file inside the store directory, rejecting keys that would escape it
+func (s *LocalBlobStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned != "/"+key || strings.Contains(key, "\\") {
+		return "", errInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleaned[1:])), nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partially written object
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	s.logger.Debug("blob stored", zap.String("key", key), zap.String("contentType", contentType))
+	return nil
+}
+
+// Open returns the object's content, the content type is derived from the key's extension
+func (s *LocalBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, models.BlobInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, models.BlobInfo{}, fmt.Errorf("failed to open blob: %w", apperrors.ErrNotFound)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, models.BlobInfo{}, fmt.Errorf("failed to open blob: %w", apperrors.ErrNotFound)
+		}
+		return nil, models.BlobInfo{}, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		f.Close()
+		return nil, models.BlobInfo{}, fmt.Errorf("failed to open blob: %w", apperrors.ErrNotFound)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return f, models.BlobInfo{
+		ContentType: contentType,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+	}, nil
+}
+
+// Delete removes the object, deleting a missing object is not an error
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
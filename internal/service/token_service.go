@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/hashing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TokenService struct {
+	logger *zap.Logger
+	db     *database.Queries
+	sqlDB  *sql.DB
+}
+
+func NewTokenService(db *database.Queries, sqlDB *sql.DB) *TokenService {
+	return &TokenService{
+		logger: config.GetLogger(),
+		db:     db,
+		sqlDB:  sqlDB,
+	}
+}
+
+// IssueTokens creates an access token and a refresh token that starts a new token family
+func (s *TokenService) IssueTokens(ctx context.Context, user models.User) (models.AuthTokens, error) {
+	logger := s.logger.With(
+		zap.String("method", "IssueTokens"),
+		zap.String("userID", user.ID.String()),
+	)
+
+	tokens, _, err := s.issueTokens(ctx, s.db, user.Email, user.ID, user.Role, uuid.New())
+	if err != nil {
+		logger.Error("failed to issue tokens", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to issue tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair. The presented refresh token is revoked
+// and replaced by the new one. Presenting a refresh token that was already rotated revokes the whole family.
+func (s *TokenService) RefreshTokens(ctx context.Context, refreshToken string) (models.AuthTokens, error) {
+	logger := s.logger.With(
+		zap.String("method", "RefreshTokens"),
+	)
+
+	now := time.Now()
+
+	// Start transaction
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	storedToken, err := qtx.GetRefreshTokenByHash(ctx, database.GetRefreshTokenByHashParams{
+		TokenHash: hashing.HashToken(refreshToken),
+		ExpiresAt: now,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("refresh token not found or expired")
+			return models.AuthTokens{}, fmt.Errorf("failed to refresh tokens: %w", apperrors.ErrInvalidToken)
+		}
+		logger.Error("failed to retrieve refresh token", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to retrieve refresh token: %w", err)
+	}
+
+	logger = logger.With(
+		zap.String("userID", storedToken.UserID.String()),
+		zap.String("familyID", storedToken.FamilyID.String()),
+	)
+
+	if storedToken.RevokedAt.Valid {
+		// A revoked token is being presented again, assume it was stolen and kill the whole family
+		err = qtx.RevokeRefreshTokenFamily(ctx, database.RevokeRefreshTokenFamilyParams{
+			FamilyID:  storedToken.FamilyID,
+			RevokedAt: sql.NullTime{Valid: true, Time: now},
+		})
+		if err != nil {
+			logger.Error("failed to revoke refresh token family", zap.Error(err))
+			return models.AuthTokens{}, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			logger.Error("failed to commit transaction", zap.Error(err))
+			return models.AuthTokens{}, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		logger.Warn("refresh token reuse detected, token family revoked")
+		return models.AuthTokens{}, fmt.Errorf("failed to refresh tokens: %w", apperrors.ErrTokenReuse)
+	}
+
+	userDetails, err := qtx.GetUserDetails(ctx, storedToken.UserID)
+	if err != nil {
+		logger.Error("failed to retrieve token owner", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to retrieve token owner: %w", err)
+	}
+
+	tokens, newTokenID, err := s.issueTokens(ctx, qtx, userDetails.Email, userDetails.ID, userDetails.Role, storedToken.FamilyID)
+	if err != nil {
+		logger.Error("failed to issue tokens", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to issue tokens: %w", err)
+	}
+
+	err = qtx.RotateRefreshToken(ctx, database.RotateRefreshTokenParams{
+		ID:         storedToken.ID,
+		RevokedAt:  sql.NullTime{Valid: true, Time: now},
+		ReplacedBy: uuid.NullUUID{Valid: true, UUID: newTokenID},
+	})
+	if err != nil {
+		logger.Error("failed to revoke rotated refresh token", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.AuthTokens{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("refresh token rotated")
+	return tokens, nil
+}
+
+// IsTokenRevoked reports whether an access token was revoked on logout or issued for a session generation the user
+// has since ended by logging out of all devices
+func (s *TokenService) IsTokenRevoked(ctx context.Context, jti string, userID uuid.UUID, generation int32) (bool, error) {
+	logger := s.logger.With(
+		zap.String("method", "IsTokenRevoked"),
+		zap.String("userID", userID.String()),
+	)
+
+	revoked, err := s.db.IsTokenRevoked(ctx, database.IsTokenRevokedParams{
+		Jti:        jti,
+		UserID:     userID,
+		Generation: generation,
+	})
+	if err != nil {
+		logger.Error("failed to check token revocation", zap.Error(err))
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// RevokeAccessToken records the token's jti so it is rejected until it expires
+func (s *TokenService) RevokeAccessToken(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	logger := s.logger.With(
+		zap.String("method", "RevokeAccessToken"),
+		zap.String("userID", userID.String()),
+	)
+
+	now := time.Now()
+
+	err := s.db.RevokeAccessToken(ctx, database.RevokeAccessTokenParams{
+		Jti:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt.Local(),
+		RevokedAt: now,
+	})
+	if err != nil {
+		logger.Error("failed to revoke access token", zap.Error(err))
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	// Expired tokens are rejected by the verifier anyway, no need to keep them around
+	if err := s.db.DeleteExpiredRevokedTokens(ctx, now); err != nil {
+		logger.Warn("failed to clean up expired revoked tokens", zap.Error(err))
+	}
+
+	logger.Info("access token revoked")
+	return nil
+}
+
+// RevokeRefreshToken revokes the family the refresh token belongs to, ending the session on that device
+func (s *TokenService) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	logger := s.logger.With(
+		zap.String("method", "RevokeRefreshToken"),
+	)
+
+	storedToken, err := s.db.GetRefreshTokenByHash(ctx, database.GetRefreshTokenByHashParams{
+		TokenHash: hashing.HashToken(refreshToken),
+		ExpiresAt: time.Now(),
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("refresh token not found or expired")
+			return nil
+		}
+		logger.Error("failed to retrieve refresh token", zap.Error(err))
+		return fmt.Errorf("failed to retrieve refresh token: %w", err)
+	}
+
+	err = s.db.RevokeRefreshTokenFamily(ctx, database.RevokeRefreshTokenFamilyParams{
+		FamilyID:  storedToken.FamilyID,
+		RevokedAt: sql.NullTime{Valid: true, Time: time.Now()},
+	})
+	if err != nil {
+		logger.Error("failed to revoke refresh token family", zap.Error(err), zap.String("familyID", storedToken.FamilyID.String()))
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	logger.Info("refresh token family revoked", zap.String("familyID", storedToken.FamilyID.String()))
+	return nil
+}
+
+// RevokeAllSessions invalidates every access token issued to the user so far and all of their refresh tokens
+func (s *TokenService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "RevokeAllSessions"),
+		zap.String("userID", userID.String()),
+	)
+
+	now := time.Now()
+
+	// Start transaction
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	if err := revokeSessions(ctx, qtx, userID, now); err != nil {
+		logger.Error("failed to revoke user sessions", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("all user sessions revoked")
+	return nil
+}
+
+// revokeSessions starts a new session generation for the user, so access tokens issued for earlier ones are rejected,
+// and revokes all of their refresh tokens. Run it in the same transaction as the change that calls for it.
+func revokeSessions(ctx context.Context, q *database.Queries, userID uuid.UUID, now time.Time) error {
+	err := q.RevokeUserSessions(ctx, database.RevokeUserSessionsParams{
+		UserID:        userID,
+		RevokedBefore: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+
+	err = q.RevokeUserRefreshTokens(ctx, database.RevokeUserRefreshTokensParams{
+		UserID:    userID,
+		RevokedAt: sql.NullTime{Valid: true, Time: now},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// issueTokens signs an access token and stores a new refresh token in the given family, returning the refresh token's ID
+func (s *TokenService) issueTokens(ctx context.Context, q *database.Queries, email string, userID uuid.UUID, role string, familyID uuid.UUID) (models.AuthTokens, uuid.UUID, error) {
+	now := time.Now()
+
+	generation, err := q.GetSessionGeneration(ctx, userID)
+	if err != nil {
+		return models.AuthTokens{}, uuid.Nil, fmt.Errorf("failed to retrieve session generation: %w", err)
+	}
+
+	refreshToken, err := hashing.GenerateToken()
+	if err != nil {
+		return models.AuthTokens{}, uuid.Nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	tokens := models.AuthTokens{
+		AccessToken:           config.MakeToken(email, userID, role, generation, now),
+		AccessTokenExpiresAt:  now.Add(config.AccessTokenTTL),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: now.Add(config.RefreshTokenTTL),
+	}
+
+	refreshTokenID := uuid.New()
+	err = q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+		ID:        refreshTokenID,
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashing.HashToken(refreshToken),
+		ExpiresAt: tokens.RefreshTokenExpiresAt,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return models.AuthTokens{}, uuid.Nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return tokens, refreshTokenID, nil
+}
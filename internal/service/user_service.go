@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/CP-Payne/ecomstore/internal/config"
 	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/user"
 	"github.com/CP-Payne/ecomstore/internal/models"
 	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
 	"github.com/CP-Payne/ecomstore/internal/utils/hashing"
@@ -15,15 +17,28 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	tokenPurposePasswordReset     = "password_reset"
+	tokenPurposeEmailVerification = "email_verification"
+	passwordResetTTL              = time.Hour
+	emailVerificationTTL          = 24 * time.Hour
+)
+
 type UserService struct {
 	logger *zap.Logger
 	db     *database.Queries
+	sqlDB  *sql.DB
+	mailer models.Mailer
+	appURL string
 }
 
-func NewUserService(db *database.Queries) *UserService {
+func NewUserService(db *database.Queries, sqlDB *sql.DB, mailer models.Mailer, appURL string) *UserService {
 	return &UserService{
 		logger: config.GetLogger(),
 		db:     db,
+		sqlDB:  sqlDB,
+		mailer: mailer,
+		appURL: appURL,
 	}
 }
 
@@ -99,8 +114,340 @@ func (s *UserService) GetUserProfile(ctx context.Context, userID uuid.UUID) (mod
 	}
 
 	return models.UserProfile{
-		ID:    userDetailsRow.ID,
-		Email: userDetailsRow.Email,
-		Name:  sqlNullStringToString(userDetailsRow.Name),
+		ID:            userDetailsRow.ID,
+		Email:         userDetailsRow.Email,
+		Name:          sqlNullStringToString(userDetailsRow.Name),
+		EmailVerified: userDetailsRow.EmailVerifiedAt.Valid,
+		Role:          userDetailsRow.Role,
 	}, nil
 }
+
+func (s *UserService) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	logger := s.logger.With(
+		zap.String("method", "IsEmailVerified"),
+		zap.String("userID", userID.String()),
+	)
+
+	userDetailsRow, err := s.db.GetUserDetails(ctx, userID)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("user not found")
+			return false, fmt.Errorf("failed to retrieve user: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve user", zap.Error(err))
+		return false, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	return userDetailsRow.EmailVerifiedAt.Valid, nil
+}
+
+// SendVerificationEmail emails a link that verifies the user's email address. Returns ErrConflict if the address is already verified.
+func (s *UserService) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "SendVerificationEmail"),
+		zap.String("userID", userID.String()),
+	)
+
+	dbUser, err := s.db.GetUserByID(ctx, userID)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("user not found")
+			return fmt.Errorf("failed to retrieve user: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to retrieve user", zap.Error(err))
+		return fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	if dbUser.EmailVerifiedAt.Valid {
+		logger.Info("email address already verified")
+		return fmt.Errorf("email address already verified: %w", apperrors.ErrConflict)
+	}
+
+	token, err := s.createUserToken(ctx, dbUser.ID, tokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		logger.Error("failed to create email verification token", zap.Error(err))
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, url.QueryEscape(token))
+	err = s.mailer.Send(ctx, &models.EmailMessage{
+		To:      dbUser.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Thanks for registering!\n\n"+
+			"Please confirm your email address using the link below. The link expires in %d hours.\n\n%s\n", int(emailVerificationTTL.Hours()), verifyLink),
+	})
+	if err != nil {
+		logger.Error("failed to send verification email", zap.Error(err))
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	logger.Info("verification email sent")
+	return nil
+}
+
+// VerifyEmail consumes an email verification token and marks the owner's email address as verified
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	logger := s.logger.With(
+		zap.String("method", "VerifyEmail"),
+	)
+
+	now := time.Now()
+
+	// Start transaction
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	userToken, err := qtx.GetUserTokenByHash(ctx, database.GetUserTokenByHashParams{
+		TokenHash: hashing.HashToken(token),
+		Purpose:   tokenPurposeEmailVerification,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("email verification token not found, used or expired")
+			return fmt.Errorf("failed to verify email: %w", apperrors.ErrInvalidToken)
+		}
+		logger.Error("failed to retrieve email verification token", zap.Error(err))
+		return fmt.Errorf("failed to retrieve email verification token: %w", err)
+	}
+
+	logger = logger.With(zap.String("userID", userToken.UserID.String()))
+
+	err = qtx.SetUserEmailVerified(ctx, database.SetUserEmailVerifiedParams{
+		ID:              userToken.UserID,
+		EmailVerifiedAt: sql.NullTime{Valid: true, Time: now},
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		logger.Error("failed to mark email as verified", zap.Error(err))
+		return fmt.Errorf("failed to mark email as verified: %w", err)
+	}
+
+	err = qtx.MarkUserTokenUsed(ctx, database.MarkUserTokenUsedParams{
+		ID:     userToken.ID,
+		UsedAt: sql.NullTime{Valid: true, Time: now},
+	})
+	if err != nil {
+		logger.Error("failed to mark email verification token as used", zap.Error(err))
+		return fmt.Errorf("failed to mark email verification token as used: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("email address verified")
+	return nil
+}
+
+// RequestPasswordReset emails a single use reset link to the user. Unknown emails are ignored so the
+// caller can respond the same way whether or not an account exists.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	logger := s.logger.With(
+		zap.String("method", "RequestPasswordReset"),
+		zap.String("email", email),
+	)
+
+	dbUser, err := s.db.GetUserByEmail(ctx, email)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("password reset requested for unknown email")
+			return nil
+		}
+		logger.Error("failed to retrieve user by email", zap.Error(err))
+		return fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	token, err := s.createUserToken(ctx, dbUser.ID, tokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		logger.Error("failed to create password reset token", zap.Error(err))
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	resetLink := fmt.Sprintf("%s/password/reset?token=%s", s.appURL, url.QueryEscape(token))
+	err = s.mailer.Send(ctx, &models.EmailMessage{
+		To:      dbUser.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("We received a request to reset your password.\n\n"+
+			"Use the link below to choose a new password. The link expires in %d minutes and can only be used once.\n\n%s\n\n"+
+			"If you did not request a password reset you can ignore this email.\n", int(passwordResetTTL.Minutes()), resetLink),
+	})
+	if err != nil {
+		logger.Error("failed to send password reset email", zap.Error(err))
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	logger.Info("password reset email sent")
+	return nil
+}
+
+// ResetPassword consumes a password reset token, sets the new password and ends the user's existing sessions
+func (s *UserService) ResetPassword(ctx context.Context, token, password string) error {
+	logger := s.logger.With(
+		zap.String("method", "ResetPassword"),
+	)
+
+	hashedPassword, err := hashing.HashPassword(password)
+	if err != nil {
+		logger.Error("failed to hash user password", zap.Error(err))
+		return fmt.Errorf("failed to hash user password: %w", err)
+	}
+
+	now := time.Now()
+
+	// Start transaction
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	userToken, err := qtx.GetUserTokenByHash(ctx, database.GetUserTokenByHashParams{
+		TokenHash: hashing.HashToken(token),
+		Purpose:   tokenPurposePasswordReset,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("password reset token not found, used or expired")
+			return fmt.Errorf("failed to reset password: %w", apperrors.ErrInvalidToken)
+		}
+		logger.Error("failed to retrieve password reset token", zap.Error(err))
+		return fmt.Errorf("failed to retrieve password reset token: %w", err)
+	}
+
+	logger = logger.With(zap.String("userID", userToken.UserID.String()))
+
+	err = qtx.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{
+		ID:             userToken.UserID,
+		HashedPassword: hashedPassword,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		logger.Error("failed to update user password", zap.Error(err))
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+
+	err = qtx.MarkUserTokenUsed(ctx, database.MarkUserTokenUsedParams{
+		ID:     userToken.ID,
+		UsedAt: sql.NullTime{Valid: true, Time: now},
+	})
+	if err != nil {
+		logger.Error("failed to mark password reset token as used", zap.Error(err))
+		return fmt.Errorf("failed to mark password reset token as used: %w", err)
+	}
+
+	// Existing sessions may belong to whoever knew the old password
+	if err := revokeSessions(ctx, qtx, userToken.UserID, now); err != nil {
+		logger.Error("failed to revoke user sessions", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("user password reset")
+	return nil
+}
+
+// createUserToken invalidates outstanding tokens for the same purpose and stores a new hashed token, returning the plain token
+func (s *UserService) createUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	token, err := hashing.GenerateToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	now := time.Now()
+
+	err = s.db.InvalidateUserTokens(ctx, database.InvalidateUserTokensParams{
+		UserID:  userID,
+		Purpose: purpose,
+		UsedAt:  sql.NullTime{Valid: true, Time: now},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to invalidate previous tokens: %w", err)
+	}
+
+	err = s.db.CreateUserToken(ctx, database.CreateUserTokenParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashing.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *UserService) SetUserRole(ctx context.Context, userID uuid.UUID, role user.Role) (models.User, error) {
+	logger := s.logger.With(
+		zap.String("method", "SetUserRole"),
+		zap.String("userID", userID.String()),
+		zap.String("role", string(role)),
+	)
+
+	now := time.Now()
+
+	// Start transaction
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return models.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	dbUser, err := qtx.SetUserRole(ctx, database.SetUserRoleParams{
+		ID:        userID,
+		Role:      string(role),
+		UpdatedAt: now,
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			logger.Info("user not found")
+			return models.User{}, fmt.Errorf("failed to set user role: %w", apperrors.ErrNotFound)
+		}
+		logger.Error("failed to set user role", zap.Error(err))
+		return models.User{}, fmt.Errorf("failed to set user role: %w", err)
+	}
+
+	// Tokens carry the role, force the user to sign in again so the change applies immediately
+	if err := revokeSessions(ctx, qtx, userID, now); err != nil {
+		logger.Error("failed to revoke user sessions", zap.Error(err))
+		return models.User{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return models.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("user role updated")
+	return models.DatabaseUserToUser(dbUser), nil
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/CP-Payne/ecomstore/internal/config"
+	"github.com/CP-Payne/ecomstore/internal/database"
+	"github.com/CP-Payne/ecomstore/internal/domain/wishlist"
+	"github.com/CP-Payne/ecomstore/internal/models"
+	"github.com/CP-Payne/ecomstore/internal/utils/apperrors"
+	"github.com/CP-Payne/ecomstore/internal/utils/hashing"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WishlistService struct {
+	logger     *zap.Logger
+	db         *database.Queries
+	sqlDB      *sql.DB
+	carts      *CartService
+	products   *ProductService
+	currencies *CurrencyService
+}
+
+func NewWishlistService(db *database.Queries, sqlDB *sql.DB, carts *CartService, products *ProductService, currencies *CurrencyService) *WishlistService {
+	return &WishlistService{
+		logger:     config.GetLogger(),
+		db:         db,
+		sqlDB:      sqlDB,
+		carts:      carts,
+		products:   products,
+		currencies: currencies,
+	}
+}
+
+// GetWishlists lists the user's wishlists without their items, the saved for later list first
+func (s *WishlistService) GetWishlists(ctx context.Context, userID uuid.UUID) ([]models.Wishlist, error) {
+	records, err := s.db.GetUserWishlists(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to fetch wishlists", zap.Error(err), zap.String("userID", userID.String()))
+		return nil, fmt.Errorf("failed to fetch wishlists: %w", err)
+	}
+
+	wishlists := make([]models.Wishlist, 0, len(records))
+	for _, record := range records {
+		wishlists = append(wishlists, models.Wishlist{
+			ID:            record.ID,
+			Name:          record.Name,
+			SavedForLater: record.SavedForLater,
+			ShareToken:    sqlNullStringToString(record.ShareToken),
+			ItemCount:     int(record.ItemCount),
+			CreatedAt:     record.CreatedAt,
+			UpdatedAt:     record.UpdatedAt,
+		})
+	}
+
+	return wishlists, nil
+}
+
+// CreateWishlist fails with apperrors.ErrConflict when the user already has a wishlist of that name
+func (s *WishlistService) CreateWishlist(ctx context.Context, userID uuid.UUID, name wishlist.Name) (models.Wishlist, error) {
+	logger := s.logger.With(
+		zap.String("method", "CreateWishlist"),
+		zap.String("userID", userID.String()),
+	)
+
+	now := time.Now()
+	created := models.Wishlist{
+		ID:        uuid.New(),
+		Name:      string(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := s.db.CreateWishlist(ctx, database.CreateWishlistParams{
+		ID:        created.ID,
+		UserID:    userID,
+		Name:      created.Name,
+		CreatedAt: now,
+	})
+	if err != nil {
+		if apperrors.IsUniqueViolation(err) {
+			return models.Wishlist{}, fmt.Errorf("wishlist %q: %w", name, apperrors.ErrConflict)
+		}
+		logger.Error("failed to create wishlist", zap.Error(err))
+		return models.Wishlist{}, fmt.Errorf("failed to create wishlist: %w", err)
+	}
+
+	logger.Info("wishlist created", zap.String("wishlistID", created.ID.String()))
+	return created, nil
+}
+
+// GetWishlist returns one of the user's wishlists with its items. It fails with apperrors.ErrNotFound for
+// wishlists of other users.
+func (s *WishlistService) GetWishlist(ctx context.Context, userID, wishlistID uuid.UUID) (models.Wishlist, error) {
+	record, err := s.db.GetWishlist(ctx, database.GetWishlistParams{ID: wishlistID, UserID: userID})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Wishlist{}, fmt.Errorf("wishlist %s: %w", wishlistID, apperrors.ErrNotFound)
+		}
+		s.logger.Error("failed to fetch wishlist", zap.Error(err), zap.String("wishlistID", wishlistID.String()))
+		return models.Wishlist{}, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+
+	return s.withItems(ctx, models.Wishlist{
+		ID:            record.ID,
+		Name:          record.Name,
+		SavedForLater: record.SavedForLater,
+		ShareToken:    sqlNullStringToString(record.ShareToken),
+		CreatedAt:     record.CreatedAt,
+		UpdatedAt:     record.UpdatedAt,
+	})
+}
+
+// GetSharedWishlist returns the wishlist of a share link, for anyone to view. It fails with apperrors.ErrNotFound
+// once the owner stops sharing the list.
+func (s *WishlistService) GetSharedWishlist(ctx context.Context, shareToken string) (models.Wishlist, error) {
+	record, err := s.db.GetSharedWishlist(ctx, sql.NullString{String: shareToken, Valid: true})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Wishlist{}, fmt.Errorf("shared wishlist: %w", apperrors.ErrNotFound)
+		}
+		s.logger.Error("failed to fetch shared wishlist", zap.Error(err))
+		return models.Wishlist{}, fmt.Errorf("failed to fetch shared wishlist: %w", err)
+	}
+
+	return s.withItems(ctx, models.Wishlist{
+		ID:        record.ID,
+		Name:      record.Name,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	})
+}
+
+// UpdateWishlist renames a wishlist when name is set and starts or stops sharing it when shared is set. Sharing
+// creates a new share link each time, so links given out before sharing was stopped keep failing.
+func (s *WishlistService) UpdateWishlist(ctx context.Context, userID, wishlistID uuid.UUID, name *wishlist.Name, shared *bool) (models.Wishlist, error) {
+	logger := s.logger.With(
+		zap.String("method", "UpdateWishlist"),
+		zap.String("wishlistID", wishlistID.String()),
+	)
+
+	current, err := s.db.GetWishlist(ctx, database.GetWishlistParams{ID: wishlistID, UserID: userID})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return models.Wishlist{}, fmt.Errorf("wishlist %s: %w", wishlistID, apperrors.ErrNotFound)
+		}
+		logger.Error("failed to fetch wishlist", zap.Error(err))
+		return models.Wishlist{}, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+
+	params := database.UpdateWishlistParams{
+		ID:         wishlistID,
+		UserID:     userID,
+		Name:       current.Name,
+		ShareToken: current.ShareToken,
+		UpdatedAt:  time.Now(),
+	}
+	if name != nil {
+		if current.SavedForLater {
+			return models.Wishlist{}, fmt.Errorf("saved for later list cannot be renamed: %w", apperrors.ErrConflict)
+		}
+		params.Name = string(*name)
+	}
+	switch {
+	case shared == nil:
+	case *shared && !current.ShareToken.Valid:
+		token, err := hashing.GenerateToken()
+		if err != nil {
+			logger.Error("failed to generate share token", zap.Error(err))
+			return models.Wishlist{}, fmt.Errorf("failed to generate share token: %w", err)
+		}
+		params.ShareToken = sql.NullString{String: token, Valid: true}
+	case !*shared:
+		params.ShareToken = sql.NullString{}
+	}
+
+	if _, err := s.db.UpdateWishlist(ctx, params); err != nil {
+		if apperrors.IsUniqueViolation(err) {
+			return models.Wishlist{}, fmt.Errorf("wishlist %q: %w", params.Name, apperrors.ErrConflict)
+		}
+		logger.Error("failed to update wishlist", zap.Error(err))
+		return models.Wishlist{}, fmt.Errorf("failed to update wishlist: %w", err)
+	}
+
+	logger.Info("wishlist updated", zap.Bool("shared", params.ShareToken.Valid))
+	return s.GetWishlist(ctx, userID, wishlistID)
+}
+
+// DeleteWishlist deletes one of the user's wishlists and its items
+func (s *WishlistService) DeleteWishlist(ctx context.Context, userID, wishlistID uuid.UUID) error {
+	deleted, err := s.db.DeleteWishlist(ctx, database.DeleteWishlistParams{ID: wishlistID, UserID: userID})
+	if err != nil {
+		s.logger.Error("failed to delete wishlist", zap.Error(err), zap.String("wishlistID", wishlistID.String()))
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("wishlist %s: %w", wishlistID, apperrors.ErrNotFound)
+	}
+
+	s.logger.Info("wishlist deleted", zap.String("wishlistID", wishlistID.String()))
+	return nil
+}
+
+// AddItem adds a product, or one of its variants, to one of the user's wishlists. Adding an item already on the
+// list adds to its quantity.
+func (s *WishlistService) AddItem(ctx context.Context, userID, wishlistID, productID uuid.UUID, variantID *uuid.UUID, quantity wishlist.Quantity) error {
+	logger := s.logger.With(
+		zap.String("method", "AddItem"),
+		zap.String("wishlistID", wishlistID.String()),
+		zap.String("productID", productID.String()),
+	)
+
+	if _, err := s.GetWishlist(ctx, userID, wishlistID); err != nil {
+		return err
+	}
+
+	err := s.db.AddWishlistItem(ctx, database.AddWishlistItemParams{
+		ID:         uuid.New(),
+		WishlistID: wishlistID,
+		ProductID:  productID,
+		VariantID:  uuidToNullUuid(variantID),
+		Quantity:   int32(quantity),
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		logger.Error("failed to add wishlist item", zap.Error(err))
+		return fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+
+	logger.Info("item added to wishlist")
+	return nil
+}
+
+// RemoveItem fails with apperrors.ErrNotFound when the item is not on the user's wishlist
+func (s *WishlistService) RemoveItem(ctx context.Context, userID, wishlistID, productID uuid.UUID, variantID *uuid.UUID) error {
+	if _, err := s.GetWishlist(ctx, userID, wishlistID); err != nil {
+		return err
+	}
+
+	removed, err := s.db.RemoveWishlistItem(ctx, database.RemoveWishlistItemParams{
+		WishlistID: wishlistID,
+		ProductID:  productID,
+		VariantID:  uuidToNullUuid(variantID),
+	})
+	if err != nil {
+		s.logger.Error("failed to remove wishlist item", zap.Error(err), zap.String("wishlistID", wishlistID.String()))
+		return fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("product %s on wishlist %s: %w", productID, wishlistID, apperrors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// MoveToCart moves an item from one of the user's wishlists into their cart, in the quantity it had on the list.
+// It fails with the errors of ProductService.GetPurchasableItem when the item cannot be bought in that quantity
+// together with any already in the cart.
+func (s *WishlistService) MoveToCart(ctx context.Context, userID, wishlistID, productID uuid.UUID, variantID *uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "MoveToCart"),
+		zap.String("wishlistID", wishlistID.String()),
+		zap.String("productID", productID.String()),
+	)
+
+	if _, err := s.GetWishlist(ctx, userID, wishlistID); err != nil {
+		return err
+	}
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	item := database.GetWishlistItemParams{
+		WishlistID: wishlistID,
+		ProductID:  productID,
+		VariantID:  uuidToNullUuid(variantID),
+	}
+	quantity, err := qtx.GetWishlistItem(ctx, item)
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("product %s on wishlist %s: %w", productID, wishlistID, apperrors.ErrNotFound)
+		}
+		logger.Error("failed to fetch wishlist item", zap.Error(err))
+		return fmt.Errorf("failed to fetch wishlist item: %w", err)
+	}
+
+	cartID, err := s.carts.userCartID(ctx, qtx, userID)
+	if err != nil {
+		return err
+	}
+
+	// The quantity is added to any already in the cart, so the stock has to cover both
+	inCart, err := qtx.GetCartItem(ctx, database.GetCartItemParams{
+		CartID:    cartID,
+		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
+	})
+	if err != nil && !apperrors.IsNoRowsError(err) {
+		logger.Error("failed to fetch cart item", zap.Error(err))
+		return fmt.Errorf("failed to fetch cart item: %w", err)
+	}
+
+	if _, err := s.products.GetPurchasableItem(ctx, productID, variantID, int(inCart+quantity)); err != nil {
+		return err
+	}
+
+	err = qtx.AddItemToCart(ctx, database.AddItemToCartParams{
+		ID:        uuid.New(),
+		CartID:    cartID,
+		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
+		Quantity:  quantity,
+	})
+	if err != nil {
+		logger.Error("failed to add item to cart", zap.Error(err))
+		return fmt.Errorf("failed to add item to cart: %w", err)
+	}
+
+	if _, err := qtx.RemoveWishlistItem(ctx, database.RemoveWishlistItemParams(item)); err != nil {
+		logger.Error("failed to remove wishlist item", zap.Error(err))
+		return fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("wishlist item moved to cart", zap.String("cartID", cartID.String()))
+	return nil
+}
+
+// SaveForLater moves an item out of the user's cart into their saved for later list, keeping its quantity. The
+// list is created the first time an item is saved. It fails with apperrors.ErrNotFound when the item is not in
+// the cart.
+func (s *WishlistService) SaveForLater(ctx context.Context, userID, productID uuid.UUID, variantID *uuid.UUID) error {
+	logger := s.logger.With(
+		zap.String("method", "SaveForLater"),
+		zap.String("userID", userID.String()),
+		zap.String("productID", productID.String()),
+	)
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}()
+	qtx := s.db.WithTx(tx)
+
+	cartID, err := s.carts.userCartID(ctx, qtx, userID)
+	if err != nil {
+		return err
+	}
+
+	quantity, err := qtx.GetCartItem(ctx, database.GetCartItemParams{
+		CartID:    cartID,
+		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
+	})
+	if err != nil {
+		if apperrors.IsNoRowsError(err) {
+			return fmt.Errorf("product %s in cart %s: %w", productID, cartID, apperrors.ErrNotFound)
+		}
+		logger.Error("failed to fetch cart item", zap.Error(err))
+		return fmt.Errorf("failed to fetch cart item: %w", err)
+	}
+
+	savedID, err := s.savedForLaterID(ctx, qtx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.AddWishlistItem(ctx, database.AddWishlistItemParams{
+		ID:         uuid.New(),
+		WishlistID: savedID,
+		ProductID:  productID,
+		VariantID:  uuidToNullUuid(variantID),
+		Quantity:   quantity,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		logger.Error("failed to save item for later", zap.Error(err))
+		return fmt.Errorf("failed to save item for later: %w", err)
+	}
+
+	err = qtx.RemoveItemFromCart(ctx, database.RemoveItemFromCartParams{
+		CartID:    cartID,
+		ProductID: productID,
+		VariantID: uuidToNullUuid(variantID),
+	})
+	if err != nil {
+		logger.Error("failed to remove item from cart", zap.Error(err))
+		return fmt.Errorf("failed to remove item from cart: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("cart item saved for later", zap.String("wishlistID", savedID.String()))
+	return nil
+}
+
+// savedForLaterID returns the ID of the user's saved for later list, creating it if needed
+func (s *WishlistService) savedForLaterID(ctx context.Context, q *database.Queries, userID uuid.UUID) (uuid.UUID, error) {
+	id, err := q.GetSavedForLaterWishlist(ctx, userID)
+	if err == nil {
+		return id, nil
+	}
+	if !apperrors.IsNoRowsError(err) {
+		s.logger.Error("failed to fetch saved for later list", zap.Error(err), zap.String("userID", userID.String()))
+		return uuid.Nil, fmt.Errorf("failed to fetch saved for later list: %w", err)
+	}
+
+	id = uuid.New()
+	err = q.CreateWishlist(ctx, database.CreateWishlistParams{
+		ID:            id,
+		UserID:        userID,
+		Name:          wishlist.SavedForLaterName,
+		SavedForLater: true,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("failed to create saved for later list", zap.Error(err), zap.String("userID", userID.String()))
+		return uuid.Nil, fmt.Errorf("failed to create saved for later list: %w", err)
+	}
+
+	return id, nil
+}
+
+// withItems loads the wishlist's items with their current prices and availability
+func (s *WishlistService) withItems(ctx context.Context, list models.Wishlist) (models.Wishlist, error) {
+	records, err := s.db.GetWishlistItems(ctx, list.ID)
+	if err != nil {
+		s.logger.Error("failed to fetch wishlist items", zap.Error(err), zap.String("wishlistID", list.ID.String()))
+		return list, fmt.Errorf("failed to fetch wishlist items: %w", err)
+	}
+
+	list.Items = make([]models.WishlistItem, 0, len(records))
+	for _, record := range records {
+		price, err := money.Parse(record.Price, s.currencies.Base())
+		if err != nil {
+			s.logger.Error("failed to parse wishlist item price", zap.Error(err), zap.String("price", record.Price))
+			return list, fmt.Errorf("failed to parse item prices: %w", err)
+		}
+
+		list.Items = append(list.Items, models.WishlistItem{
+			ProductID:  record.ProductID,
+			VariantID:  nullUuidToUuid(record.VariantID),
+			Quantity:   int(record.Quantity),
+			Name:       record.Name,
+			Price:      price,
+			Sku:        sqlNullStringToString(record.VariantSku),
+			Attributes: models.NullRawMessageToRawMessage(record.VariantAttributes),
+			Available:  record.Available,
+			InStock:    record.Available && record.StockQuantity > 0,
+			AddedAt:    record.CreatedAt,
+		})
+	}
+	list.ItemCount = len(list.Items)
+
+	return list, nil
+}
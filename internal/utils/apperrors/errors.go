@@ -8,12 +8,21 @@ import (
 )
 
 var (
-	ErrConflict       = errors.New("conflict")
-	ErrInternal       = errors.New("internal error")
-	ErrNotFound       = errors.New("resource not found")
-	ErrAuthCode       = errors.New("auth code")
-	ErrParseUUID      = errors.New("could not parse UUID")
-	ErrCheckViolation = errors.New("cannot reduce product quantity to less than 0")
+	ErrConflict            = errors.New("conflict")
+	ErrInternal            = errors.New("internal error")
+	ErrNotFound            = errors.New("resource not found")
+	ErrAuthCode            = errors.New("auth code")
+	ErrParseUUID           = errors.New("could not parse UUID")
+	ErrCheckViolation      = errors.New("cannot reduce product quantity to less than 0")
+	ErrInvalidToken        = errors.New("invalid or expired token")
+	ErrTokenReuse          = errors.New("refresh token reuse detected")
+	ErrInvalidRef          = errors.New("referenced resource does not exist")
+	ErrVariantNeeded       = errors.New("product variant must be selected")
+	ErrOutOfStock          = errors.New("insufficient stock")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its subcategories")
+	ErrInvalidImage        = errors.New("image must be a JPEG, PNG or GIF of at most 40 megapixels")
+	ErrReservationExpired  = errors.New("order reservation expired")
+	ErrUnsupportedCurrency = errors.New("currency is not supported")
 )
 
 func IsPqError(err error, code pq.ErrorCode) bool {
@@ -35,3 +44,8 @@ func IsNoRowsError(err error) bool {
 func IsCheckViolation(err error) bool {
 	return IsPqError(err, "23514")
 }
+
+// IsForeignKeyViolation checks for foreign key constraint violations
+func IsForeignKeyViolation(err error) bool {
+	return IsPqError(err, "23503")
+}
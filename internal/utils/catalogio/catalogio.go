@@ -0,0 +1,351 @@
+// Package catalogio reads and writes catalog records as CSV or JSON lines for bulk import and export.
+package catalogio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+	"github.com/CP-Payne/ecomstore/pkg/money"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+var ErrUnknownFormat = errors.New("format must be csv or jsonl")
+
+// ParseFormat accepts a format name or one of the matching content types
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson", "application/x-ndjson", "application/jsonl":
+		return FormatJSONL, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// Price is an amount in cents. It is read and written as a decimal number such as 19.99, never through a float, so
+// prices with fractions of a cent are rejected rather than rounded.
+type Price int64
+
+var errInvalidPrice = errors.New("price must be a number with at most two decimal places")
+
+func parsePrice(s string) (Price, error) {
+	amount, err := money.Parse(s, "")
+	if err != nil {
+		return 0, errInvalidPrice
+	}
+	return Price(amount.Amount), nil
+}
+
+func (p Price) String() string {
+	return money.New(int64(p), "").Decimal()
+}
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON only accepts JSON numbers, prices written as strings are rejected
+func (p *Price) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return errInvalidPrice
+	}
+
+	price, err := parsePrice(string(data))
+	if err != nil {
+		return err
+	}
+	*p = price
+	return nil
+}
+
+// Record is one product of the catalog. Category holds the category's slug; CategoryName is used when the
+// category has to be created or renamed.
+type Record struct {
+	Sku            string          `json:"sku"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Price          Price           `json:"price"`
+	Brand          string          `json:"brand,omitempty"`
+	Stock          int             `json:"stock"`
+	Category       string          `json:"category"`
+	CategoryName   string          `json:"categoryName,omitempty"`
+	ImageURL       string          `json:"imageUrl,omitempty"`
+	ThumbnailURL   string          `json:"thumbnailUrl,omitempty"`
+	Specifications json.RawMessage `json:"specifications,omitempty"`
+	Active         *bool           `json:"active,omitempty"`
+}
+
+// Columns is the CSV header written on export, imports accept the columns in any order
+var Columns = []string{"sku", "name", "description", "price", "brand", "stock", "category", "categoryName", "imageUrl", "thumbnailUrl", "specifications", "active"}
+
+var requiredColumns = []string{"sku", "name", "price", "category"}
+
+// Maximum length of a single JSON line
+const maxLineSize = 1 << 20
+
+// Reader reads records one at a time. A malformed record is reported as an errsx.Map keyed by field, along with
+// whatever could be read of it, and does not stop the reader so all problems of an import can be reported at once.
+type Reader struct {
+	csv     *csv.Reader
+	columns map[string]int
+	lines   *bufio.Scanner
+	line    int
+}
+
+// NewReader returns a reader for the given format. CSV input must start with a header row naming the columns.
+func NewReader(format Format, r io.Reader) (*Reader, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+
+		header, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("csv input is empty, a header row is required")
+			}
+			return nil, fmt.Errorf("failed to read csv header: %w", err)
+		}
+
+		columns, err := parseHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		return &Reader{csv: cr, columns: columns, line: 1}, nil
+
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &Reader{lines: scanner}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func parseHeader(header []string) (map[string]int, error) {
+	known := make(map[string]bool, len(Columns))
+	for _, column := range Columns {
+		known[column] = true
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		if !known[column] {
+			return nil, fmt.Errorf("unknown csv column %q", column)
+		}
+		if _, ok := columns[column]; ok {
+			return nil, fmt.Errorf("duplicate csv column %q", column)
+		}
+		columns[column] = i
+	}
+
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("missing csv column %q", column)
+		}
+	}
+
+	return columns, nil
+}
+
+// Line returns the line number of the record last returned by Read
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Read returns the next record or io.EOF once the input is exhausted
+func (r *Reader) Read() (Record, error) {
+	if r.csv != nil {
+		return r.readCSV()
+	}
+	return r.readJSONL()
+}
+
+func (r *Reader) readCSV() (Record, error) {
+	fields, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return Record{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		r.line = parseErr.StartLine
+		var errs errsx.Map
+		errs.Set("row", parseErr.Err)
+		return Record{}, errs
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	r.line, _ = r.csv.FieldPos(0)
+
+	get := func(column string) string {
+		i, ok := r.columns[column]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	var errs errsx.Map
+	rec := Record{
+		Sku:          get("sku"),
+		Name:         get("name"),
+		Description:  get("description"),
+		Brand:        get("brand"),
+		Category:     get("category"),
+		CategoryName: get("categoryName"),
+		ImageURL:     get("imageUrl"),
+		ThumbnailURL: get("thumbnailUrl"),
+	}
+
+	if v := get("price"); v != "" {
+		price, err := parsePrice(v)
+		if err != nil {
+			errs.Set("price", err)
+		}
+		rec.Price = price
+	}
+
+	if v := get("stock"); v != "" {
+		stock, err := strconv.Atoi(v)
+		if err != nil {
+			errs.Set("stock", "stock must be a whole number")
+		}
+		rec.Stock = stock
+	}
+
+	if v := get("specifications"); v != "" {
+		if !json.Valid([]byte(v)) {
+			errs.Set("specifications", "specifications must be valid JSON")
+		} else {
+			rec.Specifications = json.RawMessage(v)
+		}
+	}
+
+	if v := get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			errs.Set("active", "active must be true or false")
+		} else {
+			rec.Active = &active
+		}
+	}
+
+	if errs != nil {
+		return rec, errs
+	}
+	return rec, nil
+}
+
+func (r *Reader) readJSONL() (Record, error) {
+	for r.lines.Scan() {
+		r.line++
+
+		line := bytes.TrimSpace(r.lines.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec Record
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec); err != nil {
+			var errs errsx.Map
+			errs.Set("row", fmt.Errorf("invalid JSON: %w", err))
+			return Record{}, errs
+		}
+
+		return rec, nil
+	}
+
+	if err := r.lines.Err(); err != nil {
+		return Record{}, fmt.Errorf("failed to read line %d: %w", r.line+1, err)
+	}
+	return Record{}, io.EOF
+}
+
+// Writer writes records in the given format. Output is buffered until Flush is called.
+type Writer struct {
+	csv  *csv.Writer
+	json *json.Encoder
+	buf  *bufio.Writer
+}
+
+// NewWriter returns a writer for the given format, CSV output starts with the header row
+func NewWriter(format Format, w io.Writer) (*Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(Columns); err != nil {
+			return nil, fmt.Errorf("failed to write csv header: %w", err)
+		}
+		return &Writer{csv: cw}, nil
+
+	case FormatJSONL:
+		buf := bufio.NewWriter(w)
+		return &Writer{json: json.NewEncoder(buf), buf: buf}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func (w *Writer) Write(rec Record) error {
+	if w.json != nil {
+		return w.json.Encode(rec)
+	}
+
+	active := ""
+	if rec.Active != nil {
+		active = strconv.FormatBool(*rec.Active)
+	}
+
+	return w.csv.Write([]string{
+		rec.Sku,
+		rec.Name,
+		rec.Description,
+		rec.Price.String(),
+		rec.Brand,
+		strconv.Itoa(rec.Stock),
+		rec.Category,
+		rec.CategoryName,
+		rec.ImageURL,
+		rec.ThumbnailURL,
+		string(rec.Specifications),
+		active,
+	})
+}
+
+func (w *Writer) Flush() error {
+	if w.json != nil {
+		return w.buf.Flush()
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
@@ -0,0 +1,139 @@
+package catalogio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CP-Payne/ecomstore/pkg/errsx"
+)
+
+func TestRoundTrip(t *testing.T) {
+	inactive := false
+	records := []Record{
+		{
+			Sku:            "TSHIRT-001",
+			Name:           "Classic T-Shirt",
+			Description:    "Soft, \"breathable\" cotton",
+			Price:          1999,
+			Brand:          "Acme",
+			Stock:          25,
+			Category:       "clothing",
+			CategoryName:   "Clothing",
+			Specifications: json.RawMessage(`{"material":"cotton"}`),
+		},
+		{
+			Sku:      "MUG-002",
+			Name:     "Mug, large",
+			Price:    800,
+			Category: "kitchen",
+			Active:   &inactive,
+		},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(format, &buf)
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			for _, rec := range records {
+				if err := w.Write(rec); err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			r, err := NewReader(format, &buf)
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			var read []Record
+			for {
+				rec, err := r.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				read = append(read, rec)
+			}
+
+			if !reflect.DeepEqual(read, records) {
+				t.Fatalf("expected %+v but got %+v", records, read)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		line   int
+		field  string
+	}{
+		{"csv price", FormatCSV, "sku,name,price,category\nA-1,Mug,cheap,kitchen\n", 2, "price"},
+		{"csv fraction of a cent", FormatCSV, "sku,name,price,category\nA-1,Mug,8.999,kitchen\n", 2, "price"},
+		{"csv stock", FormatCSV, "sku,name,price,category,stock\nA-1,Mug,8,kitchen,2.5\n", 2, "stock"},
+		{"csv active", FormatCSV, "sku,name,price,category,active\nA-1,Mug,8,kitchen,maybe\n", 2, "active"},
+		{"csv specifications", FormatCSV, "sku,name,price,category,specifications\nA-1,Mug,8,kitchen,{oops\n", 2, "specifications"},
+		{"csv quotes", FormatCSV, "sku,name,price,category\nA-1,Mug \"x\"\",8,kitchen\n", 2, "row"},
+		{"jsonl syntax", FormatJSONL, "\n{\"sku\":\"A-1\"\n", 2, "row"},
+		{"jsonl unknown field", FormatJSONL, `{"sku":"A-1","colour":"red"}`, 1, "row"},
+		{"jsonl wrong type", FormatJSONL, `{"sku":"A-1","price":"8"}`, 1, "row"},
+		{"jsonl fraction of a cent", FormatJSONL, `{"sku":"A-1","price":8.999}`, 1, "row"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReader(tt.format, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			_, err = r.Read()
+			var errs errsx.Map
+			if !errors.As(err, &errs) || !errs.Has(tt.field) {
+				t.Fatalf("expected an error for %q but got %v", tt.field, err)
+			}
+			if r.Line() != tt.line {
+				t.Fatalf("expected line %d but got %d", tt.line, r.Line())
+			}
+		})
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"required columns in any order", "category,price,name,sku\n", false},
+		{"byte order mark", "\ufeffsku,name,price,category\n", false},
+		{"empty input", "", true},
+		{"missing column", "sku,name,price\n", true},
+		{"unknown column", "sku,name,price,category,colour\n", true},
+		{"duplicate column", "sku,name,price,category,sku\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(FormatCSV, strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package imaging resizes images using only the standard library
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit scales the image down so that neither side exceeds max, keeping its aspect ratio.
+// Images that already fit are returned unchanged.
+func Fit(src image.Image, max int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= max && h <= max {
+		return src
+	}
+
+	if w >= h {
+		h = scaleSide(h, max, w)
+		w = max
+	} else {
+		w = scaleSide(w, max, h)
+		h = max
+	}
+
+	return Resize(src, w, h)
+}
+
+// Thumbnail crops the centre square of the image and scales it to size x size. Images smaller than size are
+// cropped but not enlarged.
+func Thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), src, image.Pt(x0, y0), draw.Src)
+
+	if side <= size {
+		return square
+	}
+	return Resize(square, size, size)
+}
+
+// Resize scales the image to exactly w x h. Each destination pixel averages the source pixels it covers, which
+// gives smooth results when shrinking.
+func Resize(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 || b.Empty() {
+		return dst
+	}
+
+	// Reading pixels through At is slow, so work on an RGBA copy whose bytes can be read directly
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(b)
+		draw.Draw(rgba, b, src, b.Min, draw.Src)
+	}
+
+	for y := 0; y < h; y++ {
+		sy0 := b.Min.Y + y*b.Dy()/h
+		sy1 := b.Min.Y + (y+1)*b.Dy()/h
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+
+		for x := 0; x < w; x++ {
+			sx0 := b.Min.X + x*b.Dx()/w
+			sx1 := b.Min.X + (x+1)*b.Dx()/w
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				i := rgba.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint64(rgba.Pix[i])
+					g += uint64(rgba.Pix[i+1])
+					bl += uint64(rgba.Pix[i+2])
+					a += uint64(rgba.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n),
+				G: uint8(g / n),
+				B: uint8(bl / n),
+				A: uint8(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+func scaleSide(side, target, reference int) int {
+	scaled := side * target / reference
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func filled(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		max  int
+		expW int
+		expH int
+	}{
+		{"landscape", 1600, 900, 800, 800, 450},
+		{"portrait", 900, 1600, 800, 450, 800},
+		{"square", 1000, 1000, 400, 400, 400},
+		{"already fits", 300, 200, 800, 300, 200},
+		{"thin", 4000, 2, 100, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Fit(filled(tt.w, tt.h, color.RGBA{A: 255}), tt.max)
+
+			if result.Bounds().Dx() != tt.expW || result.Bounds().Dy() != tt.expH {
+				t.Fatalf("expected %dx%d but got %dx%d", tt.expW, tt.expH, result.Bounds().Dx(), result.Bounds().Dy())
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		size int
+		exp  int
+	}{
+		{"landscape", 1600, 900, 200, 200},
+		{"portrait", 300, 1200, 200, 200},
+		{"smaller than size", 120, 80, 200, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Thumbnail(filled(tt.w, tt.h, color.RGBA{A: 255}), tt.size)
+
+			if result.Bounds().Dx() != tt.exp || result.Bounds().Dy() != tt.exp {
+				t.Fatalf("expected %dx%d but got %dx%d", tt.exp, tt.exp, result.Bounds().Dx(), result.Bounds().Dy())
+			}
+		})
+	}
+}
+
+func TestResizeAveragesPixels(t *testing.T) {
+	// Left half black, right half white, shrunk to two pixels keeps both colours
+	src := filled(4, 2, color.RGBA{A: 255})
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	result := Resize(src, 2, 1)
+
+	if got := result.RGBAAt(0, 0); got != (color.RGBA{A: 255}) {
+		t.Fatalf("expected black left pixel but got %v", got)
+	}
+	if got := result.RGBAAt(1, 0); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Fatalf("expected white right pixel but got %v", got)
+	}
+
+	// Shrinking to one pixel averages to grey
+	if got := Resize(src, 1, 1).RGBAAt(0, 0); got.R != 127 || got.A != 255 {
+		t.Fatalf("expected grey pixel but got %v", got)
+	}
+}
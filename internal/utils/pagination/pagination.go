@@ -0,0 +1,90 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("limit must be a number between 1 and 100")
+)
+
+// Cursor points at the last row of a page. Key holds the value of the column the listing is sorted by,
+// ID breaks ties between rows sharing that value.
+type Cursor struct {
+	Key string    `json:"k"`
+	ID  uuid.UUID `json:"i"`
+}
+
+// Params describes the page requested by a client
+type Params struct {
+	Limit        int
+	Cursor       *Cursor
+	IncludeTotal bool
+}
+
+// Encode returns the opaque form of the cursor handed out to clients
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Time returns the cursor key of listings sorted by a timestamp
+func (c Cursor) Time() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Key)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t, nil
+}
+
+func TimeCursor(t time.Time, id uuid.UUID) Cursor {
+	return Cursor{Key: t.Format(time.RFC3339Nano), ID: id}
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// ParseParams reads the limit and cursor query values, an empty value selects the default
+func ParseParams(limit, cursor string) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > MaxLimit {
+			return Params{}, ErrInvalidLimit
+		}
+		params.Limit = l
+	}
+
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return Params{}, err
+		}
+		params.Cursor = &c
+	}
+
+	return params, nil
+}
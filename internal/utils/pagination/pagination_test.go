@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 9, 1, 10, 30, 0, 123456000, time.UTC)
+	id := uuid.New()
+
+	decoded, err := DecodeCursor(TimeCursor(created, id).Encode())
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	if decoded.ID != id {
+		t.Fatalf("expected id %v but got %v", id, decoded.ID)
+	}
+
+	decodedTime, err := decoded.Time()
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	if !decodedTime.Equal(created) {
+		t.Fatalf("expected time %v but got %v", created, decodedTime)
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	cursor := Cursor{Key: "k", ID: uuid.New()}
+
+	tests := []struct {
+		name      string
+		limit     string
+		cursor    string
+		expected  int
+		hasCursor bool
+		err       error
+	}{
+		{"defaults", "", "", DefaultLimit, false, nil},
+		{"limit", "5", "", 5, false, nil},
+		{"max limit", "100", "", MaxLimit, false, nil},
+		{"with cursor", "10", cursor.Encode(), 10, true, nil},
+		{"zero limit", "0", "", 0, false, ErrInvalidLimit},
+		{"limit too large", "101", "", 0, false, ErrInvalidLimit},
+		{"non numeric limit", "ten", "", 0, false, ErrInvalidLimit},
+		{"garbage cursor", "", "not-a-cursor", 0, false, ErrInvalidCursor},
+		{"cursor without id", "", Cursor{Key: "k"}.Encode(), 0, false, ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseParams(tt.limit, tt.cursor)
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result.Limit != tt.expected {
+				t.Fatalf("expected limit %v but got %v", tt.expected, result.Limit)
+			}
+
+			if (result.Cursor != nil) != tt.hasCursor {
+				t.Fatalf("expected cursor present %v but got %v", tt.hasCursor, result.Cursor != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const USD Currency = "USD"
+
+var currencyCode = regexp.MustCompile("^[A-Z]{3}$")
+
+var ErrInvalidCurrency = errors.New("currency must be a three letter ISO 4217 code")
+
+// ParseCurrency accepts a currency code in either case, such as "eur"
+func ParseCurrency(s string) (Currency, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if !currencyCode.MatchString(code) {
+		return "", ErrInvalidCurrency
+	}
+
+	return Currency(code), nil
+}
+
+// minorUnits is the number of decimal places of every supported currency, matching the DECIMAL(10, 2) columns
+// amounts are stored in
+const minorUnits = 2
+
+var ErrInvalidAmount = errors.New("amount must be a decimal number with at most two decimal places")
+
+// Money is an amount in the minor units of its currency, such as cents, so that sums and multiples are exact.
+// The zero value is an amount of zero in no particular currency and takes on the currency of whatever it is
+// added to.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount such as "12.34", as returned by Postgres for DECIMAL columns. Amounts with more
+// decimal places than the currency has are rejected rather than rounded.
+func Parse(s string, currency Currency) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	negative := strings.HasPrefix(s, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" || len(fraction) > minorUnits || strings.ContainsAny(whole+fraction, "+-") {
+		return Money{}, ErrInvalidAmount
+	}
+	fraction += strings.Repeat("0", minorUnits-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// FromFloat converts an amount that has already been validated to have at most two decimal places, rounding away
+// the error of its float representation
+func FromFloat(f float64, currency Currency) Money {
+	return Money{Amount: int64(math.Round(f * 100)), Currency: currency}
+}
+
+// Add returns the sum of two amounts. Adding amounts in different currencies is a programming error and panics.
+func (m Money) Add(o Money) Money {
+	switch {
+	case m.Currency == "":
+		m.Currency = o.Currency
+	case o.Currency != "" && o.Currency != m.Currency:
+		panic(fmt.Sprintf("money: cannot add %s to %s", o.Currency, m.Currency))
+	}
+
+	m.Amount += o.Amount
+	return m
+}
+
+// Convert returns the amount in another currency at the given rate, the units of the target currency per unit
+// of this one. The result is rounded to the nearest minor unit, halves away from zero.
+func (m Money) Convert(rate *big.Rat, to Currency) Money {
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+
+	// Integer division truncates towards zero, so rounding is done on the magnitude
+	num := new(big.Int).Abs(converted.Num())
+	denom := converted.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(denom) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if converted.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	return Money{Amount: quotient.Int64(), Currency: to}
+}
+
+// Mul returns the amount multiplied by a quantity
+func (m Money) Mul(quantity int) Money {
+	m.Amount *= int64(quantity)
+	return m
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Decimal formats the amount without its currency, such as "12.34", as expected by Postgres and PayPal
+func (m Money) Decimal() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+type moneyJSON struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string so clients never see it rounded through a float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
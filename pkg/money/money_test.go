@@ -0,0 +1,159 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		err      error
+	}{
+		{"12.34", 1234, nil},
+		{"12.3", 1230, nil},
+		{"12", 1200, nil},
+		{"0.01", 1, nil},
+		{"-5.50", -550, nil},
+		{"99999999.99", 9999999999, nil},
+		{"9.999", 0, ErrInvalidAmount},
+		{".5", 0, ErrInvalidAmount},
+		{"1e3", 0, ErrInvalidAmount},
+		{"--1", 0, ErrInvalidAmount},
+		{"", 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := Parse(tt.input, USD)
+
+			if err != tt.err {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if err == nil && (result.Amount != tt.expected || result.Currency != USD) {
+				t.Fatalf("expected %d USD but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{1234, "12.34"},
+		{5, "0.05"},
+		{0, "0.00"},
+		{-550, "-5.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := New(tt.input, USD).Decimal(); result != tt.expected {
+				t.Fatalf("expected %s but got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	// 0.1 + 0.2 drifts as a float, the total must match the sum of the line items exactly
+	var total Money
+	for _, line := range []Money{New(10, USD).Mul(3), New(20, USD), New(1999, USD).Mul(7)} {
+		total = total.Add(line)
+	}
+
+	if total != New(14043, USD) {
+		t.Fatalf("expected 140.43 USD but got %v", total)
+	}
+
+	if f := FromFloat(19.99, USD); f.Amount != 1999 {
+		t.Fatalf("expected 1999 but got %d", f.Amount)
+	}
+}
+
+func TestAddMixedCurrenciesPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected adding EUR to USD to panic")
+		}
+	}()
+
+	New(100, USD).Add(New(100, "EUR"))
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1999, USD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":"19.99","currency":"USD"}` {
+		t.Fatalf("unexpected encoding %s", data)
+	}
+
+	var decoded Money
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != New(1999, USD) {
+		t.Fatalf("expected 19.99 USD but got %v", decoded)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		rate     string
+		expected int64
+	}{
+		{1999, "0.92", 1839},
+		{1000, "1", 1000},
+		{1, "0.5", 1},
+		{3, "0.5", 2},
+		{-3, "0.5", -2},
+		{1999, "151.37", 302589},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rate, func(t *testing.T) {
+			rate, _ := new(big.Rat).SetString(tt.rate)
+
+			result := New(tt.amount, USD).Convert(rate, "EUR")
+			if result != New(tt.expected, "EUR") {
+				t.Fatalf("expected %d EUR but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Currency
+		err      error
+	}{
+		{"USD", USD, nil},
+		{" eur ", "EUR", nil},
+		{"EURO", "", ErrInvalidCurrency},
+		{"E1R", "", ErrInvalidCurrency},
+		{"", "", ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseCurrency(tt.input)
+
+			if err != tt.err {
+				t.Fatalf("expected error %v but got %v", tt.err, err)
+			}
+
+			if result != tt.expected {
+				t.Fatalf("expected %q but got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -1,52 +1,129 @@
 -- name: GetActiveCart :one
-SELECT id, user_id, status, created_at
+SELECT id, user_id, status, currency, created_at
 FROM carts
-WHERE user_id=$1 AND status='active'
+WHERE user_id=$1 AND status IN ('active', 'abandoned')
 ORDER BY created_at DESC
 LIMIT 1;
 
 -- name: GetCartItems :many
-SELECT product_id, quantity
+SELECT product_id, variant_id, quantity
 FROM cart_items
 WHERE cart_id=$1;
 
 
+-- name: GetCartItem :one
+SELECT quantity
+FROM cart_items
+WHERE cart_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3;
+
 -- name: CreateCart :exec
 INSERT INTO carts (id, user_id, status)
 VALUES ($1, $2, 'active');
 
+-- name: GetGuestCart :one
+SELECT id, status, currency, created_at
+FROM carts
+WHERE id=$1 AND user_id IS NULL AND status IN ('active', 'abandoned');
+
+-- name: GetCartItemsWithStock :many
+SELECT ci.product_id, ci.variant_id, ci.quantity, coalesce(v.stock_quantity, p.stock_quantity)::integer AS stock_quantity,
+    (p.is_active AND CASE
+        WHEN ci.variant_id IS NULL THEN NOT EXISTS (
+            SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_active = true
+        )
+        ELSE v.is_active
+    END)::boolean AS available
+FROM cart_items ci
+JOIN products p ON p.id = ci.product_id
+LEFT JOIN product_variants v ON v.id = ci.variant_id
+WHERE ci.cart_id = $1;
+
+-- name: SetCartItemQuantity :exec
+INSERT INTO cart_items (id, cart_id, product_id, variant_id, quantity, price_at_add)
+SELECT $1, $2, p.id, $4, $5, coalesce(v.price, p.price)
+FROM products p
+LEFT JOIN product_variants v ON v.id = $4 AND v.product_id = p.id
+WHERE p.id = $3
+ON CONFLICT (cart_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
+DO UPDATE SET quantity = EXCLUDED.quantity;
+
+-- name: SetCartCurrency :exec
+UPDATE carts
+SET currency = $2, updated_at = $3
+WHERE id = $1;
+
 -- name: AddItemToCart :exec
-INSERT INTO cart_items (id, cart_id, product_id, quantity)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (cart_id, product_id)
+INSERT INTO cart_items (id, cart_id, product_id, variant_id, quantity, price_at_add)
+SELECT $1, $2, p.id, $4, $5, coalesce(v.price, p.price)
+FROM products p
+LEFT JOIN product_variants v ON v.id = $4 AND v.product_id = p.id
+WHERE p.id = $3
+ON CONFLICT (cart_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
 DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity;
 
 
+-- name: AdjustCartItem :exec
+UPDATE cart_items ci
+SET quantity = $4, price_at_add = coalesce(v.price, p.price)
+FROM products p
+LEFT JOIN product_variants v ON v.id = $3 AND v.product_id = p.id
+WHERE ci.cart_id = $1 AND ci.product_id = $2 AND ci.variant_id IS NOT DISTINCT FROM $3 AND p.id = ci.product_id;
+
 -- name: RemoveItemFromCart :exec
 DELETE FROM cart_items
-WHERE cart_id=$1 AND product_id=$2;
+WHERE cart_id=$1 AND product_id=$2 AND variant_id IS NOT DISTINCT FROM $3;
 
 -- name: GetCartWithItems :many
-SELECT c.id AS cart_id, c.user_id, ci.product_id, ci.quantity, p.name, p.price
+SELECT c.id AS cart_id, c.user_id, ci.product_id, ci.variant_id, ci.quantity, p.name,
+    coalesce(v.price, p.price)::text AS price, ci.price_at_add::text AS price_at_add,
+    v.sku AS variant_sku, v.attributes AS variant_attributes,
+    coalesce(v.stock_quantity, p.stock_quantity)::integer AS stock_quantity,
+    (p.is_active AND CASE
+        WHEN ci.variant_id IS NULL THEN NOT EXISTS (
+            SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_active = true
+        )
+        ELSE v.is_active
+    END)::boolean AS available
 FROM carts c
 JOIN cart_items ci ON c.id = ci.cart_id
 JOIN products p ON ci.product_id = p.id
+LEFT JOIN product_variants v ON ci.variant_id = v.id
 WHERE c.id = $1;
 
 -- name: DeleteCart :exec
 DELETE FROM carts
 WHERE id=$1;
 
+-- name: GetCartByID :one
+SELECT id, user_id, status, currency, created_at
+FROM carts
+WHERE id=$1;
+
+-- name: ReactivateCart :exec
+UPDATE carts
+SET status = 'active', updated_at = $2
+WHERE id = $1 AND status = 'abandoned';
+
+-- name: MarkAbandonedCarts :many
+UPDATE carts
+SET status = 'abandoned'
+WHERE status = 'active' AND updated_at < $1
+RETURNING id, user_id;
+
+-- name: PurgeAbandonedCarts :execrows
+DELETE FROM carts
+WHERE status = 'abandoned' AND updated_at < $1;
+
 
 -- name: ReduceItemFromCart :exec
 WITH updated AS (
     UPDATE cart_items
-    SET quantity = cart_items.quantity - $3
-    WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2
+    SET quantity = cart_items.quantity - $4
+    WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND cart_items.variant_id IS NOT DISTINCT FROM $3
     RETURNING cart_items.quantity
 )
 DELETE FROM cart_items
-WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND EXISTS (
+WHERE cart_items.cart_id = $1 AND cart_items.product_id = $2 AND cart_items.variant_id IS NOT DISTINCT FROM $3 AND EXISTS (
     SELECT 1 FROM updated WHERE updated.quantity <= 0
 );
 
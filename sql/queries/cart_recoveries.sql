@@ -0,0 +1,35 @@
+-- name: CreateCartRecovery :execrows
+INSERT INTO cart_recoveries (id, cart_id, user_id, abandoned_at)
+SELECT $1, c.id, c.user_id, $3
+FROM carts c
+WHERE c.id = $2 AND c.user_id IS NOT NULL AND EXISTS (
+    SELECT 1 FROM cart_items ci WHERE ci.cart_id = c.id
+);
+
+-- name: GetPendingCartRecoveries :many
+SELECT r.id, r.cart_id, c.currency, u.email, u.name
+FROM cart_recoveries r
+JOIN carts c ON c.id = r.cart_id AND c.status = 'abandoned'
+JOIN users u ON u.id = r.user_id
+WHERE r.email_sent_at IS NULL
+ORDER BY r.abandoned_at;
+
+-- name: MarkCartRecoveryEmailSent :exec
+UPDATE cart_recoveries
+SET email_sent_at = $2
+WHERE id = $1;
+
+-- name: GetCartRecovery :one
+SELECT id, cart_id, user_id, converted_at
+FROM cart_recoveries
+WHERE id = $1;
+
+-- name: RestoreCartRecovery :exec
+UPDATE cart_recoveries
+SET cart_id = $2, restored_at = coalesce(restored_at, $3)
+WHERE id = $1;
+
+-- name: ConvertCartRecoveries :execrows
+UPDATE cart_recoveries
+SET order_id = $2, converted_at = $3
+WHERE cart_id = $1 AND converted_at IS NULL;
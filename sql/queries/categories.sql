@@ -0,0 +1,53 @@
+-- name: GetCategory :one
+SELECT * FROM categories
+WHERE id = $1;
+
+-- name: GetCategoryBySlug :one
+SELECT * FROM categories
+WHERE slug = $1;
+
+-- name: CreateCategory :one
+INSERT INTO categories (id, name, description, parent_id, slug, sort_order)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdateCategory :one
+UPDATE categories
+SET name = $2, description = $3, parent_id = $4, slug = $5, sort_order = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCategory :execrows
+DELETE FROM categories
+WHERE id = $1;
+
+-- name: GetCategoryPath :many
+WITH RECURSIVE category_path AS (
+    SELECT categories.id, categories.name, categories.description, categories.parent_id, categories.slug, categories.sort_order, 0 AS depth
+    FROM categories WHERE categories.id = $1
+    UNION ALL
+    SELECT c.id, c.name, c.description, c.parent_id, c.slug, c.sort_order, p.depth + 1
+    FROM categories c JOIN category_path p ON c.id = p.parent_id
+)
+SELECT id, name, description, parent_id, slug, sort_order
+FROM category_path
+ORDER BY depth DESC;
+
+-- name: GetCategoryDescendantIDs :many
+WITH RECURSIVE category_tree AS (
+    SELECT categories.id FROM categories WHERE categories.id = $1
+    UNION ALL
+    SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+)
+SELECT id FROM category_tree;
+
+-- name: LockCategoryPath :many
+WITH RECURSIVE category_path AS (
+    SELECT categories.id, categories.parent_id FROM categories WHERE categories.id = sqlc.arg(parent_id)
+    UNION
+    SELECT c.id, c.parent_id FROM categories c JOIN category_path p ON c.id = p.parent_id
+)
+SELECT categories.id FROM categories
+WHERE categories.id IN (SELECT category_path.id FROM category_path) OR categories.id = sqlc.arg(category_id)
+ORDER BY categories.id
+FOR UPDATE;
@@ -0,0 +1,70 @@
+-- name: LockProductStock :one
+SELECT stock_quantity FROM products
+WHERE id = $1
+FOR UPDATE;
+
+-- name: LockVariantStock :one
+SELECT stock_quantity FROM product_variants
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetReservedQuantity :one
+SELECT coalesce(sum(quantity), 0)::integer AS reserved
+FROM inventory_reservations
+WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2 AND status = 'active' AND expires_at > $3;
+
+-- name: CreateReservation :exec
+INSERT INTO inventory_reservations (id, order_id, product_id, variant_id, quantity, status, expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'active', $6, $7, $7);
+
+-- name: GetActiveOrderReservations :many
+SELECT * FROM inventory_reservations
+WHERE order_id = $1 AND status = 'active'
+FOR UPDATE;
+
+-- name: OrderReservationsHeld :one
+SELECT EXISTS (
+    SELECT 1 FROM inventory_reservations WHERE order_id = $1 AND status = 'active' AND expires_at > $2
+);
+
+-- name: SetOrderReservationsStatus :execrows
+WITH settled AS (
+    UPDATE inventory_reservations
+    SET status = $2, updated_at = $3
+    WHERE order_id = $1 AND status = 'active'
+    RETURNING order_id, product_id, variant_id, quantity
+)
+INSERT INTO stock_movements (id, product_id, variant_id, kind, quantity, order_id, reason, created_at)
+SELECT gen_random_uuid(), product_id, variant_id, 'reservation', quantity, order_id, $4, $3
+FROM settled;
+
+-- name: ReleaseExpiredReservations :execrows
+WITH released AS (
+    UPDATE inventory_reservations
+    SET status = 'released', updated_at = $1
+    WHERE status = 'active' AND expires_at <= $1
+    RETURNING order_id, product_id, variant_id, quantity
+), movements AS (
+    INSERT INTO stock_movements (id, product_id, variant_id, kind, quantity, order_id, reason, created_at)
+    SELECT gen_random_uuid(), product_id, variant_id, 'reservation', quantity, order_id, 'reservation expired', $1
+    FROM released
+)
+UPDATE orders
+SET status = 'EXPIRED', updated_at = $1
+WHERE id IN (SELECT order_id FROM released) AND status IN ('created', 'PAYER_ACTION_REQUIRED');
+
+-- name: SupersedeCartOrders :execrows
+WITH cancelled AS (
+    UPDATE orders
+    SET status = 'CANCELLED', updated_at = $2
+    WHERE cart_id = $1 AND status IN ('created', 'PAYER_ACTION_REQUIRED')
+    RETURNING id
+), released AS (
+    UPDATE inventory_reservations
+    SET status = 'released', updated_at = $2
+    WHERE order_id IN (SELECT id FROM cancelled) AND status = 'active'
+    RETURNING order_id, product_id, variant_id, quantity
+)
+INSERT INTO stock_movements (id, product_id, variant_id, kind, quantity, order_id, reason, created_at)
+SELECT gen_random_uuid(), product_id, variant_id, 'reservation', quantity, order_id, 'order superseded', $2
+FROM released;
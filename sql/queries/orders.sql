@@ -1,14 +1,15 @@
 -- name: CreateOrder :one
 INSERT INTO orders(
-    id, user_id, product_total,order_total, status, payment_method, shipping_price, cart_id, created_at, updated_at
-) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    id, user_id, product_total,order_total, status, payment_method, shipping_price, cart_id, created_at, updated_at,
+    currency, exchange_rate
+) VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING id;
 
 
 -- name: CreateOrderItem :exec
 INSERT INTO order_items(
-    id, order_id, product_id, quantity, price
-) VALUES ( $1, $2, $3, $4, $5);
+    id, order_id, product_id, variant_id, quantity, price
+) VALUES ( $1, $2, $3, $4, $5, $6);
 
 -- name: GetOrderByID :one
 SELECT * FROM orders
@@ -19,9 +20,10 @@ SELECT * FROM orders
 WHERE processor_order_id = $1;
 
 -- name: GetOrderItemsByOrderID :many
-SELECT oi.quantity, p.price, p.name, oi.product_id
+SELECT oi.quantity, oi.price, p.name, oi.product_id, oi.variant_id, v.sku AS variant_sku
 FROM order_items oi
 JOIN products p ON oi.product_id = p.id
+LEFT JOIN product_variants v ON oi.variant_id = v.id
 WHERE oi.order_id = $1;
 
 -- name: SetProcessorIDAndStatus :exec
@@ -37,4 +39,9 @@ UPDATE orders
 -- name: GetUserOrderIDs :many
 SELECT id
 FROM orders
-WHERE user_id = $1 AND status = 'COMPLETED';
\ No newline at end of file
+WHERE user_id = $1 AND status = 'COMPLETED';
+
+-- name: CancelOrder :execrows
+UPDATE orders
+    SET status = 'CANCELLED', updated_at = $2
+    WHERE id = $1 AND status IN ('created', 'PAYER_ACTION_REQUIRED');
@@ -0,0 +1,27 @@
+-- name: GetProductImages :many
+SELECT * FROM product_images
+WHERE product_id = $1
+ORDER BY position, created_at;
+
+-- name: GetProductImage :one
+SELECT * FROM product_images
+WHERE id = $1 AND product_id = $2;
+
+-- name: CreateProductImage :one
+INSERT INTO product_images (id, product_id, position, alt_text, format, width, height, created_at)
+VALUES ($1, $2, (SELECT coalesce(max(position) + 1, 0) FROM product_images WHERE product_id = $2), $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: SetProductImagePosition :execrows
+UPDATE product_images
+SET position = $3
+WHERE id = $1 AND product_id = $2;
+
+-- name: DeleteProductImage :execrows
+DELETE FROM product_images
+WHERE id = $1 AND product_id = $2;
+
+-- name: SetProductImageURLs :exec
+UPDATE products
+SET image_url = $2, thumbnail_url = $3, updated_at = $4
+WHERE id = $1;
@@ -0,0 +1,41 @@
+-- name: GetProductVariants :many
+SELECT * FROM product_variants
+WHERE product_id = $1 AND is_active = true
+ORDER BY created_at, id;
+
+-- name: ListProductVariants :many
+SELECT * FROM product_variants
+WHERE product_id = $1
+ORDER BY created_at, id;
+
+-- name: GetProductVariant :one
+SELECT * FROM product_variants
+WHERE id = $1 AND product_id = $2;
+
+-- name: ProductHasVariants :one
+SELECT EXISTS (
+    SELECT 1 FROM product_variants
+    WHERE product_id = $1 AND is_active = true
+);
+
+-- name: CreateProductVariant :one
+INSERT INTO product_variants (id, product_id, sku, price, stock_quantity, attributes, is_active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: UpdateProductVariant :one
+UPDATE product_variants
+SET sku = $3, price = $4, stock_quantity = $5, attributes = $6, updated_at = $7
+WHERE id = $1 AND product_id = $2
+RETURNING *;
+
+-- name: SetProductVariantActive :one
+UPDATE product_variants
+SET is_active = $3, updated_at = $4
+WHERE id = $1 AND product_id = $2
+RETURNING *;
+
+-- name: UpdateVariantStock :execrows
+UPDATE product_variants
+SET stock_quantity = stock_quantity - $2
+WHERE id = $1 AND stock_quantity >= $2;
@@ -1,33 +1,136 @@
 -- name: ListProducts :many
 SELECT * FROM products
-WHERE (created_at > $1 OR (created_at = $1 AND id > $2))
+WHERE is_active = true AND (created_at > $1 OR (created_at = $1 AND id > $2))
 ORDER BY created_at, id
 LIMIT $3;
 
 -- name: ProductExists :one
 SELECT EXISTS (
-    SELECT 1 FROM products WHERE id = $1
+    SELECT 1 FROM products WHERE id = $1 AND is_active = true
 );
 
 -- name: GetProduct :one
 SELECT * FROM products
-WHERE id = $1;
+WHERE id = $1 AND is_active = true;
 
 -- name: GetTotalProducts :one
-SELECT COUNT(*) FROM products;
+SELECT COUNT(*) FROM products
+WHERE is_active = true;
 
 -- name: GetAllProducts :many
-SELECT * FROM products;
+SELECT * FROM products
+WHERE is_active = true;
 
 -- name: GetProductCategories :many
-SELECT * FROM categories;
+SELECT * FROM categories
+ORDER BY sort_order, name;
 
 -- name: GetProductsByCategory :many
-SELECT * FROM products
-WHERE category_id = $1;
+WITH RECURSIVE category_tree AS (
+    SELECT categories.id FROM categories WHERE categories.id = sqlc.arg(category_id)
+    UNION ALL
+    SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+    WHERE sqlc.arg(include_descendants)::boolean
+)
+SELECT id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at
+FROM products
+WHERE category_id IN (SELECT id FROM category_tree) AND is_active = true
+    AND (created_at > sqlc.arg(after_created_at) OR (created_at = sqlc.arg(after_created_at) AND id > sqlc.arg(after_id)))
+ORDER BY created_at, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetTotalProductsByCategory :one
+WITH RECURSIVE category_tree AS (
+    SELECT categories.id FROM categories WHERE categories.id = sqlc.arg(category_id)
+    UNION ALL
+    SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+    WHERE sqlc.arg(include_descendants)::boolean
+)
+SELECT COUNT(*) FROM products
+WHERE category_id IN (SELECT id FROM category_tree) AND is_active = true;
 
 
--- name: UpdateStock :exec
+-- name: UpdateStock :execrows
 UPDATE products
 SET stock_quantity = stock_quantity - $2
 WHERE id = $1 AND stock_quantity >= $2;
+
+-- name: GetProductAnyStatus :one
+SELECT * FROM products
+WHERE id = $1;
+
+-- name: ListAllProducts :many
+SELECT * FROM products
+ORDER BY created_at DESC, id;
+
+-- name: CreateProduct :one
+INSERT INTO products (id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at, reorder_threshold)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING *;
+
+-- name: UpdateProduct :one
+UPDATE products
+SET name = $2, description = $3, price = $4, brand = $5, sku = $6, stock_quantity = $7, category_id = $8,
+    image_url = $9, thumbnail_url = $10, specifications = $11, updated_at = $12, reorder_threshold = $13
+WHERE id = $1
+RETURNING *;
+
+-- name: SetProductActive :one
+UPDATE products
+SET is_active = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SearchProducts :many
+SELECT p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold,
+    ranked.rank,
+    ts_headline('english', p.name, to_tsquery('english', sqlc.arg(query)), 'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text AS name_highlight,
+    ts_headline('english', coalesce(p.description, ''), to_tsquery('english', sqlc.arg(query)), 'MaxFragments=2, MinWords=5, MaxWords=20, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text AS snippet
+FROM (
+    SELECT products.id, ts_rank_cd(product_search_document(products.name, products.brand, products.description, products.specifications), to_tsquery('english', sqlc.arg(query)), 32)::real AS rank
+    FROM products
+    WHERE products.is_active = true
+        AND product_search_document(products.name, products.brand, products.description, products.specifications) @@ to_tsquery('english', sqlc.arg(query))
+) ranked
+JOIN products p ON p.id = ranked.id
+WHERE ranked.rank < sqlc.arg(after_rank)::real OR (ranked.rank = sqlc.arg(after_rank)::real AND p.id > sqlc.arg(after_id))
+ORDER BY ranked.rank DESC, p.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: SetWordSimilarityThreshold :exec
+SELECT set_config('pg_trgm.word_similarity_threshold', sqlc.arg(threshold)::real::text, true);
+
+-- name: SearchProductsFuzzy :many
+SELECT p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold,
+    ranked.rank,
+    p.name::text AS name_highlight,
+    left(coalesce(p.description, ''), 200)::text AS snippet
+FROM (
+    SELECT products.id, greatest(word_similarity(sqlc.arg(query), products.name), word_similarity(sqlc.arg(query), coalesce(products.brand, '')))::real AS rank
+    FROM products
+    WHERE (sqlc.arg(query) <% products.name OR sqlc.arg(query) <% products.brand)
+        AND products.is_active = true
+) ranked
+JOIN products p ON p.id = ranked.id
+WHERE ranked.rank < sqlc.arg(after_rank)::real OR (ranked.rank = sqlc.arg(after_rank)::real AND p.id > sqlc.arg(after_id))
+ORDER BY ranked.rank DESC, p.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpsertProductBySku :one
+INSERT INTO products (id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, is_active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (sku) DO UPDATE
+SET name = EXCLUDED.name, description = EXCLUDED.description, price = EXCLUDED.price, brand = EXCLUDED.brand,
+    stock_quantity = EXCLUDED.stock_quantity, category_id = EXCLUDED.category_id, image_url = EXCLUDED.image_url,
+    thumbnail_url = EXCLUDED.thumbnail_url, specifications = EXCLUDED.specifications, is_active = EXCLUDED.is_active,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: ExportProducts :many
+SELECT p.id, p.name, p.description, p.price, p.brand, p.sku, p.stock_quantity, p.category_id, p.image_url, p.thumbnail_url, p.specifications, p.is_active, p.created_at, p.updated_at, p.reorder_threshold,
+    c.slug AS category_slug, c.name AS category_name
+FROM products p
+JOIN categories c ON c.id = p.category_id
+WHERE p.created_at > sqlc.arg(after_created_at) OR (p.created_at = sqlc.arg(after_created_at) AND p.id > sqlc.arg(after_id))
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,47 @@
+-- name: GetProductStockLevel :one
+SELECT p.sku, p.name, p.stock_quantity, p.reorder_threshold,
+    (p.is_active AND NOT EXISTS (
+        SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active = true
+    ))::boolean AS tracked
+FROM products p
+WHERE p.id = $1;
+
+-- name: GetVariantStockLevel :one
+SELECT v.sku, p.name, v.stock_quantity, p.reorder_threshold, (v.is_active AND p.is_active)::boolean AS tracked
+FROM product_variants v
+JOIN products p ON p.id = v.product_id
+WHERE v.id = $1;
+
+-- name: GetStockAlertCandidates :many
+SELECT p.id AS product_id, NULL::uuid AS variant_id
+FROM products p
+WHERE p.is_active = true AND p.stock_quantity <= p.reorder_threshold
+    AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active = true)
+UNION
+SELECT v.product_id, v.id AS variant_id
+FROM product_variants v
+JOIN products p ON p.id = v.product_id
+WHERE v.is_active = true AND p.is_active = true AND v.stock_quantity <= p.reorder_threshold
+UNION
+SELECT a.product_id, a.variant_id
+FROM stock_alerts a
+WHERE a.resolved_at IS NULL;
+
+-- name: GetOpenStockAlert :one
+SELECT * FROM stock_alerts
+WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2 AND resolved_at IS NULL;
+
+-- name: CreateStockAlert :execrows
+INSERT INTO stock_alerts (id, product_id, variant_id, level, stock_quantity, reorder_threshold, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING;
+
+-- name: SetStockAlertNotified :exec
+UPDATE stock_alerts
+SET notified_at = $2
+WHERE id = $1;
+
+-- name: ResolveStockAlert :exec
+UPDATE stock_alerts
+SET resolved_at = $2
+WHERE id = $1 AND resolved_at IS NULL;
@@ -0,0 +1,33 @@
+-- name: CreateStockMovement :exec
+INSERT INTO stock_movements (id, product_id, variant_id, kind, quantity, order_id, reason, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetStockMovements :many
+SELECT * FROM stock_movements
+WHERE product_id = sqlc.arg(product_id)
+    AND (sqlc.narg(variant_id)::uuid IS NULL OR variant_id = sqlc.narg(variant_id))
+    AND (NOT sqlc.arg(has_cursor)::boolean OR created_at < sqlc.arg(before_created_at) OR (created_at = sqlc.arg(before_created_at) AND id < sqlc.arg(before_id)))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetTotalStockMovements :one
+SELECT count(*) FROM stock_movements
+WHERE product_id = sqlc.arg(product_id)
+    AND (sqlc.narg(variant_id)::uuid IS NULL OR variant_id = sqlc.narg(variant_id));
+
+-- name: GetProductStockBySku :one
+SELECT stock_quantity FROM products
+WHERE sku = $1
+FOR UPDATE;
+
+-- name: AdjustProductStock :one
+UPDATE products
+SET stock_quantity = stock_quantity + sqlc.arg(quantity), updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND stock_quantity + sqlc.arg(quantity) >= 0
+RETURNING stock_quantity;
+
+-- name: AdjustVariantStock :one
+UPDATE product_variants
+SET stock_quantity = stock_quantity + sqlc.arg(quantity), updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND product_id = sqlc.arg(product_id) AND stock_quantity + sqlc.arg(quantity) >= 0
+RETURNING stock_quantity;
@@ -0,0 +1,20 @@
+-- name: CreateStockSubscription :execrows
+INSERT INTO stock_subscriptions (id, user_id, product_id, variant_id, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING;
+
+-- name: ClaimStockSubscriptions :many
+WITH claimed AS (
+    UPDATE stock_subscriptions
+    SET notified_at = $3
+    WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2 AND notified_at IS NULL
+    RETURNING id, user_id
+)
+SELECT claimed.id, users.email, users.name
+FROM claimed
+JOIN users ON users.id = claimed.user_id;
+
+-- name: ReopenStockSubscription :exec
+UPDATE stock_subscriptions
+SET notified_at = NULL
+WHERE id = $1;
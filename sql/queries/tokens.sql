@@ -0,0 +1,49 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1 AND expires_at > $2
+FOR UPDATE;
+
+-- name: RotateRefreshToken :exec
+UPDATE refresh_tokens
+    SET revoked_at = $2, replaced_by = $3
+    WHERE id = $1;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+    SET revoked_at = $2
+    WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+    SET revoked_at = $2
+    WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: DeleteExpiredRevokedTokens :exec
+DELETE FROM revoked_tokens
+WHERE expires_at < $1;
+
+-- name: RevokeUserSessions :exec
+INSERT INTO user_session_revocations (user_id, revoked_before, generation)
+VALUES ($1, $2, 1)
+ON CONFLICT (user_id)
+DO UPDATE SET revoked_before = EXCLUDED.revoked_before, generation = user_session_revocations.generation + 1;
+
+-- name: GetSessionGeneration :one
+SELECT coalesce(
+    (SELECT generation FROM user_session_revocations WHERE user_id = $1), 0
+)::integer AS generation;
+
+-- name: IsTokenRevoked :one
+SELECT (
+    EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+    OR EXISTS (SELECT 1 FROM user_session_revocations WHERE user_id = $2 AND generation > $3)
+) AS revoked;
@@ -1,12 +1,31 @@
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1;
 
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1;
+
 -- name: CreateUser :one
 INSERT INTO users (id, email,name, hashed_password, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetUserDetails :one
-SELECT id, email, name 
+SELECT id, email, name, email_verified_at, role
 FROM users
 WHERE id = $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+    SET hashed_password = $2, updated_at = $3
+    WHERE id = $1;
+
+-- name: SetUserEmailVerified :exec
+UPDATE users
+    SET email_verified_at = $2, updated_at = $3
+    WHERE id = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+    SET role = $2, updated_at = $3
+    WHERE id = $1
+    RETURNING *;
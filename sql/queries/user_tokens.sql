@@ -0,0 +1,18 @@
+-- name: CreateUserToken :exec
+INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetUserTokenByHash :one
+SELECT * FROM user_tokens
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+FOR UPDATE;
+
+-- name: MarkUserTokenUsed :exec
+UPDATE user_tokens
+    SET used_at = $2
+    WHERE id = $1;
+
+-- name: InvalidateUserTokens :exec
+UPDATE user_tokens
+    SET used_at = $3
+    WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
@@ -0,0 +1,65 @@
+-- name: CreateWishlist :exec
+INSERT INTO wishlists (id, user_id, name, saved_for_later, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $5);
+
+-- name: GetUserWishlists :many
+SELECT w.id, w.name, w.saved_for_later, w.share_token, w.created_at, w.updated_at,
+    (SELECT count(*) FROM wishlist_items wi WHERE wi.wishlist_id = w.id)::integer AS item_count
+FROM wishlists w
+WHERE w.user_id = $1
+ORDER BY w.saved_for_later DESC, w.created_at;
+
+-- name: GetWishlist :one
+SELECT id, name, saved_for_later, share_token, created_at, updated_at
+FROM wishlists
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetSharedWishlist :one
+SELECT id, name, created_at, updated_at
+FROM wishlists
+WHERE share_token = $1;
+
+-- name: GetSavedForLaterWishlist :one
+SELECT id
+FROM wishlists
+WHERE user_id = $1 AND saved_for_later;
+
+-- name: UpdateWishlist :execrows
+UPDATE wishlists
+SET name = $3, share_token = $4, updated_at = $5
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteWishlist :execrows
+DELETE FROM wishlists
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetWishlistItems :many
+SELECT wi.product_id, wi.variant_id, wi.quantity, wi.created_at, p.name,
+    coalesce(v.price, p.price)::text AS price, v.sku AS variant_sku, v.attributes AS variant_attributes,
+    coalesce(v.stock_quantity, p.stock_quantity)::integer AS stock_quantity,
+    (p.is_active AND CASE
+        WHEN wi.variant_id IS NULL THEN NOT EXISTS (
+            SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_active = true
+        )
+        ELSE v.is_active
+    END)::boolean AS available
+FROM wishlist_items wi
+JOIN products p ON p.id = wi.product_id
+LEFT JOIN product_variants v ON v.id = wi.variant_id
+WHERE wi.wishlist_id = $1
+ORDER BY wi.created_at DESC;
+
+-- name: GetWishlistItem :one
+SELECT quantity
+FROM wishlist_items
+WHERE wishlist_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3;
+
+-- name: AddWishlistItem :exec
+INSERT INTO wishlist_items (id, wishlist_id, product_id, variant_id, quantity, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (wishlist_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
+DO UPDATE SET quantity = wishlist_items.quantity + EXCLUDED.quantity;
+
+-- name: RemoveWishlistItem :execrows
+DELETE FROM wishlist_items
+WHERE wishlist_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE revoked_tokens(
+    jti VARCHAR(100) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE user_session_revocations(
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    revoked_before TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE user_session_revocations;
+DROP TABLE revoked_tokens;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users
+ADD email_verified_at TIMESTAMP;
+
+-- Accounts created before verification existed are treated as verified
+UPDATE users
+SET email_verified_at = created_at;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN email_verified_at;
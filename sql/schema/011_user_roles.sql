@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE users
+ADD role VARCHAR(20) NOT NULL
+DEFAULT 'customer'
+CHECK (role IN ('customer', 'staff', 'admin'));
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN role;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Weighted search document of a product, name matches rank above brand, description and specification matches
+-- +goose StatementBegin
+CREATE FUNCTION product_search_document(name TEXT, brand TEXT, description TEXT, specifications JSONB)
+RETURNS tsvector
+LANGUAGE SQL
+IMMUTABLE
+AS $$
+    SELECT setweight(to_tsvector('english'::regconfig, coalesce(name, '')), 'A')
+        || setweight(to_tsvector('english'::regconfig, coalesce(brand, '')), 'B')
+        || setweight(to_tsvector('english'::regconfig, coalesce(description, '')), 'C')
+        || setweight(jsonb_to_tsvector('english'::regconfig, coalesce(specifications, '{}'::jsonb), '["string"]'), 'D')
+$$;
+-- +goose StatementEnd
+
+CREATE INDEX products_search_idx ON products
+USING GIN (product_search_document(name, brand, description, specifications));
+
+-- +goose Down
+DROP INDEX products_search_idx;
+DROP FUNCTION product_search_document(TEXT, TEXT, TEXT, JSONB);
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE INDEX products_specifications_idx ON products USING GIN (specifications jsonb_path_ops);
+CREATE INDEX products_price_idx ON products (price);
+CREATE INDEX products_created_at_idx ON products (created_at, id);
+
+-- +goose Down
+DROP INDEX products_created_at_idx;
+DROP INDEX products_price_idx;
+DROP INDEX products_specifications_idx;
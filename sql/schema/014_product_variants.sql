@@ -0,0 +1,78 @@
+-- +goose Up
+CREATE TABLE product_variants (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(100) NOT NULL UNIQUE,
+    price DECIMAL(10, 2) NOT NULL,
+    stock_quantity INT NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+    attributes JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX product_variants_product_id_idx ON product_variants (product_id);
+
+-- Move the JSON variants into the table, splitting the product's stock evenly between them. The first variant
+-- takes the remainder so no stock is lost. SKUs listed more than once get the number of the repeat appended
+-- rather than being dropped with their stock, and prices that are not decimal numbers fall back to the
+-- product's price rather than aborting the migration. Should a numbered SKU still clash with another, the
+-- migration fails on the unique constraint before any variant data is dropped.
+INSERT INTO product_variants (id, product_id, sku, price, stock_quantity, attributes)
+SELECT gen_random_uuid(), s.product_id,
+    CASE WHEN s.sku_n = 1 THEN s.sku ELSE left(s.sku, 90) || '-' || s.sku_n END,
+    CASE WHEN s.v->>'price' ~ '^\s*[0-9]{1,8}(\.[0-9]{1,2})?\s*$' THEN (s.v->>'price')::numeric ELSE s.price END,
+    s.stock_quantity / s.n + CASE WHEN s.ord = 1 THEN s.stock_quantity % s.n ELSE 0 END,
+    s.v - 'sku' - 'price'
+FROM (
+    SELECT p.id AS product_id, p.price, p.stock_quantity, e.v, e.v->>'sku' AS sku,
+        row_number() OVER (PARTITION BY p.id ORDER BY e.i) AS ord,
+        count(*) OVER (PARTITION BY p.id) AS n,
+        row_number() OVER (PARTITION BY e.v->>'sku' ORDER BY p.created_at, p.id, e.i) AS sku_n
+    FROM products p
+    CROSS JOIN LATERAL jsonb_array_elements(p.variants) WITH ORDINALITY e(v, i)
+    WHERE jsonb_typeof(p.variants) = 'array' AND coalesce(e.v->>'sku', '') <> ''
+) s;
+
+ALTER TABLE products
+DROP COLUMN variants;
+
+-- Items without a variant compare equal on the nil UUID, so each product or variant appears once per cart and order
+ALTER TABLE cart_items
+ADD variant_id UUID REFERENCES product_variants(id),
+DROP CONSTRAINT cart_items_cart_id_product_id_key;
+
+CREATE UNIQUE INDEX cart_items_cart_product_variant_key
+ON cart_items (cart_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+ALTER TABLE order_items
+ADD variant_id UUID REFERENCES product_variants(id),
+DROP CONSTRAINT order_items_order_id_product_id_key;
+
+CREATE UNIQUE INDEX order_items_order_product_variant_key
+ON order_items (order_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+-- +goose Down
+DROP INDEX order_items_order_product_variant_key;
+DELETE FROM order_items WHERE variant_id IS NOT NULL;
+ALTER TABLE order_items
+DROP COLUMN variant_id,
+ADD CONSTRAINT order_items_order_id_product_id_key UNIQUE (order_id, product_id);
+
+DROP INDEX cart_items_cart_product_variant_key;
+DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+ALTER TABLE cart_items
+DROP COLUMN variant_id,
+ADD CONSTRAINT cart_items_cart_id_product_id_key UNIQUE (cart_id, product_id);
+
+ALTER TABLE products
+ADD variants JSONB;
+
+UPDATE products p
+SET variants = (
+    SELECT jsonb_agg(v.attributes || jsonb_build_object('sku', v.sku, 'price', v.price) ORDER BY v.created_at)
+    FROM product_variants v
+    WHERE v.product_id = p.id
+);
+
+DROP TABLE product_variants;
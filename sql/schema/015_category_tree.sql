@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE categories
+ADD parent_id UUID REFERENCES categories(id),
+ADD slug VARCHAR(100),
+ADD sort_order INT NOT NULL DEFAULT 0,
+ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+
+-- Existing categories are roots, slugs are derived from their names. Names without letters or digits fall back
+-- to part of the id, and names giving the same slug, such as "T-Shirts" and "T Shirts", have part of the id
+-- appended to all but the first.
+UPDATE categories
+SET slug = trim(BOTH '-' FROM left(regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g'), 90));
+
+UPDATE categories
+SET slug = left(id::text, 8)
+WHERE slug = '';
+
+UPDATE categories c
+SET slug = c.slug || '-' || left(c.id::text, 8)
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY slug ORDER BY name, id) AS n
+    FROM categories
+) d
+WHERE d.id = c.id AND d.n > 1;
+
+ALTER TABLE categories
+ALTER COLUMN slug SET NOT NULL,
+ADD CONSTRAINT categories_slug_key UNIQUE (slug);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id, sort_order, name);
+
+-- +goose Down
+DROP INDEX categories_parent_id_idx;
+
+ALTER TABLE categories
+DROP COLUMN sort_order,
+DROP COLUMN slug,
+DROP COLUMN parent_id;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE product_images (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    alt_text VARCHAR(255),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('jpeg', 'png')),
+    width INT NOT NULL,
+    height INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX product_images_product_id_idx ON product_images (product_id, position);
+
+-- +goose Down
+DROP TABLE product_images;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE inventory_reservations (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    variant_id UUID REFERENCES product_variants(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'released', 'converted')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only active reservations hold stock, so only they need to be found quickly
+CREATE INDEX inventory_reservations_active_item_idx ON inventory_reservations (product_id, variant_id)
+    WHERE status = 'active';
+CREATE INDEX inventory_reservations_active_expiry_idx ON inventory_reservations (expires_at)
+    WHERE status = 'active';
+CREATE INDEX inventory_reservations_order_id_idx ON inventory_reservations (order_id);
+
+-- +goose Down
+DROP TABLE inventory_reservations;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- Append-only ledger of stock changes. Quantities are signed: summing the movements of an item other than
+-- reservations gives its stock quantity, including reservations gives the stock still available to buy.
+CREATE TABLE stock_movements (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id),
+    variant_id UUID REFERENCES product_variants(id),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('sale', 'restock', 'return', 'adjustment', 'reservation')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    reason VARCHAR(255),
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX stock_movements_product_idx ON stock_movements (product_id, created_at DESC, id DESC);
+
+-- +goose StatementBegin
+CREATE FUNCTION stock_movements_append_only()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER stock_movements_append_only
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Open the ledger with the stock and reservations held before it existed
+INSERT INTO stock_movements (id, product_id, kind, quantity, reason, created_at)
+SELECT gen_random_uuid(), id, 'adjustment', stock_quantity, 'opening balance', CURRENT_TIMESTAMP
+FROM products
+WHERE stock_quantity <> 0;
+
+INSERT INTO stock_movements (id, product_id, variant_id, kind, quantity, reason, created_at)
+SELECT gen_random_uuid(), product_id, id, 'adjustment', stock_quantity, 'opening balance', CURRENT_TIMESTAMP
+FROM product_variants
+WHERE stock_quantity <> 0;
+
+INSERT INTO stock_movements (id, product_id, variant_id, kind, quantity, order_id, reason, created_at)
+SELECT gen_random_uuid(), product_id, variant_id, 'reservation', -quantity, order_id, 'opening balance', CURRENT_TIMESTAMP
+FROM inventory_reservations
+WHERE status = 'active';
+
+-- +goose Down
+DROP TABLE stock_movements;
+DROP FUNCTION stock_movements_append_only();
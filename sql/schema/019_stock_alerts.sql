@@ -0,0 +1,23 @@
+-- +goose Up
+-- Stock at or below the reorder threshold is low, variants use the threshold of their product
+ALTER TABLE products ADD COLUMN reorder_threshold INTEGER NOT NULL DEFAULT 0 CHECK (reorder_threshold >= 0);
+
+-- An alert stays open while its product or variant remains at the alerted level, so it is only sent once
+CREATE TABLE stock_alerts (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    level VARCHAR(10) NOT NULL CHECK (level IN ('low', 'out')),
+    stock_quantity INTEGER NOT NULL,
+    reorder_threshold INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX stock_alerts_open_idx ON stock_alerts (product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'))
+WHERE resolved_at IS NULL;
+
+-- +goose Down
+DROP TABLE stock_alerts;
+ALTER TABLE products DROP COLUMN reorder_threshold;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Shoppers waiting for a product or variant to be restocked. A subscription is fulfilled once notified, and
+-- a shopper can only wait once for the same item at a time.
+CREATE TABLE stock_subscriptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX stock_subscriptions_open_idx ON stock_subscriptions (user_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'))
+WHERE notified_at IS NULL;
+
+CREATE INDEX stock_subscriptions_item_idx ON stock_subscriptions (product_id, variant_id)
+WHERE notified_at IS NULL;
+
+-- +goose Down
+DROP TABLE stock_subscriptions;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Carts without a currency are priced in the store's base currency
+ALTER TABLE carts ADD COLUMN currency CHAR(3);
+
+-- Orders placed before currencies were supported were all charged in US dollars
+ALTER TABLE orders
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN exchange_rate NUMERIC(20, 10) NOT NULL DEFAULT 1;
+ALTER TABLE orders
+    ALTER COLUMN currency DROP DEFAULT,
+    ALTER COLUMN exchange_rate DROP DEFAULT;
+
+
+-- +goose Down
+ALTER TABLE orders
+    DROP COLUMN exchange_rate,
+    DROP COLUMN currency;
+ALTER TABLE carts DROP COLUMN currency;
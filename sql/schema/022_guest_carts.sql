@@ -0,0 +1,8 @@
+-- +goose Up
+-- Guest carts belong to no user until they are merged into the user's cart on login or registration
+ALTER TABLE carts ALTER COLUMN user_id DROP NOT NULL;
+
+
+-- +goose Down
+DELETE FROM carts WHERE user_id IS NULL;
+ALTER TABLE carts ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- The price an item had when it was added to the cart, so shoppers can be told when it has changed since
+ALTER TABLE cart_items ADD COLUMN price_at_add DECIMAL(10, 2);
+
+UPDATE cart_items ci
+SET price_at_add = (
+    SELECT coalesce(v.price, p.price)
+    FROM products p
+    LEFT JOIN product_variants v ON v.id = ci.variant_id
+    WHERE p.id = ci.product_id
+);
+
+ALTER TABLE cart_items ALTER COLUMN price_at_add SET NOT NULL;
+
+
+-- +goose Down
+ALTER TABLE cart_items DROP COLUMN price_at_add;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- A cart's updated_at is its last activity, so changing its items counts too
+-- +goose StatementBegin
+CREATE FUNCTION touch_cart()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    UPDATE carts SET updated_at = CURRENT_TIMESTAMP
+    WHERE id = CASE WHEN TG_OP = 'DELETE' THEN OLD.cart_id ELSE NEW.cart_id END;
+    RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER cart_items_touch_cart
+AFTER INSERT OR UPDATE OR DELETE ON cart_items
+FOR EACH ROW EXECUTE FUNCTION touch_cart();
+
+CREATE INDEX carts_status_updated_at_idx ON carts (status, updated_at);
+
+-- One row per time a user's cart was abandoned. Rows outlive the cart, which is deleted once it is checked out or
+-- purged, so they also record whether the cart was restored from the recovery email and converted into an order.
+CREATE TABLE cart_recoveries (
+    id UUID PRIMARY KEY,
+    cart_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    abandoned_at TIMESTAMP NOT NULL,
+    email_sent_at TIMESTAMP,
+    restored_at TIMESTAMP,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    converted_at TIMESTAMP
+);
+
+CREATE INDEX cart_recoveries_cart_idx ON cart_recoveries (cart_id);
+
+
+-- +goose Down
+DROP TABLE cart_recoveries;
+DROP INDEX carts_status_updated_at_idx;
+DROP TRIGGER cart_items_touch_cart ON cart_items;
+DROP FUNCTION touch_cart();
+UPDATE carts SET status = 'active' WHERE status = 'abandoned';
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Named lists of products a user wants to keep. Each user also gets one "saved for later" list, created the first
+-- time an item is saved from their cart. Lists with a share token can be viewed by anyone with the link.
+CREATE TABLE wishlists (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    saved_for_later BOOLEAN NOT NULL DEFAULT false,
+    share_token VARCHAR(64) UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+CREATE UNIQUE INDEX wishlists_saved_for_later_key ON wishlists (user_id) WHERE saved_for_later;
+
+CREATE TABLE wishlist_items (
+    id UUID PRIMARY KEY,
+    wishlist_id UUID NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    variant_id UUID REFERENCES product_variants(id),
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX wishlist_items_product_variant_key
+ON wishlist_items (wishlist_id, product_id, coalesce(variant_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+
+-- +goose Down
+DROP TABLE wishlist_items;
+DROP TABLE wishlists;
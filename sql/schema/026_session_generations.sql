@@ -0,0 +1,9 @@
+-- +goose Up
+-- Access tokens carry the user's session generation, which logging out of all devices increments. Unlike issue
+-- times, which tokens only carry in whole seconds, generations give an exact cutoff. Tokens issued before this
+-- change have no generation and count as 0, so users who had already logged out of all devices start at 1.
+ALTER TABLE user_session_revocations ADD COLUMN generation INT NOT NULL DEFAULT 1;
+ALTER TABLE user_session_revocations ALTER COLUMN generation DROP DEFAULT;
+
+-- +goose Down
+ALTER TABLE user_session_revocations DROP COLUMN generation;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Trigram indexes for the fuzzy search, which filters names and brands with the word similarity operator <%
+CREATE INDEX products_name_trgm_idx ON products
+USING GIN (name gin_trgm_ops);
+
+CREATE INDEX products_brand_trgm_idx ON products
+USING GIN (brand gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX products_brand_trgm_idx;
+DROP INDEX products_name_trgm_idx;
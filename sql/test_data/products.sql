@@ -1,13 +1,14 @@
-INSERT INTO categories (id, name, description)
+INSERT INTO categories (id, name, slug, description, parent_id, sort_order)
 VALUES
-('9b682f9c-c1ac-4242-98ea-f05d5b2d680c','Laptops', 'Various models of laptops and notebooks'),
-('8bc5a2ca-b3ed-4fad-91b5-aaf302e070bf','Smartphones', 'Latest smartphones from top brands'),
-('87785cc5-4c62-441f-8fb5-add44b598c8c','Tech Accessories', 'Accessories for your tech devices'),
-('2411d196-8e64-4161-894c-2ee25fab063f','Light Gadgets', 'Innovative lighting gadgets for home and office'),
-('d1d7b533-39b5-466c-8ac4-3a3bacb4e763','Wearables', 'Smartwatches, fitness trackers, and more');
+('e7c27fd3-8b38-53e0-a818-98da0b5d0ec2','Electronics', 'electronics', 'Computers, phones and everything in between', NULL, 0),
+('9b682f9c-c1ac-4242-98ea-f05d5b2d680c','Laptops', 'laptops', 'Various models of laptops and notebooks', 'e7c27fd3-8b38-53e0-a818-98da0b5d0ec2', 0),
+('8bc5a2ca-b3ed-4fad-91b5-aaf302e070bf','Smartphones', 'smartphones', 'Latest smartphones from top brands', 'e7c27fd3-8b38-53e0-a818-98da0b5d0ec2', 1),
+('87785cc5-4c62-441f-8fb5-add44b598c8c','Tech Accessories', 'tech-accessories', 'Accessories for your tech devices', 'e7c27fd3-8b38-53e0-a818-98da0b5d0ec2', 2),
+('2411d196-8e64-4161-894c-2ee25fab063f','Light Gadgets', 'light-gadgets', 'Innovative lighting gadgets for home and office', NULL, 1),
+('d1d7b533-39b5-466c-8ac4-3a3bacb4e763','Wearables', 'wearables', 'Smartwatches, fitness trackers, and more', NULL, 2);
 
 
-INSERT INTO products (id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications, variants)
+INSERT INTO products (id, name, description, price, brand, sku, stock_quantity, category_id, image_url, thumbnail_url, specifications)
 VALUES
 -- Tech Accessories
 ('3e2762f7-344d-4e6c-acb8-8462c67438f8',
@@ -20,11 +21,7 @@ VALUES
  (SELECT id FROM categories WHERE name = 'Tech Accessories'), 
  'https://i.pinimg.com/originals/f5/ff/7c/f5ff7c038681a83f68144042e995aac2.jpg', 
  'https://i.pinimg.com/originals/f5/ff/7c/f5ff7c038681a83f68144042e995aac2.jpg', 
- '{"connectivity": "2.4GHz wireless", "battery_life": "12 months", "dpi": "800-1600 DPI", "color": "Black"}', 
- '[
-    {"color": "Black", "price": 29.99, "sku": "MOUSE-WL-LOGI-BLK"},
-    {"color": "White", "price": 29.99, "sku": "MOUSE-WL-LOGI-WHT"}
-  ]'),
+ '{"connectivity": "2.4GHz wireless", "battery_life": "12 months", "dpi": "800-1600 DPI", "color": "Black"}'),
 
 ('749059f1-61df-4d24-934f-6179035b2149',
     'USB-C Hub', 
@@ -36,11 +33,7 @@ VALUES
  (SELECT id FROM categories WHERE name = 'Tech Accessories'), 
  'https://i.pinimg.com/736x/5c/a7/c6/5ca7c606107ca60828a7c10db3d10581.jpg', 
  'https://i.pinimg.com/736x/5c/a7/c6/5ca7c606107ca60828a7c10db3d10581.jpg', 
- '{"ports": "1 HDMI, 3 USB 3.0, 1 SD card slot, 1 microSD card slot, 1 USB-C power delivery", "material": "Aluminum", "weight": "50g"}', 
- '[
-    {"color": "Space Gray", "price": 49.99, "sku": "USBHUB-7IN1-ANK-GRY"},
-    {"color": "Silver", "price": 49.99, "sku": "USBHUB-7IN1-ANK-SLV"}
-  ]'),
+ '{"ports": "1 HDMI, 3 USB 3.0, 1 SD card slot, 1 microSD card slot, 1 USB-C power delivery", "material": "Aluminum", "weight": "50g"}'),
 
 -- Light Gadgets
 ('5b7922cd-0b27-4541-90ee-7c6230b4d90c',
@@ -53,11 +46,7 @@ VALUES
  (SELECT id FROM categories WHERE name = 'Light Gadgets'), 
  'https://i.pinimg.com/originals/1f/de/fb/1fdefbc717f4a5cc69c21d4d3d5dedb7.jpg', 
  'https://i.pinimg.com/originals/1f/de/fb/1fdefbc717f4a5cc69c21d4d3d5dedb7.jpg', 
- '{"brightness": "800 lumens", "color_temperature": "2700K-6500K", "lifespan": "25000 hours", "connectivity": "Wi-Fi, Bluetooth"}', 
- '[
-    {"color": "White", "price": 19.99, "sku": "LED-BULB-SMART-HUE-WHT"},
-    {"color": "Color", "price": 24.99, "sku": "LED-BULB-SMART-HUE-CLR"}
-  ]'),
+ '{"brightness": "800 lumens", "color_temperature": "2700K-6500K", "lifespan": "25000 hours", "connectivity": "Wi-Fi, Bluetooth"}'),
 
 ('b7e162b8-38a9-4bb6-b61b-68b175f1e9f5',
     'Portable LED Desk Lamp', 
@@ -69,11 +58,7 @@ VALUES
  (SELECT id FROM categories WHERE name = 'Light Gadgets'), 
  'https://i.pinimg.com/originals/f7/3a/db/f73adbe044d6cd474451a0803febb3d8.jpg', 
  'https://i.pinimg.com/originals/f7/3a/db/f73adbe044d6cd474451a0803febb3d8.jpg', 
- '{"brightness_levels": "5", "color_modes": "3", "battery_life": "10 hours", "weight": "600g"}', 
- '[
-    {"color": "Black", "price": 39.99, "sku": "DESK-LAMP-LED-TT-BLK"},
-    {"color": "White", "price": 39.99, "sku": "DESK-LAMP-LED-TT-WHT"}
-  ]'),
+ '{"brightness_levels": "5", "color_modes": "3", "battery_life": "10 hours", "weight": "600g"}'),
 
 -- Wearables
 ('83b35792-bd21-4d29-bb42-0ecdf9125fb1',
@@ -86,11 +71,7 @@ VALUES
  (SELECT id FROM categories WHERE name = 'Wearables'), 
  'https://i.pinimg.com/originals/d0/e2/a9/d0e2a9aad3d0f3f3ccc5fbbb9f2ce4fc.jpg', 
  'https://i.pinimg.com/originals/d0/e2/a9/d0e2a9aad3d0f3f3ccc5fbbb9f2ce4fc.jpg', 
- '{"display": "1.5 inch AMOLED", "battery_life": "7 days", "water_resistance": "5 ATM", "connectivity": "Bluetooth, GPS"}', 
- '[
-    {"color": "Black", "band": "Silicone", "price": 149.99, "sku": "WATCH-SMART-X100-BLK-SIL"},
-    {"color": "Silver", "band": "Metal", "price": 169.99, "sku": "WATCH-SMART-X100-SLV-MTL"}
-  ]'),
+ '{"display": "1.5 inch AMOLED", "battery_life": "7 days", "water_resistance": "5 ATM", "connectivity": "Bluetooth, GPS"}'),
 
 ('99734a47-a88e-48e3-9e89-c55bd515c9e2',
     'Fitness Tracker Z200', 
@@ -102,9 +83,20 @@ VALUES
  (SELECT id FROM categories WHERE name = 'Wearables'), 
  'https://i.pinimg.com/originals/42/fc/98/42fc98825cc3372bfb94b43a69059d9b.jpg', 
  'https://i.pinimg.com/originals/42/fc/98/42fc98825cc3372bfb94b43a69059d9b.jpg', 
- '{"display": "OLED", "battery_life": "10 days", "water_resistance": "IP68", "connectivity": "Bluetooth"}', 
- '[
-    {"color": "Black", "band": "Silicone", "price": 69.99, "sku": "FITNESS-TRACK-Z200-BLK"},
-    {"color": "Blue", "band": "Silicone", "price": 69.99, "sku": "FITNESS-TRACK-Z200-BLU"}
-  ]');
+ '{"display": "OLED", "battery_life": "10 days", "water_resistance": "IP68", "connectivity": "Bluetooth"}');
 
+
+INSERT INTO product_variants (id, product_id, sku, price, stock_quantity, attributes)
+VALUES
+('981db7c5-1d9f-5ee6-af23-58a723f5883c', '3e2762f7-344d-4e6c-acb8-8462c67438f8', 'MOUSE-WL-LOGI-BLK', 29.99, 75, '{"color": "Black"}'),
+('49a34451-90e2-55f8-bb50-8b3fed3b989f', '3e2762f7-344d-4e6c-acb8-8462c67438f8', 'MOUSE-WL-LOGI-WHT', 29.99, 75, '{"color": "White"}'),
+('3c73c3b9-764c-5a73-a1bd-aaae172adf27', '749059f1-61df-4d24-934f-6179035b2149', 'USBHUB-7IN1-ANK-GRY', 49.99, 50, '{"color": "Space Gray"}'),
+('2f750c20-8b88-5db2-aac1-be458bba94e7', '749059f1-61df-4d24-934f-6179035b2149', 'USBHUB-7IN1-ANK-SLV', 49.99, 50, '{"color": "Silver"}'),
+('7f8f357f-11f2-5d85-8475-b385d1eb49b5', '5b7922cd-0b27-4541-90ee-7c6230b4d90c', 'LED-BULB-SMART-HUE-WHT', 19.99, 100, '{"color": "White"}'),
+('34e0e0c5-94d2-5f21-b6d4-e115c89bd419', '5b7922cd-0b27-4541-90ee-7c6230b4d90c', 'LED-BULB-SMART-HUE-CLR', 24.99, 100, '{"color": "Color"}'),
+('cc5de75b-9b36-5afb-8611-a755a94bb552', 'b7e162b8-38a9-4bb6-b61b-68b175f1e9f5', 'DESK-LAMP-LED-TT-BLK', 39.99, 37, '{"color": "Black"}'),
+('34e87a99-4539-572d-8ff9-74632758477b', 'b7e162b8-38a9-4bb6-b61b-68b175f1e9f5', 'DESK-LAMP-LED-TT-WHT', 39.99, 37, '{"color": "White"}'),
+('5c6a7036-4a6c-5a85-a77a-d7bfbcea6eb3', '83b35792-bd21-4d29-bb42-0ecdf9125fb1', 'WATCH-SMART-X100-BLK-SIL', 149.99, 25, '{"color": "Black", "band": "Silicone"}'),
+('c55c5e62-2ecc-5985-93fb-e9414e7dbd8f', '83b35792-bd21-4d29-bb42-0ecdf9125fb1', 'WATCH-SMART-X100-SLV-MTL', 169.99, 25, '{"color": "Silver", "band": "Metal"}'),
+('2247ed19-2596-5d76-a8ef-d9886523cebd', '99734a47-a88e-48e3-9e89-c55bd515c9e2', 'FITNESS-TRACK-Z200-BLK', 69.99, 40, '{"color": "Black", "band": "Silicone"}'),
+('bca02eb6-6b8b-53a5-9bca-11e287652fc6', '99734a47-a88e-48e3-9e89-c55bd515c9e2', 'FITNESS-TRACK-Z200-BLU', 69.99, 40, '{"color": "Blue", "band": "Silicone"}');